@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"github.com/nray-scanner/nray/core"
+	"github.com/nray-scanner/nray/scanner"
+	"github.com/nray-scanner/nray/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -13,7 +15,9 @@ var nodeCmd = &cobra.Command{
 	Long: `The nray node connects to a upstream nray server and performs network discovery
 scans on behalf of the server. For itself, it is useless.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		core.RunNode(nodeCmdArgs)
+		// Catch typos before registering, the server's settings are only known afterwards
+		utils.CheckError(scanner.ValidateSourceBinding(nodeCmdArgs.SourceIP, nodeCmdArgs.SourceInterface, nodeCmdArgs.SourcePorts), true)
+		utils.CheckError(core.RunNode(nodeCmdArgs), true)
 	},
 }
 
@@ -40,6 +44,22 @@ func parseCmdLine() {
 		"path to tls client cert. Requires --use-tls")
 	nodeCmd.PersistentFlags().StringVar(&nodeCmdArgs.TLSServerSAN, "tls-server-SAN", "",
 		"subject alternative name of the server. Go's TLS implementation checks this value against the values provided in the certificate and refuses to connect if no match is found")
+	nodeCmd.PersistentFlags().StringSliceVar(&nodeCmdArgs.ScopeAllow, "allow", []string{},
+		"IPs, networks in CIDR notation or domains this node may scan, regardless of what the server says. If set, everything else is refused")
+	nodeCmd.PersistentFlags().StringVar(&nodeCmdArgs.ScopeAllowFile, "allow-file", "",
+		"File containing entries for --allow, one per line")
+	nodeCmd.PersistentFlags().StringSliceVar(&nodeCmdArgs.ScopeDeny, "deny", []string{},
+		"IPs, networks in CIDR notation or domains this node never scans, regardless of what the server says")
+	nodeCmd.PersistentFlags().StringVar(&nodeCmdArgs.ScopeDenyFile, "deny-file", "",
+		"File containing entries for --deny, one per line")
+	nodeCmd.PersistentFlags().BoolVar(&nodeCmdArgs.ScopeStrict, "strict", false,
+		"Abort the whole batch instead of skipping single targets if the server sends targets out of scope")
+	nodeCmd.PersistentFlags().StringVar(&nodeCmdArgs.SourceIP, "source-ip", "",
+		"Local IP address scans are sent from. Overrides scannerconfig.source.ip of the server")
+	nodeCmd.PersistentFlags().StringVar(&nodeCmdArgs.SourceInterface, "source-interface", "",
+		"Network interface scans are sent from. Overrides scannerconfig.source.interface of the server")
+	nodeCmd.PersistentFlags().StringVar(&nodeCmdArgs.SourcePorts, "source-ports", "",
+		"Local port or port range like 40000-40999 scans are sent from. Overrides scannerconfig.source.ports of the server")
 
 }
 
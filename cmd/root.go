@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/nray-scanner/nray/core"
+	"github.com/nray-scanner/nray/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -38,7 +39,7 @@ to speed up scans and improve the accuracy of results.
 			cmd.Help()
 			os.Exit(1)
 		}
-		core.RunNode(nodeCmdArgs)
+		utils.CheckError(core.RunNode(nodeCmdArgs), true)
 	},
 }
 
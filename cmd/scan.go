@@ -1,13 +1,14 @@
 package cmd
 
 import (
+	"bufio"
+	"io"
 	"net"
+	"os"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/apparentlymart/go-cidr/cidr"
-	"github.com/golang/protobuf/ptypes"
 	log "github.com/sirupsen/logrus"
 
 	targetgeneration "github.com/nray-scanner/nray/core/targetGeneration"
@@ -16,232 +17,391 @@ import (
 	nraySchema "github.com/nray-scanner/nray/schemas"
 	"github.com/nray-scanner/nray/utils"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
 var rawPorts string
+var rawUDPPorts string
+var excludedPorts string
+var topPortsFile string
 var rawTargets string
+var inputList string
+var excludedTargets string
+var excludeFile string
 var scanUDP bool
 var targetCount uint64
-var scannedCount uint64
 var timeout time.Duration
 var outputFile string
 var workers uint
+var ratelimit string
+var hostsPerBatch uint
+var scannerOptions []string
+var serviceDetection bool
+var versionIntensity int
+var serviceProbeFile string
 
 var scanCmd = &cobra.Command{
 	Use:   "scan",
 	Short: "Starts a scan with parameters provided on the command line",
 	Long: `If you want to initiate a quick and dirty simple scan without 
 creating a configuration and attaching scanner nodes, the simple scan
-is what you are looking for. Get the work done nmap-style like you are used to.`,
+is what you are looking for. Get the work done nmap-style like you are used to.
+The scan runs the same scanning engine as a node does. Scanner options are
+taken from the scannerconfig section of --config and may be overridden
+with flags or -O key=value, e.g. -O udp.fast=true`,
+	// Flags are parsed by parseScanFlags, so -iL works like in nmap
+	DisableFlagParsing: true,
 	Run: func(cmd *cobra.Command, args []string) {
-		if rawTargets == "" { // scan from stdin
-
+		if err := parseScanFlags(cmd, args); err != nil {
+			cmd.Usage()
+			utils.CheckError(err, true)
 		}
-		persistResults := true
-		if outputFile == "" {
-			persistResults = false
+		if help, _ := cmd.Flags().GetBool("help"); help {
+			cmd.Help()
+			return
 		}
+		controller, err := scanner.CreateScanController("0", "localscanner", 0, scanConfig(cmd))
+		utils.CheckError(err, true)
+		defer controller.Close()
 
-		targetChan := parseTargets()
-		parsedPorts := parsePorts()
-		scanChan := prepareScan(targetChan, parsedPorts)
-		resultChan := make(chan (*scanner.PortscanResult), 100)
-		scanFuncs := prepareScanFuncs(scanChan, resultChan)
-
-		var filechan chan *nraySchema.Event
-		var logfile events.EventHandler
-		if persistResults {
+		handlers := make([]events.EventHandler, 0, 2)
+		stdout := events.GetEventHandler("terminal")
+		utils.CheckError(stdout.Configure(viper.New()), true)
+		handlers = append(handlers, stdout)
+		if outputFile != "" {
 			config := viper.New()
 			config.Set("filename", outputFile)
 			config.Set("overwriteExisting", true)
 			logfile := events.GetEventHandler("json-file")
-			logfile.Configure(config)
-			filechan := make(chan (*nraySchema.Event), 1000)
-			go logfile.ProcessEventStream(filechan)
+			utils.CheckError(logfile.Configure(config), true)
+			handlers = append(handlers, logfile)
 		}
 
-		stdout := events.GetEventHandler("terminal")
-		stdout.Configure(viper.New())
-		stdoutchan := make(chan (*nraySchema.Event), 1000)
-		go stdout.ProcessEventStream(stdoutchan)
-
-		go func(resultChan <-chan *scanner.PortscanResult) {
-			for portscanResult := range resultChan {
-				now, _ := ptypes.TimestampProto(time.Now())
-				data := &nraySchema.Event{
-					NodeID:      "0",
-					NodeName:    "localscanner",
-					Timestamp:   now,
-					Scannername: "local",
-					EventData: &nraySchema.Event_Result{
-						Result: &nraySchema.ScanResult{
-							Target: portscanResult.Target,
-							Port:   portscanResult.Port,
-							Result: &nraySchema.ScanResult_Portscan{
-								Portscan: &nraySchema.PortScanResult{
-									Scantype: portscanResult.Scantype,
-									Target:   portscanResult.Target,
-									Port:     portscanResult.Port,
-									Open:     portscanResult.Open,
-									Timeout:  uint32(portscanResult.Timeout / time.Millisecond),
-								},
-							},
-						},
-					},
-				}
-				if persistResults {
-					filechan <- data
-				}
-				stdoutchan <- data
+		tcpPorts, udpPorts := parsePorts()
+		targetChan := parseTargets(targetInput(), parseExcludes())
+		for workBatch := range batchTargets(targetChan, tcpPorts, udpPorts) {
+			log.WithFields(log.Fields{
+				"module": "cmd.scan",
+				"src":    "scanCmd",
+			}).Debugf("Scanning batch %d with %d hosts", workBatch.Batchid, len(workBatch.Targets.Rhosts))
+			results := controller.ScanBatch(workBatch)
+			for _, handler := range handlers {
+				handler.ProcessEvents(results)
 			}
-		}(resultChan)
-		startScan(scanFuncs, resultChan)
-
-		utils.CheckError(stdout.Close(), false)
-		if persistResults {
-			utils.CheckError(logfile.Close(), false)
 		}
 
+		for _, handler := range handlers {
+			utils.CheckError(handler.Close(), false)
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(scanCmd)
-	scanCmd.PersistentFlags().StringVarP(&rawPorts, "ports", "p", "", "Ports to scan. A comma-separated list as well as ranges are supported.")
-	scanCmd.PersistentFlags().StringVarP(&rawTargets, "targets", "t", "", "Targets to scan.")
+	scanCmd.PersistentFlags().StringVarP(&rawPorts, "ports", "p", "", "Ports to scan. A comma-separated list of ports, ranges (-1024, 60000-), top lists (top25), service names (http,ssh) and exclusions (all,!9100). Prefix with T: or U: to select the protocol, e.g. T:80,443,U:53")
+	scanCmd.PersistentFlags().StringVarP(&rawUDPPorts, "udp-ports", "U", "", "UDP ports to scan in addition to --ports, same format as --ports")
+	scanCmd.PersistentFlags().StringVar(&topPortsFile, "top-ports-file", "", "nmap-services or frequency file used for top lists like top100 or top90%")
+	scanCmd.PersistentFlags().StringVar(&excludedPorts, "exclude-ports", "", "Ports not to scan, same format as --ports. Ports without prefix are excluded for TCP and UDP")
+	scanCmd.PersistentFlags().StringVarP(&rawTargets, "targets", "t", "", "Targets to scan. A comma-separated list of IPs, networks and DNS names. If neither --targets nor --input-list is given, targets are read from stdin")
+	scanCmd.PersistentFlags().StringVarP(&inputList, "input-list", "i", "", "File to read targets from, one per line. Use - for stdin")
+	scanCmd.PersistentFlags().StringVar(&excludedTargets, "exclude", "", "Comma-separated list of IPs, networks and DNS names not to scan")
+	scanCmd.PersistentFlags().StringVar(&excludeFile, "exclude-file", "", "File containing IPs, networks and DNS names not to scan, one per line")
+	scanCmd.SetGlobalNormalizationFunc(nmapFlagNames)
 	scanCmd.PersistentFlags().BoolVarP(&scanUDP, "udp", "u", false, "This flag switches to UDP scanning.")
-	scanCmd.PersistentFlags().DurationVarP(&timeout, "timeout", "", 1000*time.Millisecond, "Timeout for TCP connect.")
+	scanCmd.PersistentFlags().MarkDeprecated("udp", "prefix ports with U: instead, e.g. -p T:80,U:53")
+	scanCmd.PersistentFlags().DurationVarP(&timeout, "timeout", "", 1000*time.Millisecond, "Timeout for TCP connect and UDP responses.")
 	scanCmd.PersistentFlags().StringVarP(&outputFile, "output", "o", "", "The file to write json output")
 	scanCmd.PersistentFlags().UintVarP(&workers, "workers", "w", 1000, "How many workers to use for scanning.")
-	scanCmd.MarkFlagRequired("ports")
-	scanCmd.MarkFlagRequired("targets") // remove once stdin scanning is implemented
+	scanCmd.PersistentFlags().StringVar(&ratelimit, "ratelimit", "none", "How many scans are started per second, or none")
+	scanCmd.PersistentFlags().UintVar(&hostsPerBatch, "hosts-per-batch", 256, "How many hosts are scanned at once. Results are written after each batch")
+	scanCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "Config file to read scanner options from. Uses the scannerconfig section if present, so server configurations work as well")
+	scanCmd.PersistentFlags().BoolVar(&serviceDetection, "service-detection", false, "Identify services and their versions on open ports")
+	scanCmd.PersistentFlags().IntVar(&versionIntensity, "version-intensity", 7, "Service detection sends probes up to this rarity, from 0 (few) to 9 (all)")
+	scanCmd.PersistentFlags().StringVar(&serviceProbeFile, "service-probes", "", "nmap-service-probes file used for service detection instead of the built-in probes")
+	scanCmd.PersistentFlags().StringArrayVarP(&scannerOptions, "scanner-option", "O", []string{}, "Set a scanner option as key=value, e.g. udp.fast=true or tcp.timeout=500ms. May be repeated")
 	log.SetFormatter(&utils.Formatter{})
 }
 
-func parseTargets() <-chan (string) {
+// parseScanFlags parses the flags of the scan command. -iL is rewritten to
+// --iL first, otherwise the flag parser reads it as -i with the value L
+// and the file as a target
+func parseScanFlags(cmd *cobra.Command, args []string) error {
+	rewritten := make([]string, 0, len(args))
+	for i, arg := range args {
+		if arg == "--" {
+			rewritten = append(rewritten, args[i:]...)
+			break
+		}
+		if arg == "-iL" || strings.HasPrefix(arg, "-iL=") {
+			arg = "-" + arg
+		}
+		rewritten = append(rewritten, arg)
+	}
+	// Adds the help flag and merges the persistent flags, as cobra would before parsing
+	cmd.InitDefaultHelpFlag()
+	return cmd.Flags().Parse(rewritten)
+}
+
+// nmapFlagNames allows --iL and --excludefile as used by nmap
+func nmapFlagNames(f *pflag.FlagSet, name string) pflag.NormalizedName {
+	switch name {
+	case "iL":
+		name = "input-list"
+	case "excludefile":
+		name = "exclude-file"
+	}
+	return pflag.NormalizedName(name)
+}
+
+// targetInput returns a reader providing one target per line. Targets
+// passed via --targets come first, followed by the input list.
+// If neither is given, targets are read from stdin
+func targetInput() io.Reader {
+	sources := make([]io.Reader, 0, 2)
+	if rawTargets != "" {
+		sources = append(sources, strings.NewReader(strings.ReplaceAll(rawTargets, ",", "\n")+"\n"))
+	}
+	if inputList == "-" || (inputList == "" && rawTargets == "") {
+		log.WithFields(log.Fields{
+			"module": "cmd.scan",
+			"src":    "targetInput",
+		}).Info("Reading targets from stdin")
+		sources = append(sources, os.Stdin)
+	} else if inputList != "" {
+		file, err := os.Open(inputList)
+		utils.CheckError(err, true)
+		sources = append(sources, file)
+	}
+	return io.MultiReader(sources...)
+}
+
+// parseExcludes builds the blacklist from --exclude and --exclude-file
+func parseExcludes() *targetgeneration.NrayBlacklist {
+	blacklist := targetgeneration.NewBlacklist()
+	for _, excluded := range strings.Split(excludedTargets, ",") {
+		if excluded = strings.TrimSpace(excluded); excluded != "" {
+			blacklist.AddToBlacklist(excluded)
+		}
+	}
+	if excludeFile != "" {
+		utils.CheckError(blacklist.AddFileToBlacklist(excludeFile), true)
+	}
+	return blacklist
+}
+
+// parseTargets reads targets line by line and streams the expanded hosts.
+// Empty lines and lines starting with # are ignored
+func parseTargets(input io.Reader, blacklist *targetgeneration.NrayBlacklist) <-chan (string) {
 	targetChan := make(chan (string), 500)
 	go func(targets chan<- (string)) {
-		for _, rawTarget := range strings.Split(rawTargets, ",") {
-			if utils.Ipv4NetRegexpr.MatchString(rawTarget) { // An IPv4 network
-				_, ipnet, err := net.ParseCIDR(rawTarget)
-				targetCount += cidr.AddressCount(ipnet)
-				utils.CheckError(err, true)
-				ipStream := targetgeneration.GenerateIPStreamFromCIDR(ipnet, nil)
-				for ip := range ipStream {
-					targets <- ip.String()
-				}
-			} else if utils.Ipv4Regexpr.MatchString(rawTarget) { // An IPv4 address
-				targetCount++
-				targets <- rawTarget
-			} else if utils.MayBeFQDN(rawTarget) { // Probably a FQDN
-				targetCount++
-				targets <- rawTarget
-			} else {
-				log.WithFields(log.Fields{
-					"module": "cmd.scan",
-					"src":    "parseTargets",
-				}).Printf("This does not look like a valid target: %s", rawTarget)
+		lineScanner := bufio.NewScanner(input)
+		for lineScanner.Scan() {
+			rawTarget := strings.TrimSpace(lineScanner.Text())
+			if rawTarget == "" || strings.HasPrefix(rawTarget, "#") {
+				continue
 			}
+			expandTarget(rawTarget, blacklist, targets)
 		}
+		utils.CheckError(lineScanner.Err(), false)
 		close(targets)
 	}(targetChan)
 	return targetChan
 }
 
-func parsePorts() []uint16 {
-	if len(rawPorts) == 0 {
-		log.Fatal("Port list is empty")
-	}
-	var parsedPorts []uint16
-	if scanUDP {
-		parsedPorts = targetgeneration.ParsePorts(strings.Split(rawPorts, ","), "udp")
+// expandTarget sends all hosts of a target that are not excluded
+func expandTarget(rawTarget string, blacklist *targetgeneration.NrayBlacklist, targets chan<- (string)) {
+	if utils.Ipv4NetRegexpr.MatchString(rawTarget) { // An IPv4 network
+		_, ipnet, err := net.ParseCIDR(rawTarget)
+		utils.CheckError(err, true)
+		targetCount += cidr.AddressCount(ipnet)
+		ipStream := targetgeneration.GenerateIPStreamFromCIDR(ipnet, blacklist)
+		for ip := range ipStream {
+			targets <- ip.String()
+		}
+	} else if utils.Ipv4Regexpr.MatchString(rawTarget) { // An IPv4 address
+		if blacklist.IsIPBlacklisted(rawTarget) {
+			logExcludedTarget(rawTarget)
+			return
+		}
+		targetCount++
+		targets <- rawTarget
+	} else if utils.MayBeFQDN(rawTarget) { // Probably a FQDN
+		if blacklist.IsDNSNameBlacklisted(rawTarget) {
+			logExcludedTarget(rawTarget)
+			return
+		}
+		targetCount++
+		targets <- rawTarget
 	} else {
-		parsedPorts = targetgeneration.ParsePorts(strings.Split(rawPorts, ","), "tcp")
+		log.WithFields(log.Fields{
+			"module": "cmd.scan",
+			"src":    "parseTargets",
+		}).Printf("This does not look like a valid target: %s", rawTarget)
 	}
-	return parsedPorts
 }
 
-func prepareScan(targetChan <-chan (string), ports []uint16) <-chan (*scanner.Target) {
-	scanChan := make(chan (*scanner.Target), 1000)
-	var proto string
+func logExcludedTarget(target string) {
+	log.WithFields(log.Fields{
+		"module": "cmd.scan",
+		"src":    "parseTargets",
+	}).Debugf("Target is excluded: %s", target)
+}
+
+// parsePorts returns the TCP and UDP ports to scan. Ports without a
+// protocol prefix are TCP ports unless the deprecated --udp flag is set,
+// ports passed via --udp-ports are UDP ports
+func parsePorts() ([]uint16, []uint16) {
+	if len(rawPorts) == 0 && len(rawUDPPorts) == 0 {
+		log.Fatal("Port list is empty, use --ports and/or --udp-ports")
+	}
+	if topPortsFile != "" {
+		ranking, err := targetgeneration.LoadPortRanking(topPortsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		targetgeneration.SetPortRanking(ranking)
+	}
+	defaultProto := "tcp"
 	if scanUDP {
-		proto = "UDP"
-	} else {
-		proto = "TCP"
-	}
-	for hostToScan := range targetChan {
-		for _, port := range ports {
-			t := &scanner.Target{
-				Host:     hostToScan,
-				Protocol: proto,
-				Port:     uint32(port),
-			}
-			scanChan <- t
+		defaultProto = "udp"
+	}
+	tcpPorts, udpPorts, err := targetgeneration.ParsePortSpec([]string{rawPorts}, defaultProto)
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, additionalUDPPorts, err := targetgeneration.ParsePortSpec([]string{rawUDPPorts}, "udp")
+	if err != nil {
+		log.Fatal(err)
+	}
+	udpPorts = append(udpPorts, additionalUDPPorts...)
+	if excludedPorts != "" {
+		tcpPorts, udpPorts, err = targetgeneration.ExcludePorts(tcpPorts, udpPorts, []string{excludedPorts})
+		if err != nil {
+			log.Fatal(err)
 		}
 	}
-	close(scanChan)
-	return scanChan
-}
-
-func startScan(funcChan <-chan (func()), resultChan chan<- *scanner.PortscanResult) {
-	var wg sync.WaitGroup
-	log.WithFields(log.Fields{
-		"module": "cmd.scan",
-		"src":    "startScan",
-	}).Printf("Starting workers: %d", workers)
-	for i := uint(0); i < workers; i++ {
-		wg.Add(1)
-		go func(queue <-chan func()) {
-			for queuedTask := range queue {
-				queuedTask()
-			}
-			wg.Done()
-		}(funcChan)
+	if len(tcpPorts) == 0 && len(udpPorts) == 0 {
+		log.Fatal("No ports left to scan")
 	}
-	wg.Wait()
-	close(resultChan)
+	return tcpPorts, udpPorts
 }
 
-// prepareScanFuncs returns a channel where scan functions are sent over
-// They are completely prepared and just have to be called
-// This is a leightweight version of the larger implementation when using
-// the full feature set
-func prepareScanFuncs(targetChan <-chan (*scanner.Target), results chan<- *scanner.PortscanResult) <-chan func() {
-	scannerconf := viper.New()
-	scannerconf.Set("timeout", timeout)
-	var tcpscanner = &scanner.TCPScanner{}
-	tcpscanner.Configure(scannerconf)
-	var udpscanner = &scanner.UDPScanner{}
-	udpscanner.Configure(scannerconf)
-	scanFuncs := make(chan func(), 100)
-
-	go func(targetChan <-chan (*scanner.Target), results chan<- *scanner.PortscanResult) {
-		for targetHost := range targetChan {
-			if targetHost.Protocol == "UDP" {
-				t := targetHost.Host
-				port := targetHost.Port
-				scanFuncs <- func() {
-					result, err := scanner.UDPProtoScan(t, port, *udpscanner)
-					utils.CheckError(err, false)
-					if result != nil {
-						results <- result
-					}
+// batchTargets groups the streamed hosts into work batches like the ones
+// the server sends to nodes. A batch is also sent if it is not full but
+// no new hosts arrived for a second, so slow input doesn't stall the scan
+func batchTargets(targetChan <-chan (string), tcpPorts []uint16, udpPorts []uint16) <-chan *nraySchema.MoreWorkReply {
+	batches := make(chan *nraySchema.MoreWorkReply)
+	tcpPorts32 := make([]uint32, 0, len(tcpPorts))
+	for _, port := range tcpPorts {
+		tcpPorts32 = append(tcpPorts32, uint32(port))
+	}
+	udpPorts32 := make([]uint32, 0, len(udpPorts))
+	for _, port := range udpPorts {
+		udpPorts32 = append(udpPorts32, uint32(port))
+	}
+	go func() {
+		batchID := uint64(0)
+		hosts := make([]string, 0, hostsPerBatch)
+		flush := func() {
+			if len(hosts) == 0 {
+				return
+			}
+			batchID++
+			batches <- &nraySchema.MoreWorkReply{
+				Batchid: batchID,
+				Targets: &nraySchema.ScanTargets{
+					Rhosts:   hosts,
+					Tcpports: tcpPorts32,
+					Udpports: udpPorts32,
+				},
+			}
+			hosts = make([]string, 0, hostsPerBatch)
+		}
+		idle := time.NewTimer(time.Second)
+		defer idle.Stop()
+		for {
+			select {
+			case host, more := <-targetChan:
+				if !more {
+					flush()
+					close(batches)
+					return
 				}
-			} else { // Assume TCP default
-				t := targetHost.Host
-				port := targetHost.Port
-				scanFuncs <- func() {
-					result, err := scanner.TCPConnectIsOpen(t, port, timeout)
-					utils.CheckError(err, false)
-					if result != nil {
-						results <- result
-					}
+				hosts = append(hosts, host)
+				if uint(len(hosts)) >= hostsPerBatch {
+					flush()
 				}
+			case <-idle.C:
+				flush()
 			}
+			if !idle.Stop() {
+				select {
+				case <-idle.C:
+				default:
+				}
+			}
+			idle.Reset(time.Second)
 		}
-		close(scanFuncs)
-	}(targetChan, results)
+	}()
+	return batches
+}
 
-	return scanFuncs
+// scanConfig builds the scanner configuration. It is read from the scannerconfig
+// section of --config, or the whole file if there is no such section.
+// Flags take precedence over the file, --timeout and --workers also apply if the
+// file doesn't set them
+func scanConfig(cmd *cobra.Command) *viper.Viper {
+	scannerConfig := viper.New()
+	if cfgFile != "" {
+		fileConfig := viper.New()
+		fileConfig.SetConfigFile(cfgFile)
+		utils.CheckError(fileConfig.ReadInConfig(), true)
+		if fileConfig.IsSet("scannerconfig") {
+			fileConfig = fileConfig.Sub("scannerconfig")
+		}
+		utils.CheckError(scannerConfig.MergeConfigMap(fileConfig.AllSettings()), true)
+	}
+	flags := cmd.Flags()
+	if flags.Changed("workers") || !scannerConfig.IsSet("workers") {
+		setScannerOption(scannerConfig, "workers", workers)
+	}
+	if flags.Changed("ratelimit") {
+		setScannerOption(scannerConfig, "ratelimit", ratelimit)
+	}
+	for _, key := range []string{"tcp.timeout", "udp.timeout"} {
+		if flags.Changed("timeout") || !scannerConfig.IsSet(key) {
+			setScannerOption(scannerConfig, key, timeout.String())
+		}
+	}
+	if flags.Changed("service-detection") {
+		setScannerOption(scannerConfig, "servicedetection.enabled", serviceDetection)
+	}
+	if flags.Changed("version-intensity") {
+		setScannerOption(scannerConfig, "servicedetection.intensity", versionIntensity)
+	}
+	if flags.Changed("service-probes") {
+		setScannerOption(scannerConfig, "servicedetection.probeFile", serviceProbeFile)
+	}
+	for _, option := range scannerOptions {
+		keyValue := strings.SplitN(option, "=", 2)
+		if len(keyValue) != 2 || keyValue[0] == "" {
+			log.Fatalf("Scanner options have to be passed as key=value, got %s", option)
+		}
+		setScannerOption(scannerConfig, keyValue[0], keyValue[1])
+	}
+	utils.CheckError(scanner.LoadUDPProbeFile(scannerConfig), true)
+	utils.CheckError(scanner.LoadServiceProbeFile(scannerConfig), true)
+	return scannerConfig
+}
+
+// setScannerOption merges a single value into the configuration. Unlike viper's Set,
+// this keeps the other values of the same section when using Sub() later on
+func setScannerOption(config *viper.Viper, key string, value interface{}) {
+	path := strings.Split(strings.ToLower(key), ".")
+	option := map[string]interface{}{path[len(path)-1]: value}
+	for i := len(path) - 2; i >= 0; i-- {
+		option = map[string]interface{}{path[i]: option}
+	}
+	utils.CheckError(config.MergeConfigMap(option), true)
 }
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	targetgeneration "github.com/nray-scanner/nray/core/targetGeneration"
+	"github.com/spf13/viper"
+)
+
+func TestParseTargets(t *testing.T) {
+	blacklist := targetgeneration.NewBlacklist()
+	blacklist.AddToBlacklist("10.0.0.0/31")
+	blacklist.AddToBlacklist("192.168.0.1")
+	blacklist.AddToBlacklist("excluded.example.local")
+
+	input := strings.NewReader("10.0.0.0/30\n\n# comment\n 192.168.0.1 \n192.168.0.2\nexcluded.example.local\nincluded.example.local\nhttp://invalid/\n")
+	targets := make([]string, 0)
+	for target := range parseTargets(input, blacklist) {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	expected := []string{"10.0.0.2", "10.0.0.3", "192.168.0.2", "included.example.local"}
+	if strings.Join(targets, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, targets)
+	}
+}
+
+func TestSetScannerOption(t *testing.T) {
+	config := viper.New()
+	config.MergeConfigMap(map[string]interface{}{"udp": map[string]interface{}{"fast": true, "timeout": "1s"}})
+	setScannerOption(config, "udp.timeout", "200ms")
+	setScannerOption(config, "workers", 10)
+	udpConfig := config.Sub("udp")
+	if !udpConfig.GetBool("fast") || udpConfig.GetString("timeout") != "200ms" {
+		t.Errorf("Wrong UDP configuration: %v", udpConfig.AllSettings())
+	}
+	if config.GetInt("workers") != 10 {
+		t.Errorf("Workers not set")
+	}
+}
+
+func TestBatchTargets(t *testing.T) {
+	hostsPerBatch = 2
+	targetChan := make(chan string, 5)
+	for _, host := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		targetChan <- host
+	}
+	close(targetChan)
+	batchSizes := make([]int, 0)
+	for batch := range batchTargets(targetChan, []uint16{80}, []uint16{53}) {
+		batchSizes = append(batchSizes, len(batch.Targets.Rhosts))
+		if len(batch.Targets.Tcpports) != 1 || len(batch.Targets.Udpports) != 1 {
+			t.Errorf("Wrong ports in batch %d", batch.Batchid)
+		}
+	}
+	if len(batchSizes) != 2 || batchSizes[0] != 2 || batchSizes[1] != 1 {
+		t.Errorf("Wrong batches: %v", batchSizes)
+	}
+}
+
+func TestParseScanFlags(t *testing.T) {
+	defer func() { inputList, rawPorts = "", "" }()
+	if err := parseScanFlags(scanCmd, []string{"-iL", "targets.txt", "-p", "80", "--", "-iL"}); err != nil {
+		t.Fatal(err)
+	}
+	if inputList != "targets.txt" || rawPorts != "80" {
+		t.Errorf("Expected targets.txt as input list, got %q and ports %q", inputList, rawPorts)
+	}
+	if args := scanCmd.Flags().Args(); len(args) != 1 || args[0] != "-iL" {
+		t.Errorf("Arguments after -- must not be rewritten, got %v", args)
+	}
+	if err := parseScanFlags(scanCmd, []string{"-iL=list.txt"}); err != nil || inputList != "list.txt" {
+		t.Errorf("Expected list.txt as input list, got %q %v", inputList, err)
+	}
+}
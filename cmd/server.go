@@ -12,6 +12,8 @@ import (
 	"github.com/spf13/viper"
 )
 
+var localNodes int
+
 var serverCmd = &cobra.Command{
 	Use:   "server",
 	Short: "Run as server, waiting for nodes to connect and perform a scan",
@@ -19,6 +21,9 @@ var serverCmd = &cobra.Command{
 Perform scanning with all configuration options and multiple scanner nodes at once`,
 	Run: func(cmd *cobra.Command, args []string) {
 		config := initServerConfig()
+		if cmd.Flags().Changed("local-nodes") {
+			config.Set("localNodes", localNodes)
+		}
 		err := core.InitGlobalServerConfig(config)
 		utils.CheckError(err, false)
 		core.Start()
@@ -34,6 +39,7 @@ func init() {
 
 	rootCmd.AddCommand(serverCmd)
 	serverCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file")
+	serverCmd.PersistentFlags().IntVar(&localNodes, "local-nodes", 0, "Start this many scanner nodes in the server process. Overrides localNodes of the config file")
 	serverCmd.MarkPersistentFlagRequired("config")
 }
 
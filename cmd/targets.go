@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	targetgeneration "github.com/nray-scanner/nray/core/targetGeneration"
+	"github.com/nray-scanner/nray/utils"
+	"github.com/spf13/cobra"
+)
+
+var listHosts bool
+var targetsOutputFile string
+
+var targetsCmd = &cobra.Command{
+	Use:   "targets",
+	Short: "Expands the targets of a server configuration without scanning anything",
+	Long: `Runs the target generators configured for the server and shows what would be
+scanned: hosts, ports, the number of batches and targets. Entries that match
+nothing and entries overlapping with the blacklist are reported.
+Nothing is sent over the network, except for DNS queries if the server is
+configured to resolve targets.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		config := initServerConfig()
+		targetGenerator := targetgeneration.TargetGenerator{}
+		targetGenerator.Init(config.Sub("targetgenerator"))
+		summary := targetGenerator.Summarize(listHosts || targetsOutputFile != "")
+
+		if targetsOutputFile != "" {
+			export, err := json.MarshalIndent(summary, "", "  ")
+			utils.CheckError(err, true)
+			utils.CheckError(os.WriteFile(targetsOutputFile, export, 0644), true)
+		}
+		if listHosts {
+			for _, host := range summary.Hosts {
+				fmt.Println(host)
+			}
+		}
+		printTargetSummary(summary)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(targetsCmd)
+	targetsCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file")
+	targetsCmd.PersistentFlags().BoolVar(&listHosts, "list-hosts", false, "Print every host that would be scanned")
+	targetsCmd.PersistentFlags().StringVarP(&targetsOutputFile, "output", "o", "", "Export hosts, ports, counts and issues to this file as JSON")
+	targetsCmd.MarkPersistentFlagRequired("config")
+}
+
+func printTargetSummary(summary targetgeneration.TargetSummary) {
+	fmt.Printf("Hosts:         %d\n", summary.HostCount)
+	fmt.Printf("TCP ports:     %d (%s)\n", len(summary.TCPPorts), formatPortList(summary.TCPPorts))
+	fmt.Printf("UDP ports:     %d (%s)\n", len(summary.UDPPorts), formatPortList(summary.UDPPorts))
+	fmt.Printf("Batches:       %d\n", summary.BatchCount)
+	fmt.Printf("Targets:       %d\n", summary.TargetCount)
+	if summary.ExpectedCount != summary.TargetCount {
+		// The server estimates the count for progress reporting, e.g. networks
+		// overlapping with the blacklist make the estimate differ
+		fmt.Printf("Estimated:     %d\n", summary.ExpectedCount)
+	}
+	if len(summary.Issues) == 0 {
+		return
+	}
+	fmt.Printf("\n%d issues found:\n", len(summary.Issues))
+	for _, issue := range summary.Issues {
+		fmt.Printf("  [%s] %s %s\n", issue.Generator, issue.Entry, issue.Problem)
+	}
+}
+
+// formatPortList collapses consecutive ports into ranges, e.g. "21-23,80"
+// Ports are expected to be sorted
+func formatPortList(ports []uint16) string {
+	if len(ports) == 0 {
+		return "none"
+	}
+	parts := make([]string, 0)
+	start := ports[0]
+	for pos := 1; pos <= len(ports); pos++ {
+		if pos < len(ports) && ports[pos] == ports[pos-1]+1 {
+			continue
+		}
+		end := ports[pos-1]
+		if start == end {
+			parts = append(parts, fmt.Sprintf("%d", start))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", start, end))
+		}
+		if pos < len(ports) {
+			start = ports[pos]
+		}
+	}
+	return strings.Join(parts, ",")
+}
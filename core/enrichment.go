@@ -0,0 +1,232 @@
+package core
+
+import (
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/golang/time/rate"
+	nraySchema "github.com/nray-scanner/nray/schemas"
+	"github.com/nray-scanner/nray/utils"
+	"github.com/oschwald/maxminddb-golang"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// mmdbRecord holds the fields of GeoIP2/GeoLite2 Country, City, ASN and ISP
+// databases nray uses. Other MaxMind-format databases work if they use the same names
+type mmdbRecord struct {
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	ASN            uint32 `maxminddb:"autonomous_system_number"`
+	ASOrganization string `maxminddb:"autonomous_system_organization"`
+	Organization   string `maxminddb:"organization"`
+}
+
+// enricher adds PTR records and data from MMDB files to result events before
+// they are passed to the event handlers. It runs in its own goroutines, so slow
+// lookups don't block the server's main loop. The results of a batch are enriched
+// by a pool of workers, batches are passed on in the order they were submitted
+type enricher struct {
+	// nil if PTR records are not looked up
+	resolver  *utils.Resolver
+	databases []*maxminddb.Reader
+	jobs      chan enrichmentJob
+	workers   int
+	logEvents func([]*nraySchema.Event)
+	done      chan struct{}
+	// Batches waiting for enrichment, there is no limit so the server never blocks
+	queue     [][]*nraySchema.Event
+	queueLock sync.Mutex
+	queued    *sync.Cond
+	closed    bool
+	// A warning is logged whenever this many batches are waiting
+	warnQueueSize int
+}
+
+// enrichmentJob is a single result a worker enriches
+type enrichmentJob struct {
+	result *nraySchema.ScanResult
+	done   *sync.WaitGroup
+}
+
+// newEnricher reads the enrichment configuration. It returns nil if enrichment is disabled
+func newEnricher(config *viper.Viper, logEvents func([]*nraySchema.Event)) (*enricher, error) {
+	config = utils.ApplyDefaultEnrichmentConfig(config)
+	if !config.GetBool("enabled") {
+		return nil, nil
+	}
+	e := &enricher{
+		jobs:          make(chan enrichmentJob),
+		workers:       max(config.GetInt("internal.workers"), 1),
+		logEvents:     logEvents,
+		done:          make(chan struct{}),
+		warnQueueSize: max(config.GetInt("internal.queueSize"), 1),
+	}
+	e.queued = sync.NewCond(&e.queueLock)
+	if config.GetBool("ptr.enabled") {
+		e.resolver = utils.NewResolver(config.GetStringSlice("ptr.nameservers"), config.GetDuration("ptr.timeout"), config.GetDuration("ptr.maxCacheTime"))
+		limit, err := parseRatelimit(config.GetString("ptr.ratelimit"))
+		if err != nil {
+			return nil, err
+		}
+		if limit > 0 {
+			e.resolver.SetRatelimit(rate.NewLimiter(rate.Limit(limit), 1))
+		}
+	}
+	for _, file := range config.GetStringSlice("mmdbFiles") {
+		database, err := maxminddb.Open(file)
+		if err != nil {
+			e.closeDatabases()
+			return nil, fmt.Errorf("Unable to open MMDB file %s: %v", file, err)
+		}
+		log.WithFields(log.Fields{
+			"module": "core.enrichment",
+			"src":    "newEnricher",
+		}).Infof("Enriching results with %s from %s", database.Metadata.DatabaseType, file)
+		e.databases = append(e.databases, database)
+	}
+	var workers sync.WaitGroup
+	for i := 0; i < e.workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			e.work()
+		}()
+	}
+	go e.run(&workers)
+	return e, nil
+}
+
+// run hands out the results of each batch to the workers and passes the batch
+// on once all of them are enriched
+func (e *enricher) run(workers *sync.WaitGroup) {
+	for {
+		events, more := e.next()
+		if !more {
+			break
+		}
+		var enriched sync.WaitGroup
+		for _, event := range events {
+			if result := event.GetResult(); result != nil {
+				enriched.Add(1)
+				e.jobs <- enrichmentJob{result: result, done: &enriched}
+			}
+		}
+		enriched.Wait()
+		e.logEvents(events)
+	}
+	close(e.jobs)
+	workers.Wait()
+	e.closeDatabases()
+	close(e.done)
+}
+
+// work enriches results until the enricher is closed
+func (e *enricher) work() {
+	for job := range e.jobs {
+		e.enrich(job.result)
+		job.done.Done()
+	}
+}
+
+// next blocks until a batch is queued. It returns false once the enricher is
+// closed and all batches have been handed out
+func (e *enricher) next() ([]*nraySchema.Event, bool) {
+	e.queueLock.Lock()
+	defer e.queueLock.Unlock()
+	for len(e.queue) == 0 && !e.closed {
+		e.queued.Wait()
+	}
+	if len(e.queue) == 0 {
+		return nil, false
+	}
+	events := e.queue[0]
+	e.queue[0] = nil
+	e.queue = e.queue[1:]
+	return events, true
+}
+
+// submit queues events for enrichment. It never blocks, events are passed on
+// enriched and in order even if enrichment can't keep up
+func (e *enricher) submit(events []*nraySchema.Event) {
+	e.queueLock.Lock()
+	defer e.queueLock.Unlock()
+	e.queue = append(e.queue, events)
+	if len(e.queue)%e.warnQueueSize == 0 {
+		log.WithFields(log.Fields{
+			"module": "core.enrichment",
+			"src":    "submit",
+		}).Warningf("Enrichment can't keep up, %d batches are waiting", len(e.queue))
+	}
+	e.queued.Signal()
+}
+
+// close waits until all submitted events are passed on. It may be called more than once
+func (e *enricher) close() {
+	e.queueLock.Lock()
+	e.closed = true
+	e.queued.Signal()
+	e.queueLock.Unlock()
+	<-e.done
+}
+
+func (e *enricher) closeDatabases() {
+	for _, database := range e.databases {
+		database.Close()
+	}
+}
+
+// enrich adds what is known about the IP address of a result
+func (e *enricher) enrich(result *nraySchema.ScanResult) {
+	address := resultAddress(result)
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return
+	}
+	enrichment := &nraySchema.Enrichment{Address: ip.String()}
+	if e.resolver != nil {
+		names, err := e.resolver.LookupAddr(enrichment.Address)
+		if err == nil {
+			enrichment.Ptr = names
+		} else {
+			log.WithFields(log.Fields{
+				"module": "core.enrichment",
+				"src":    "enrich",
+			}).Debugf("No PTR record for %s: %v", enrichment.Address, err)
+		}
+	}
+	for _, database := range e.databases {
+		var record mmdbRecord
+		if err := database.Lookup(ip, &record); err != nil {
+			utils.CheckError(err, false)
+			continue
+		}
+		// Earlier files take precedence
+		if enrichment.Country == "" {
+			enrichment.Country = record.Country.ISOCode
+			enrichment.CountryName = record.Country.Names["en"]
+		}
+		if enrichment.Asn == 0 {
+			enrichment.Asn = record.ASN
+		}
+		if enrichment.Organization == "" {
+			enrichment.Organization = record.ASOrganization
+		}
+		if enrichment.Organization == "" {
+			enrichment.Organization = record.Organization
+		}
+	}
+	result.Enrichment = enrichment
+}
+
+// resultAddress returns the IP address a result was scanned on. Names are only
+// known as IP address if the node resolved them
+func resultAddress(result *nraySchema.ScanResult) string {
+	if address := result.GetPortscan().GetAddress(); address != "" {
+		return address
+	}
+	return result.GetTarget()
+}
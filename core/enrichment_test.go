@@ -0,0 +1,172 @@
+package core
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	nraySchema "github.com/nray-scanner/nray/schemas"
+	"github.com/spf13/viper"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// startStubPTRServer answers PTR queries for a fixed set of reverse names and
+// counts the queries it got
+func startStubPTRServer(t *testing.T, records map[string]string) (string, func() int) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	var lock sync.Mutex
+	queries := 0
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var query dnsmessage.Message
+			if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) != 1 {
+				continue
+			}
+			lock.Lock()
+			queries++
+			lock.Unlock()
+			question := query.Questions[0]
+			response := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.Header.ID, Response: true, RecursionAvailable: true},
+				Questions: query.Questions,
+			}
+			if name, exists := records[question.Name.String()]; exists && question.Type == dnsmessage.TypePTR {
+				response.Answers = append(response.Answers, dnsmessage.Resource{
+					Header: dnsmessage.ResourceHeader{Name: question.Name, Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET, TTL: 300},
+					Body:   &dnsmessage.PTRResource{PTR: dnsmessage.MustNewName(name)},
+				})
+			} else {
+				response.Header.RCode = dnsmessage.RCodeNameError
+			}
+			packed, _ := response.Pack()
+			conn.WriteTo(packed, addr)
+		}
+	}()
+	return conn.LocalAddr().String(), func() int {
+		lock.Lock()
+		defer lock.Unlock()
+		return queries
+	}
+}
+
+func testEnrichmentConfig(settings map[string]interface{}) *viper.Viper {
+	config := viper.New()
+	for key, value := range settings {
+		config.Set(key, value)
+	}
+	return config
+}
+
+func resultEvent(target string, address string) *nraySchema.Event {
+	return &nraySchema.Event{EventData: &nraySchema.Event_Result{Result: &nraySchema.ScanResult{
+		Target: target,
+		Result: &nraySchema.ScanResult_Portscan{Portscan: &nraySchema.PortScanResult{Target: target, Address: address}},
+	}}}
+}
+
+func TestEnricher(t *testing.T) {
+	nameserver, queryCount := startStubPTRServer(t, map[string]string{"10.2.0.192.in-addr.arpa.": "web.example.test."})
+	var logged []*nraySchema.Event
+	e, err := newEnricher(testEnrichmentConfig(map[string]interface{}{
+		"enabled":         true,
+		"ptr.nameservers": []string{nameserver},
+		"mmdbFiles":       []string{"testdata/enrichment-test.mmdb"},
+	}), func(events []*nraySchema.Event) { logged = append(logged, events...) })
+	if err != nil || e == nil {
+		t.Fatalf("Expected an enricher, got %v", err)
+	}
+
+	e.submit([]*nraySchema.Event{
+		resultEvent("web.example.test", "192.0.2.10"),
+		resultEvent("192.0.2.10", ""),
+		resultEvent("198.51.100.1", ""),
+		resultEvent("unresolved.example.test", ""),
+		{EventData: &nraySchema.Event_Environment{Environment: &nraySchema.EnvironmentInformation{}}},
+	})
+	e.close()
+	e.close()
+	if len(logged) != 5 {
+		t.Fatalf("Expected all events to be passed on, got %d", len(logged))
+	}
+	for _, event := range logged[:2] {
+		enrichment := event.GetResult().GetEnrichment()
+		if enrichment.GetAddress() != "192.0.2.10" || len(enrichment.GetPtr()) != 1 || enrichment.GetPtr()[0] != "web.example.test" {
+			t.Errorf("Expected the PTR record of 192.0.2.10, got %v", enrichment)
+		}
+		if enrichment.GetCountry() != "DE" || enrichment.GetCountryName() != "Germany" || enrichment.GetAsn() != 64496 || enrichment.GetOrganization() != "Example Org" {
+			t.Errorf("Expected the MMDB data of 192.0.2.0/24, got %v", enrichment)
+		}
+	}
+	if enrichment := logged[2].GetResult().GetEnrichment(); enrichment.GetAddress() != "198.51.100.1" || enrichment.GetCountry() != "" || len(enrichment.GetPtr()) != 0 {
+		t.Errorf("Expected no data for 198.51.100.1, got %v", enrichment)
+	}
+	if logged[3].GetResult().GetEnrichment() != nil {
+		t.Errorf("Names without address must not be enriched")
+	}
+	// Each address is looked up once
+	if count := queryCount(); count != 2 {
+		t.Errorf("Expected 2 PTR queries, got %d", count)
+	}
+}
+
+func TestEnricherBackpressure(t *testing.T) {
+	release := make(chan struct{})
+	logged := make(chan []*nraySchema.Event, 10)
+	e, err := newEnricher(testEnrichmentConfig(map[string]interface{}{
+		"enabled":            true,
+		"ptr.enabled":        false,
+		"internal.queueSize": 1,
+	}), func(events []*nraySchema.Event) {
+		if events[0].GetResult().GetTarget() == "192.0.2.1" {
+			<-release
+		}
+		logged <- events
+	})
+	if err != nil || e == nil {
+		t.Fatalf("Expected an enricher, got %v", err)
+	}
+
+	// The first batch blocks the enricher, the others fill the queue beyond its size
+	targets := []string{"192.0.2.1", "192.0.2.2", "192.0.2.3", "192.0.2.4", "192.0.2.5"}
+	submitted := make(chan struct{})
+	go func() {
+		for _, target := range targets {
+			e.submit([]*nraySchema.Event{resultEvent(target, "")})
+		}
+		close(submitted)
+	}()
+	select {
+	case <-submitted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Submitting to a full queue blocked")
+	}
+	close(release)
+	e.close()
+	for _, target := range targets {
+		if events := <-logged; events[0].GetResult().GetTarget() != target || events[0].GetResult().GetEnrichment() == nil {
+			t.Errorf("Expected %s to be enriched in order, got %v", target, events)
+		}
+	}
+}
+
+func TestNewEnricher(t *testing.T) {
+	if e, err := newEnricher(testEnrichmentConfig(nil), nil); e != nil || err != nil {
+		t.Errorf("Enrichment must be disabled by default, got %v %v", e, err)
+	}
+	if _, err := newEnricher(testEnrichmentConfig(map[string]interface{}{"enabled": true, "ptr.ratelimit": "fast"}), nil); err == nil {
+		t.Errorf("Expected an error for an invalid rate limit")
+	}
+	if _, err := newEnricher(testEnrichmentConfig(map[string]interface{}{"enabled": true, "mmdbFiles": []string{"testdata/missing.mmdb"}}), nil); err == nil {
+		t.Errorf("Expected an error for a missing MMDB file")
+	}
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	// In-memory transport for nodes running in the server process
+	_ "nanomsg.org/go/mangos/v2/transport/inproc"
+)
+
+// localServerURL is where the server listens for nodes running in its own process
+const localServerURL = "inproc://nray-server"
+
+// startLocalNodes starts scanner nodes in the server process. They connect over
+// an in-memory transport and behave exactly like nodes started with "nray node".
+// The returned WaitGroup is done once all of them have said goodbye
+func startLocalNodes(count int) *sync.WaitGroup {
+	var nodes sync.WaitGroup
+	log.WithFields(log.Fields{
+		"module": "core.localNodes",
+		"src":    "startLocalNodes",
+	}).Infof("Starting %d local scanner nodes", count)
+	for i := 1; i <= count; i++ {
+		nodes.Add(1)
+		go func(args NodeCmdArgs) {
+			defer nodes.Done()
+			if err := RunNode(args); err != nil {
+				log.WithFields(log.Fields{
+					"module": "core.localNodes",
+					"src":    "startLocalNodes",
+				}).Errorf("Local node %s stopped: %v", args.NodeName, err)
+			}
+		}(NodeCmdArgs{
+			ServerURL:     localServerURL,
+			NodeName:      fmt.Sprintf("local-%d", i),
+			PreferredPool: -1,
+		})
+	}
+	return &nodes
+}
+
+// waitForLocalNodes blocks until all local nodes are shut down or the timeout is reached
+func waitForLocalNodes(nodes *sync.WaitGroup, timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		nodes.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.WithFields(log.Fields{
+			"module": "core.localNodes",
+			"src":    "waitForLocalNodes",
+		}).Warning("Local nodes did not shut down in time")
+	}
+}
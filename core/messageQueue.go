@@ -6,7 +6,6 @@ import (
 	"crypto/x509"
 	"fmt"
 	"io/ioutil"
-	"os"
 
 	"github.com/nray-scanner/nray/utils"
 	log "github.com/sirupsen/logrus"
@@ -40,31 +39,37 @@ func createRepSock(host string, ports []uint32, tlsconfig *tls.Config) mangos.So
 }
 
 // Checks server and port and connects to the server
-func initServerConnection(server, port string, socketconfig map[string]interface{}) mangos.Socket {
+func initServerConnection(server, port string, socketconfig map[string]interface{}) (mangos.Socket, error) {
 	if server == "" || port == "" {
-		log.WithFields(log.Fields{
-			"module": "core.messageQueue",
-			"src":    "initServerConnection",
-		}).Error("Please specify a server and the port of the upstream nray server")
-		os.Exit(1)
+		return nil, fmt.Errorf("Please specify a server and the port of the upstream nray server")
 	}
-	sock, err := req.NewSocket()
-	utils.CheckError(err, true)
-	sock.SetOption(mangos.OptionRecvDeadline, recvDeadline)
-	sock.SetOption(mangos.OptionSendDeadline, sendDeadline)
 	var serverAddress string
 	if socketconfig[mangos.OptionTLSConfig] != nil {
 		serverAddress = fmt.Sprintf("tls+tcp://%s:%s", server, port)
 	} else {
 		serverAddress = fmt.Sprintf("tcp://%s:%s", server, port)
 	}
+	return dialServer(serverAddress, socketconfig)
+}
+
+// dialServer connects to the server at the given mangos URL
+func dialServer(serverAddress string, socketconfig map[string]interface{}) (mangos.Socket, error) {
+	sock, err := req.NewSocket()
+	if err != nil {
+		return nil, err
+	}
+	sock.SetOption(mangos.OptionRecvDeadline, recvDeadline)
+	sock.SetOption(mangos.OptionSendDeadline, sendDeadline)
 	log.WithFields(log.Fields{
 		"module": "core.messageQueue",
-		"src":    "InitServerConnection",
+		"src":    "dialServer",
 	}).Infof("Connecting to: %s", serverAddress)
 	err = sock.DialOptions(serverAddress, socketconfig)
-	utils.CheckError(err, true)
-	return sock
+	if err != nil {
+		sock.Close()
+		return nil, err
+	}
+	return sock, nil
 }
 
 func setupMangosClientTLSConfig(useTLS bool, ignoreServerCertificate bool, serverCertPath string, clientCertPath string, clientKeyPath string, serverName string) (map[string]interface{}, error) {
@@ -87,7 +92,9 @@ func setupMangosClientTLSConfig(useTLS bool, ignoreServerCertificate bool, serve
 	// Pin server cert?
 	if serverCertPath != "" {
 		cert, err := ioutil.ReadFile(serverCertPath)
-		utils.CheckError(err, true)
+		if err != nil {
+			return nil, err
+		}
 		roots := x509.NewCertPool()
 		ok := roots.AppendCertsFromPEM(cert)
 		if !ok {
@@ -103,7 +110,9 @@ func setupMangosClientTLSConfig(useTLS bool, ignoreServerCertificate bool, serve
 	// Client key for mutual auth?
 	if clientCertPath != "" && clientKeyPath != "" {
 		cert, err := tls.LoadX509KeyPair(clientCertPath, clientKeyPath)
-		utils.CheckError(err, true)
+		if err != nil {
+			return nil, err
+		}
 		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
 
@@ -5,7 +5,6 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
-	"os"
 	"time"
 
 	targetgeneration "github.com/nray-scanner/nray/core/targetGeneration"
@@ -78,28 +77,27 @@ func handleNodeRegister(message *nraySchema.NodeRegister, considerClientPoolPref
 
 // HandleRegisteredNode extracts the assigned scanner ID
 // as well as the clock offset
-func HandleRegisteredNode(registeredNode *nraySchema.RegisteredNode) (string, time.Duration, *viper.Viper) {
+func HandleRegisteredNode(registeredNode *nraySchema.RegisteredNode) (string, time.Duration, *viper.Viper, error) {
 	nodeID := registeredNode.GetNodeID()
 	log.WithFields(log.Fields{
 		"module": "core.messageStuff",
 		"src":    "HandleRegisteredNode",
 	}).Infof("Got ID: %s", nodeID)
 	if nodeID == "" {
-		log.WithFields(log.Fields{
-			"module": "core.messageStuff",
-			"src":    "HandleRegisteredNode",
-		}).Error("Aborting, server refused to give an ID. Is there another instance running on this system?")
-		os.Exit(1)
+		return "", 0, nil, fmt.Errorf("Aborting, server refused to give an ID. Is there another instance running on this system?")
 	}
 	serverTime, err := ptypes.Timestamp(registeredNode.GetServerClock())
-	utils.CheckError(err, true)
+	if err != nil {
+		return "", 0, nil, err
+	}
 	timeOffset := serverTime.Sub(time.Now())
 	rawConfig := registeredNode.GetScannerconfig()
-	utils.CheckError(err, true)
 	scannerConfig := viper.New()
 	scannerConfig.SetConfigType("json")
-	scannerConfig.ReadConfig(bytes.NewBuffer(rawConfig))
-	return nodeID, timeOffset, scannerConfig
+	if err := scannerConfig.ReadConfig(bytes.NewBuffer(rawConfig)); err != nil {
+		return "", 0, nil, err
+	}
+	return nodeID, timeOffset, scannerConfig, nil
 }
 
 func handleHeartbeat(heartbeat *nraySchema.Heartbeat) *nraySchema.HeartbeatAck {
@@ -113,9 +111,11 @@ func handleHeartbeat(heartbeat *nraySchema.Heartbeat) *nraySchema.HeartbeatAck {
 			"module": "core.messageStuff",
 			"src":    "handleHeartbeat",
 		}).Debug("Received too old heartbeat, temporarily stopping scanner")
+		// A rate limit of 0 would lift the node's limit
 		return &nraySchema.HeartbeatAck{
-			Running:  true,
-			Scanning: false,
+			Running:   true,
+			Scanning:  false,
+			Ratelimit: CurrentConfig.nodeRatelimit(CurrentConfig.getPoolFromNodeID(id)),
 		}
 	}
 	pool := CurrentConfig.getPoolFromNodeID(id)
@@ -136,9 +136,11 @@ func handleHeartbeat(heartbeat *nraySchema.Heartbeat) *nraySchema.HeartbeatAck {
 		node.setStop(true)
 	}
 
+	ratelimit, waiting := CurrentConfig.assignRatelimit(id)
 	return &nraySchema.HeartbeatAck{
-		Running:  !node.getStop(),
-		Scanning: !node.scanPaused,
+		Running:   !node.getStop(),
+		Scanning:  !node.scanPaused && !waiting && CurrentConfig.Schedule.isOpen(time.Now()),
+		Ratelimit: ratelimit,
 	}
 }
 
@@ -147,10 +149,21 @@ func handleMoreWorkRequest(moreWork *nraySchema.MoreWorkRequest) string {
 }
 
 func createMoreWorkMsg(targets targetgeneration.AnyTargets, jobID uint64) []byte {
+	services := make([]*nraySchema.ServiceTarget, 0, len(targets.Services))
+	for _, service := range targets.Services {
+		services = append(services, &nraySchema.ServiceTarget{
+			Rhost:    service.Host,
+			Proto:    service.Protocol,
+			Port:     service.Port,
+			Hostname: service.Hostname,
+		})
+	}
 	t := &nraySchema.ScanTargets{
-		Rhosts:   targets.RemoteHosts,
-		Tcpports: targets.TCPPorts,
-		Udpports: targets.UDPPorts,
+		Rhosts:    targets.RemoteHosts,
+		Tcpports:  targets.TCPPorts,
+		Udpports:  targets.UDPPorts,
+		Services:  services,
+		Hostnames: targets.Hostnames,
 	}
 	moreWork := &nraySchema.MoreWorkReply{
 		Batchid: jobID,
@@ -177,14 +190,14 @@ func createUnregisteredMessage(nodeID string) *nraySchema.NrayServerMessage {
 }
 
 // makeHeartbeats sends an already serialized heartbeat every heartBeatTick to the specified channel
-func makeHeartbeats(dataChan chan<- *nraySchema.NrayNodeMessage, heartBeatTick time.Duration, timeOffset time.Duration) {
+func makeHeartbeats(dataChan chan<- *nraySchema.NrayNodeMessage, heartBeatTick time.Duration, session *nodeSession) {
 	ticker := time.NewTicker(heartBeatTick)
 	for range ticker.C {
 		// Add offset to have timestamps aligned to the server's clock
-		normalizedTime, err := ptypes.TimestampProto(time.Now().Add(timeOffset))
+		normalizedTime, err := ptypes.TimestampProto(time.Now().Add(session.offset()))
 		utils.CheckError(err, false)
 		heartbeat := nraySchema.Heartbeat{
-			NodeID:   nodeID,
+			NodeID:   session.id(),
 			BeatTime: normalizedTime,
 		}
 		msg := &nraySchema.NrayNodeMessage{
@@ -245,11 +258,14 @@ func HandleHeartbeatAck(heartbeatAck *nraySchema.HeartbeatAck) (bool, bool) {
 // Register a node at the server. The node generates a unique ID
 // that identifies the machine so the server can reject multiple
 // instances on the same machine
-func registerNode(sock mangos.Socket, nodeName string, preferredPool int32) (string, time.Duration, error) {
-	err := sock.Send(generateNodeRegister(nodeName, preferredPool))
-	utils.CheckError(err, true)
+func registerNode(sock mangos.Socket, nodeName string, preferredPool int32) (*nraySchema.RegisteredNode, error) {
+	if err := sock.Send(generateNodeRegister(nodeName, preferredPool)); err != nil {
+		return nil, err
+	}
 	msg, err := sock.Recv()
-	utils.CheckError(err, true)
+	if err != nil {
+		return nil, err
+	}
 	// Unpack it
 	skeleton := &nraySchema.NrayServerMessage{}
 	err = proto.Unmarshal(msg, skeleton)
@@ -258,11 +274,8 @@ func registerNode(sock mangos.Socket, nodeName string, preferredPool int32) (str
 	// Depending on the content of the message, do someting
 	switch skeleton.MessageContent.(type) {
 	case *nraySchema.NrayServerMessage_RegisteredNode:
-		nodeID, timeOffset, scannerConfig = HandleRegisteredNode(skeleton.GetRegisteredNode())
-		return nodeID, timeOffset, nil
-	case nil:
-		return "", 0, fmt.Errorf("Expected RegisteredNode message")
+		return skeleton.GetRegisteredNode(), nil
 	default:
-		return "", 0, fmt.Errorf("Expected RegisteredNode message")
+		return nil, fmt.Errorf("Expected RegisteredNode message")
 	}
 }
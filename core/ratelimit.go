@@ -0,0 +1,102 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// parseRatelimit parses a rate limit in probes per second. "none" or an
+// empty value mean unlimited, which is returned as 0
+func parseRatelimit(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "none" {
+		return 0, nil
+	}
+	limit, err := strconv.ParseFloat(value, 64)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("Invalid rate limit %s, expected a positive number or none", value)
+	}
+	return limit, nil
+}
+
+// activeNodeCount returns the number of nodes that are neither stopped nor paused
+func (p *Pool) activeNodeCount() int {
+	p.nodeLock.RLock()
+	defer p.nodeLock.RUnlock()
+	active := 0
+	for _, node := range p.nodes {
+		if !node.getStop() && !node.scanPaused {
+			active++
+		}
+	}
+	return active
+}
+
+// assignedRatelimit returns the sum of the shares active nodes of the pool other than nodeID hold
+func (p *Pool) assignedRatelimit(nodeID string) float64 {
+	p.nodeLock.RLock()
+	defer p.nodeLock.RUnlock()
+	assigned := 0.0
+	for id, node := range p.nodes {
+		if id != nodeID && !node.getStop() && !node.scanPaused {
+			share, _ := node.getRatelimit()
+			assigned += share
+		}
+	}
+	return assigned
+}
+
+// nodeRatelimit returns the share of the global rate limits a node of the pool
+// may use, in probes per second. The total budget is split evenly between all
+// active nodes, the budget of a pool between the active nodes of that pool.
+// The lower share applies, 0 means there is no global limit
+func (gc GlobalConfig) nodeRatelimit(pool *Pool) float64 {
+	share := math.Inf(1)
+	if gc.RatelimitTotal > 0 {
+		activeNodes := 0
+		for _, p := range gc.Pools {
+			activeNodes += p.activeNodeCount()
+		}
+		share = gc.RatelimitTotal / math.Max(float64(activeNodes), 1)
+	}
+	if gc.RatelimitPerPool > 0 && pool != nil {
+		share = math.Min(share, gc.RatelimitPerPool/math.Max(float64(pool.activeNodeCount()), 1))
+	}
+	if math.IsInf(share, 1) {
+		return 0
+	}
+	return share
+}
+
+// assignRatelimit returns the share of the global rate limits to send to a node
+// and if it has to wait for it. Other nodes only learn about their new shares
+// with their next heartbeat, so a node never gets more than what the others
+// still hold leaves of the limits. If nothing is left, the node is sent its
+// share but gets no work until a later heartbeat finds the share free
+func (gc GlobalConfig) assignRatelimit(nodeID string) (float64, bool) {
+	pool := gc.getPoolFromNodeID(nodeID)
+	node := gc.getNodeFromID(nodeID)
+	share := gc.nodeRatelimit(pool)
+	if share == 0 || node == nil {
+		return share, false
+	}
+	free := share
+	if gc.RatelimitTotal > 0 {
+		assigned := 0.0
+		for _, p := range gc.Pools {
+			assigned += p.assignedRatelimit(nodeID)
+		}
+		free = math.Min(free, gc.RatelimitTotal-assigned)
+	}
+	if gc.RatelimitPerPool > 0 && pool != nil {
+		free = math.Min(free, gc.RatelimitPerPool-pool.assignedRatelimit(nodeID))
+	}
+	if free <= 0 {
+		node.setRatelimit(0, true)
+		return share, true
+	}
+	node.setRatelimit(free, false)
+	return free, false
+}
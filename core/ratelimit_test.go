@@ -0,0 +1,102 @@
+package core
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParseRatelimit(t *testing.T) {
+	for value, expected := range map[string]float64{"none": 0, "": 0, "100": 100, "0.5": 0.5} {
+		if limit, err := parseRatelimit(value); err != nil || limit != expected {
+			t.Errorf("Parsing %q returned %f %v", value, limit, err)
+		}
+	}
+	for _, value := range []string{"-1", "0", "fast"} {
+		if _, err := parseRatelimit(value); err == nil {
+			t.Errorf("Parsing %q should fail", value)
+		}
+	}
+}
+
+func TestNodeRatelimit(t *testing.T) {
+	first := initPool(time.Hour)
+	second := initPool(time.Hour)
+	first.addNodeToPool("a", "a", "", time.Now())
+	first.addNodeToPool("b", "b", "", time.Now())
+	first.addNodeToPool("c", "c", "", time.Now())
+	second.addNodeToPool("d", "d", "", time.Now())
+	config := GlobalConfig{Pools: []*Pool{first, second}}
+
+	if limit := config.nodeRatelimit(first); limit != 0 {
+		t.Errorf("Expected no limit, got %f", limit)
+	}
+	config.RatelimitTotal = 1000
+	if limit := config.nodeRatelimit(first); limit != 250 {
+		t.Errorf("Expected an even split of the total limit, got %f", limit)
+	}
+	config.RatelimitPerPool = 300
+	if limit := config.nodeRatelimit(first); limit != 100 {
+		t.Errorf("Expected the pool's share, got %f", limit)
+	}
+	if limit := config.nodeRatelimit(second); limit != 250 {
+		t.Errorf("Expected the share of the total limit, got %f", limit)
+	}
+
+	// Stopped nodes don't count, so the others get more
+	first.StopNode("c")
+	second.StopAllNodes()
+	if limit := config.nodeRatelimit(first); limit != 150 {
+		t.Errorf("Expected the pool's share of two nodes, got %f", limit)
+	}
+	first.removeNodeFromPool("b", true)
+	if limit := config.nodeRatelimit(first); limit != 300 {
+		t.Errorf("Expected the pool's limit for a single node, got %f", limit)
+	}
+}
+
+func TestAssignRatelimit(t *testing.T) {
+	pool := initPool(time.Hour)
+	pool.addNodeToPool("a", "a", "", time.Now())
+	config := GlobalConfig{Pools: []*Pool{pool}, RatelimitTotal: 100}
+	if limit, waiting := config.assignRatelimit("a"); limit != 100 || waiting {
+		t.Errorf("Expected the whole limit for a single node, got %f %t", limit, waiting)
+	}
+
+	// a still holds the whole limit until its next heartbeat
+	pool.addNodeToPool("b", "b", "", time.Now())
+	if limit, waiting := config.assignRatelimit("b"); limit != 50 || !waiting {
+		t.Errorf("Expected b to wait for its share, got %f %t", limit, waiting)
+	}
+	if limit, waiting := config.assignRatelimit("a"); limit != 50 || waiting {
+		t.Errorf("Expected a to be lowered to its share, got %f %t", limit, waiting)
+	}
+	if limit, waiting := config.assignRatelimit("b"); limit != 50 || waiting {
+		t.Errorf("Expected b to get the share a released, got %f %t", limit, waiting)
+	}
+
+	// A third node only gets what is left while the others are lowered
+	pool.addNodeToPool("c", "c", "", time.Now())
+	config.assignRatelimit("c")
+	config.assignRatelimit("a")
+	if limit, waiting := config.assignRatelimit("c"); math.Abs(limit-100.0/6) > 0.001 || waiting {
+		t.Errorf("Expected c to get what b doesn't hold yet, got %f %t", limit, waiting)
+	}
+	node, _ := pool.getNodeFromID("b")
+	if held, _ := node.getRatelimit(); held != 50 {
+		t.Errorf("b must hold its old share until its heartbeat, got %f", held)
+	}
+	config.assignRatelimit("b")
+	if limit, _ := config.assignRatelimit("c"); math.Abs(limit-100.0/3) > 0.001 {
+		t.Errorf("Expected c to get a third, got %f", limit)
+	}
+	total := 0.0
+	for _, id := range []string{"a", "b", "c"} {
+		node, _ := pool.getNodeFromID(id)
+		held, _ := node.getRatelimit()
+		total += held
+	}
+	if total > 100.001 {
+		t.Errorf("Shares must never exceed the total limit, got %f", total)
+	}
+}
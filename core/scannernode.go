@@ -3,6 +3,7 @@ package core
 import (
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/golang/protobuf/proto"
@@ -18,15 +19,56 @@ import (
 	"github.com/shirou/gopsutil/process"
 	log "github.com/sirupsen/logrus"
 
+	mangos "nanomsg.org/go/mangos/v2"
 	// TCP transport for nanomsg
 	_ "nanomsg.org/go/mangos/v2/transport/tcp"
 	_ "nanomsg.org/go/mangos/v2/transport/tlstcp"
 )
 
-// TODO: Get rid of globals / unify them to a struct
-var nodeID string
-var timeOffset time.Duration
-var scannerConfig *viper.Viper
+// nodeSession holds what a node is assigned by the server when registering.
+// Each node has its own, so multiple nodes may run in the same process
+type nodeSession struct {
+	lock          sync.RWMutex
+	nodeID        string
+	timeOffset    time.Duration
+	scannerConfig *viper.Viper
+}
+
+func (session *nodeSession) set(nodeID string, timeOffset time.Duration, scannerConfig *viper.Viper) {
+	session.lock.Lock()
+	defer session.lock.Unlock()
+	session.nodeID = nodeID
+	session.timeOffset = timeOffset
+	session.scannerConfig = scannerConfig
+}
+
+// register stores what the server assigned to the node when registering
+func (session *nodeSession) register(registeredNode *nraySchema.RegisteredNode) error {
+	nodeID, timeOffset, scannerConfig, err := HandleRegisteredNode(registeredNode)
+	if err != nil {
+		return err
+	}
+	session.set(nodeID, timeOffset, scannerConfig)
+	return nil
+}
+
+func (session *nodeSession) id() string {
+	session.lock.RLock()
+	defer session.lock.RUnlock()
+	return session.nodeID
+}
+
+func (session *nodeSession) offset() time.Duration {
+	session.lock.RLock()
+	defer session.lock.RUnlock()
+	return session.timeOffset
+}
+
+func (session *nodeSession) config() *viper.Viper {
+	session.lock.RLock()
+	defer session.lock.RUnlock()
+	return session.scannerConfig
+}
 
 // These variables are currently hardcoded and should be configurable in the future
 const sendDeadline = 30 * time.Second
@@ -46,10 +88,23 @@ type NodeCmdArgs struct {
 	TLSClientKeyPath           string
 	TLSClientCertPath          string
 	TLSServerSAN               string
+	ScopeAllow                 []string
+	ScopeAllowFile             string
+	ScopeDeny                  []string
+	ScopeDenyFile              string
+	ScopeStrict                bool
+	// Override the source settings of the server's scanner configuration
+	SourceIP        string
+	SourceInterface string
+	SourcePorts     string
+	// ServerURL is used instead of Server and Port if set, e.g. by nodes
+	// running in the server process
+	ServerURL string
 }
 
-// RunNode is called by the main function of the node binary and gets everything up and running
-func RunNode(args NodeCmdArgs) {
+// RunNode is called by the main function of the node binary and gets everything up and running.
+// Errors are returned instead of exiting, so nodes running in the server process can't stop the server
+func RunNode(args NodeCmdArgs) error {
 	if args.Debug {
 		log.SetLevel(log.DebugLevel)
 		log.SetFormatter(&utils.Formatter{})
@@ -62,24 +117,47 @@ func RunNode(args NodeCmdArgs) {
 			"src":    "RunNode",
 		}).Debugf("Truncating name to %s", args.NodeName)
 	}
-	if args.Server == "" {
+	if args.Server == "" && args.ServerURL == "" {
 		log.Printf("Server is not specified, using localhost")
 		args.Server = "localhost"
 	}
-	if args.Port == "" {
+	if args.Port == "" && args.ServerURL == "" {
 		log.Printf("Port is not specified, using 8601")
 		args.Port = "8601"
 	}
 
+	scope, err := createNodeScope(args)
+	if err != nil {
+		return err
+	}
+
 	var socketConfig map[string]interface{}
-	socketConfig, err := setupMangosClientTLSConfig(args.UseTLS, args.TLSIgnoreServerCertificate, args.TLSCACertPath,
+	socketConfig, err = setupMangosClientTLSConfig(args.UseTLS, args.TLSIgnoreServerCertificate, args.TLSCACertPath,
 		args.TLSClientCertPath, args.TLSClientKeyPath, args.TLSServerSAN)
-	utils.CheckError(err, true)
-	sock := initServerConnection(args.Server, args.Port, socketConfig) // establish network connection to server
+	if err != nil {
+		return err
+	}
+	var sock mangos.Socket
+	if args.ServerURL != "" {
+		// Local nodes may be started before the server listens
+		socketConfig[mangos.OptionDialAsynch] = true
+		sock, err = dialServer(args.ServerURL, socketConfig)
+	} else {
+		sock, err = initServerConnection(args.Server, args.Port, socketConfig) // establish network connection to server
+	}
+	if err != nil {
+		return err
+	}
 	defer sock.Close()
 
-	nodeID, timeOffset, err = registerNode(sock, args.NodeName, args.PreferredPool) // makes node known to server and sets nodeID and timeOffset
-	utils.CheckError(err, true)
+	registeredNode, err := registerNode(sock, args.NodeName, args.PreferredPool) // makes node known to server
+	if err != nil {
+		return err
+	}
+	session := &nodeSession{}
+	if err := session.register(registeredNode); err != nil {
+		return err
+	}
 
 	// Everything sent to this channel will be sent to the server
 	dataChan := make(chan *nraySchema.NrayNodeMessage, 10)
@@ -87,13 +165,20 @@ func RunNode(args NodeCmdArgs) {
 		"module": "core.scannernode",
 		"src":    "RunNode",
 	}).Debugf("Node name is set to %s", args.NodeName)
-	scanController := scanner.CreateScanController(nodeID, args.NodeName, timeOffset, scannerConfig)
+	applySourceOverrides(args, session.config())
+	scanController, err := scanner.CreateScanController(session.id(), args.NodeName, session.offset(), session.config())
+	if err != nil {
+		return err
+	}
+	defer scanController.Close()
+	scanController.SetScope(scope)
+	scanController.SetServerRatelimit(registeredNode.GetRatelimit())
 
 	// JobBatches are sent here
 	workBatchChan := make(chan *nraySchema.MoreWorkReply)
 
 	// makeHeartbeats runs asynchronously in its own goroutine and sends regular heartbeats
-	go makeHeartbeats(dataChan, heartBeatTick, timeOffset)
+	go makeHeartbeats(dataChan, heartBeatTick, session)
 
 	// here does the actual scanning work happen
 	go scanner.RunNodeScannerLoop(scanController, workBatchChan, dataChan)
@@ -126,7 +211,10 @@ mainloop:
 				"module": "core.scannernode",
 				"src":    "RunNode",
 			}).Debug("Register message")
-			nodeID, timeOffset, scannerConfig = HandleRegisteredNode(skeleton.GetRegisteredNode())
+			if err := session.register(skeleton.GetRegisteredNode()); err != nil {
+				return err
+			}
+			scanController.SetServerRatelimit(skeleton.GetRegisteredNode().GetRatelimit())
 		case *nraySchema.NrayServerMessage_HeartbeatAck:
 			log.WithFields(log.Fields{
 				"module": "core.scannernode",
@@ -134,11 +222,12 @@ mainloop:
 			}).Debug("Heartbeat ACK")
 			scanning, running := HandleHeartbeatAck(skeleton.GetHeartbeatAck())
 			scanController.Pause.SetValue(!scanning || !running)
+			scanController.SetServerRatelimit(skeleton.GetHeartbeatAck().GetRatelimit())
 			if !running {
 				message := &nraySchema.NrayNodeMessage{
 					MessageContent: &nraySchema.NrayNodeMessage_Goodbye{
 						Goodbye: &nraySchema.Goodbye{
-							NodeID: nodeID,
+							NodeID: session.id(),
 						},
 					},
 				}
@@ -149,7 +238,7 @@ mainloop:
 			log.WithFields(log.Fields{
 				"module": "core.scannernode",
 				"src":    "RunNode",
-			}).Debugf("Job Batch with ID %d. It contains %d targets, %d tcp and %d udp ports", b.Batchid, len(b.GetTargets().GetRhosts()), len(b.GetTargets().GetTcpports()), len(b.GetTargets().GetUdpports()))
+			}).Debugf("Job Batch with ID %d. It contains %d targets, %d tcp and %d udp ports and %d single services", b.Batchid, len(b.GetTargets().GetRhosts()), len(b.GetTargets().GetTcpports()), len(b.GetTargets().GetUdpports()), len(b.GetTargets().GetServices()))
 			workBatchChan <- skeleton.GetJobBatch()
 		case *nraySchema.NrayServerMessage_WorkDoneAck:
 			log.WithFields(log.Fields{
@@ -166,8 +255,14 @@ mainloop:
 				break mainloop
 			}
 		case *nraySchema.NrayServerMessage_NodeIsUnregistered:
-			nodeID, timeOffset, err = registerNode(sock, args.NodeName, args.PreferredPool)
-			utils.CheckError(err, true)
+			registeredNode, err = registerNode(sock, args.NodeName, args.PreferredPool)
+			if err != nil {
+				return err
+			}
+			if err := session.register(registeredNode); err != nil {
+				return err
+			}
+			scanController.SetServerRatelimit(registeredNode.GetRatelimit())
 			if _, ok := nextNodeMessage.MessageContent.(*nraySchema.NrayNodeMessage_Heartbeat); ok {
 				dataChan <- nextNodeMessage // retransmit the last message unless it was a heartbeat
 			}
@@ -183,6 +278,54 @@ mainloop:
 			}).Error("Cannot decode message sent by server")
 		}
 	}
+	return nil
+}
+
+// applySourceOverrides replaces the source settings the server sent with those
+// given on the command line. Nodes know best which of their addresses are allowed
+func applySourceOverrides(args NodeCmdArgs, scannerConfig *viper.Viper) {
+	overrides := map[string]string{
+		"source.ip":        args.SourceIP,
+		"source.interface": args.SourceInterface,
+		"source.ports":     args.SourcePorts,
+	}
+	for key, value := range overrides {
+		if value != "" {
+			scannerConfig.Set(key, value)
+		}
+	}
+}
+
+// createNodeScope merges the allow and deny entries given on the command line
+// with the ones read from files
+func createNodeScope(args NodeCmdArgs) (*scanner.NodeScope, error) {
+	allow := args.ScopeAllow
+	deny := args.ScopeDeny
+	if args.ScopeAllowFile != "" {
+		entries, err := scanner.LoadScopeFile(args.ScopeAllowFile)
+		if err != nil {
+			return nil, err
+		}
+		allow = append(allow, entries...)
+	}
+	if args.ScopeDenyFile != "" {
+		entries, err := scanner.LoadScopeFile(args.ScopeDenyFile)
+		if err != nil {
+			return nil, err
+		}
+		deny = append(deny, entries...)
+	}
+	scope, err := scanner.NewNodeScope(allow, deny, args.ScopeStrict)
+	if err != nil {
+		return nil, err
+	}
+	if scope.Enabled() {
+		log.WithFields(log.Fields{
+			"module": "core.scannernode",
+			"src":    "createNodeScope",
+		}).Infof("Enforcing node scope: %d allow and %d deny entries, strict mode: %t", len(allow), len(deny), args.ScopeStrict)
+	}
+	return scope, nil
 }
 
 func gatherEnvironmentInformation() *nraySchema.EnvironmentInformation {
@@ -0,0 +1,186 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// scanWindow is a time range on some weekdays. Ranges ending before they
+// start, like 22:00-06:00, end on the following day
+type scanWindow struct {
+	days  [7]bool
+	start int // minutes after midnight
+	end   int // minutes after midnight of the day the window starts
+}
+
+// scanSchedule restricts scanning to a list of windows. A nil schedule allows scanning at any time
+type scanSchedule struct {
+	windows  []scanWindow
+	location *time.Location
+}
+
+// parseSchedule parses windows like "Mon-Fri 18:00-07:00", "Sat,Sun 00:00-24:00"
+// or "22:00-06:00" (every day) in the given timezone.
+// Without windows, nil is returned
+func parseSchedule(windows []string, timezone string) (*scanSchedule, error) {
+	if len(windows) == 0 {
+		return nil, nil
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %s: %v", timezone, err)
+	}
+	schedule := &scanSchedule{location: location}
+	for _, window := range windows {
+		parsed, err := parseScanWindow(window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scan window %s: %v", window, err)
+		}
+		schedule.windows = append(schedule.windows, parsed)
+	}
+	return schedule, nil
+}
+
+func parseScanWindow(window string) (scanWindow, error) {
+	var parsed scanWindow
+	fields := strings.Fields(window)
+	var days, times string
+	switch len(fields) {
+	case 1:
+		days, times = "*", fields[0]
+	case 2:
+		days, times = fields[0], fields[1]
+	default:
+		return parsed, fmt.Errorf("expected [days] start-end")
+	}
+
+	if days == "*" {
+		for day := range parsed.days {
+			parsed.days[day] = true
+		}
+	} else {
+		for _, dayRange := range strings.Split(days, ",") {
+			bounds := strings.SplitN(dayRange, "-", 2)
+			first, ok := weekdays[strings.ToLower(bounds[0])]
+			if !ok {
+				return parsed, fmt.Errorf("unknown weekday %s", bounds[0])
+			}
+			last := first
+			if len(bounds) == 2 {
+				if last, ok = weekdays[strings.ToLower(bounds[1])]; !ok {
+					return parsed, fmt.Errorf("unknown weekday %s", bounds[1])
+				}
+			}
+			for day := first; ; day = (day + 1) % 7 {
+				parsed.days[day] = true
+				if day == last {
+					break
+				}
+			}
+		}
+	}
+
+	bounds := strings.SplitN(times, "-", 2)
+	if len(bounds) != 2 {
+		return parsed, fmt.Errorf("expected a time range like 18:00-07:00")
+	}
+	var err error
+	if parsed.start, err = parseTimeOfDay(bounds[0]); err != nil {
+		return parsed, err
+	}
+	if parsed.end, err = parseTimeOfDay(bounds[1]); err != nil {
+		return parsed, err
+	}
+	if parsed.start == 24*60 {
+		return parsed, fmt.Errorf("window must not start at 24:00")
+	}
+	if parsed.end <= parsed.start {
+		parsed.end += 24 * 60
+	}
+	return parsed, nil
+}
+
+// parseTimeOfDay returns the minutes after midnight of a time like 18:30. 24:00 is allowed
+func parseTimeOfDay(value string) (int, error) {
+	if value == "24:00" {
+		return 24 * 60, nil
+	}
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %s", value)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// nextWindow returns start and end of the window that is open at t or,
+// if none is, of the one that opens next. Overlapping windows are merged
+func (s *scanSchedule) nextWindow(t time.Time) (time.Time, time.Time, bool) {
+	t = t.In(s.location)
+	type occurrence struct{ start, end time.Time }
+	occurrences := make([]occurrence, 0)
+	// Windows starting yesterday may still be open
+	for offset := -1; offset <= 8; offset++ {
+		day := time.Date(t.Year(), t.Month(), t.Day()+offset, 0, 0, 0, 0, s.location)
+		for _, window := range s.windows {
+			if !window.days[day.Weekday()] {
+				continue
+			}
+			windowEnd := time.Date(day.Year(), day.Month(), day.Day(), 0, window.end, 0, 0, s.location)
+			if windowEnd.After(t) {
+				windowStart := time.Date(day.Year(), day.Month(), day.Day(), 0, window.start, 0, 0, s.location)
+				occurrences = append(occurrences, occurrence{windowStart, windowEnd})
+			}
+		}
+	}
+	if len(occurrences) == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].start.Before(occurrences[j].start) })
+	start, end := occurrences[0].start, occurrences[0].end
+	for _, next := range occurrences[1:] {
+		if next.start.After(end) {
+			break
+		}
+		if next.end.After(end) {
+			end = next.end
+		}
+	}
+	return start, end, true
+}
+
+// isOpen returns if scanning is allowed at t
+func (s *scanSchedule) isOpen(t time.Time) bool {
+	if s == nil {
+		return true
+	}
+	start, _, found := s.nextWindow(t)
+	return found && !start.After(t)
+}
+
+// String describes the state of the schedule at the current time
+func (s *scanSchedule) String() string {
+	if s == nil {
+		return "no scan windows configured"
+	}
+	now := time.Now()
+	start, end, found := s.nextWindow(now)
+	if !found {
+		return "no scan window on any day"
+	}
+	if !start.After(now) {
+		return fmt.Sprintf("scan window open until %s", end.Format("Mon 2006-01-02 15:04 MST"))
+	}
+	return fmt.Sprintf("outside scan window, next window %s until %s", start.Format("Mon 2006-01-02 15:04 MST"), end.Format("Mon 2006-01-02 15:04 MST"))
+}
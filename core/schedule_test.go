@@ -0,0 +1,53 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScanSchedule(t *testing.T) {
+	schedule, err := parseSchedule([]string{"Mon-Fri 18:00-07:00", "Sat,Sun 00:00-24:00"}, "Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	berlin := schedule.location
+	tests := []struct {
+		time time.Time
+		open bool
+	}{
+		{time.Date(2026, 10, 19, 12, 0, 0, 0, berlin), false}, // Monday noon
+		{time.Date(2026, 10, 19, 18, 0, 0, 0, berlin), true},
+		{time.Date(2026, 10, 20, 6, 59, 0, 0, berlin), true}, // Tuesday morning, window started on Monday
+		{time.Date(2026, 10, 20, 7, 0, 0, 0, berlin), false},
+		{time.Date(2026, 10, 24, 12, 0, 0, 0, berlin), true}, // Saturday
+		{time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC), false},
+		{time.Date(2026, 10, 19, 17, 0, 0, 0, time.UTC), true}, // 19:00 in Berlin
+	}
+	for _, test := range tests {
+		if open := schedule.isOpen(test.time); open != test.open {
+			t.Errorf("Expected open=%t at %s", test.open, test.time)
+		}
+	}
+
+	// Friday night until the end of Sunday is one merged window
+	start, end, found := schedule.nextWindow(time.Date(2026, 10, 23, 12, 0, 0, 0, berlin))
+	if !found || !start.Equal(time.Date(2026, 10, 23, 18, 0, 0, 0, berlin)) || !end.Equal(time.Date(2026, 10, 26, 0, 0, 0, 0, berlin)) {
+		t.Errorf("Wrong next window: %s until %s", start, end)
+	}
+
+	var unrestricted *scanSchedule
+	if !unrestricted.isOpen(time.Now()) {
+		t.Errorf("No schedule must allow scanning at any time")
+	}
+	if schedule, err := parseSchedule(nil, "Local"); schedule != nil || err != nil {
+		t.Errorf("Expected no schedule without windows, got %v %v", schedule, err)
+	}
+	for _, window := range []string{"Mon-Fri", "Foo 10:00-12:00", "25:00-26:00", "10:00", "Mon 10:00-11:00 extra", "10:00x-11:00", "10:60-11:00", "24:01-01:00"} {
+		if _, err := parseSchedule([]string{window}, "UTC"); err == nil {
+			t.Errorf("Parsing window %q should fail", window)
+		}
+	}
+	if _, err := parseSchedule([]string{"10:00-12:00"}, "Mars/Olympus"); err == nil {
+		t.Errorf("Invalid timezone should fail")
+	}
+}
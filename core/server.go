@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/nray-scanner/nray/events"
@@ -18,6 +19,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	targetgeneration "github.com/nray-scanner/nray/core/targetGeneration"
+	"github.com/nray-scanner/nray/scanner"
 	nraySchema "github.com/nray-scanner/nray/schemas"
 	"github.com/nray-scanner/nray/utils"
 	"github.com/spf13/viper"
@@ -45,9 +47,22 @@ func InitGlobalServerConfig(config *viper.Viper) error {
 		})
 	}
 
+	// Local nodes share the machine ID, so they can only register if multiple nodes per host are allowed
+	localNodes := externalConfig.GetInt("localNodes")
+	if localNodes < 0 {
+		return fmt.Errorf("The number of local nodes must not be negative")
+	}
+	if localNodes > 0 && !externalConfig.GetBool("allowMultipleNodesPerHost") {
+		log.WithFields(log.Fields{
+			"module": "core.server",
+			"src":    "InitGlobalServerConfig",
+		}).Info("Local nodes are enabled, allowing multiple nodes per host")
+		externalConfig.Set("allowMultipleNodesPerHost", true)
+	}
+
 	// Init ports
 	portsToListen := externalConfig.GetStringSlice("listen")
-	if len(portsToListen) == 0 || portsToListen == nil {
+	if len(portsToListen) == 0 && localNodes == 0 {
 		return fmt.Errorf("No port to bind to was given")
 	}
 	portList := make([]uint32, 0)
@@ -59,7 +74,7 @@ func InitGlobalServerConfig(config *viper.Viper) error {
 
 	// Init host configuration
 	host := externalConfig.GetString("host")
-	CurrentConfig = GlobalConfig{ListenPorts: portList, ListenHost: host}
+	CurrentConfig = GlobalConfig{ListenPorts: portList, ListenHost: host, LocalNodes: localNodes}
 
 	// Init TLS
 	if externalConfig.GetBool("TLS.enabled") {
@@ -78,6 +93,43 @@ func InitGlobalServerConfig(config *viper.Viper) error {
 		}
 	}
 
+	// Init global rate limits
+	var err error
+	if CurrentConfig.RatelimitTotal, err = parseRatelimit(externalConfig.GetString("ratelimit.total")); err != nil {
+		return err
+	}
+	if CurrentConfig.RatelimitPerPool, err = parseRatelimit(externalConfig.GetString("ratelimit.perPool")); err != nil {
+		return err
+	}
+
+	// Init scan windows
+	if CurrentConfig.Schedule, err = parseSchedule(externalConfig.GetStringSlice("schedule.windows"), externalConfig.GetString("schedule.timezone")); err != nil {
+		return err
+	}
+	if CurrentConfig.Schedule != nil {
+		log.WithFields(log.Fields{
+			"module": "core.server",
+			"src":    "InitGlobalServerConfig",
+		}).Infof("Scanning is restricted to %d scan windows, %s", len(CurrentConfig.Schedule.windows), CurrentConfig.Schedule)
+	}
+
+	// Load the UDP and service probe libraries, they are sent to nodes as part of the scanner configuration
+	if externalConfig.IsSet("scannerconfig") {
+		scannerConfig := externalConfig.Sub("scannerconfig")
+		if err := scanner.LoadUDPProbeFile(scannerConfig); err != nil {
+			return err
+		}
+		if err := scanner.LoadServiceProbeFile(scannerConfig); err != nil {
+			return err
+		}
+		externalConfig.Set("scannerconfig", scannerConfig.AllSettings())
+	}
+
+	// Invalid ports would only be noticed once the pools are set up
+	if err := targetgeneration.ValidatePortConfig(externalConfig.Sub("targetgenerator")); err != nil {
+		return err
+	}
+
 	// Init pool configuration
 	CurrentConfig.Pools = make([]*Pool, externalConfig.GetInt("pools"))
 
@@ -92,12 +144,25 @@ func InitGlobalServerConfig(config *viper.Viper) error {
 			CurrentConfig.EventHandlers = append(CurrentConfig.EventHandlers, handler)
 		}
 	}
+
+	// Init result enrichment, it passes events on to the event handlers
+	if CurrentConfig.Enricher, err = newEnricher(externalConfig.Sub("enrichment"), CurrentConfig.LogEvents); err != nil {
+		return err
+	}
 	return nil
 }
 
-// Start starts the core
+// Start starts the core. If local nodes are configured, they are started
+// as well and Start returns after they have shut down
 func Start() {
+	var localNodes *sync.WaitGroup
+	if CurrentConfig.LocalNodes > 0 {
+		localNodes = startLocalNodes(CurrentConfig.LocalNodes)
+	}
 	server(CurrentConfig)
+	if localNodes != nil {
+		waitForLocalNodes(localNodes, sendDeadline)
+	}
 }
 
 // Here does (most of) the core magic happen. It's long, but don't get afraid
@@ -108,6 +173,9 @@ func server(currentConfig GlobalConfig) {
 	// Initialise Message Queue and bind to TCP ports
 	sock := createRepSock(currentConfig.ListenHost, currentConfig.ListenPorts, currentConfig.TLSConfig)
 	defer sock.Close()
+	if currentConfig.LocalNodes > 0 {
+		utils.CheckError(sock.Listen(localServerURL), true)
+	}
 
 	// Handle Ctrl+C events
 	startSignalInterruptHandler()
@@ -126,6 +194,9 @@ mainloop:
 		switch skeleton.MessageContent.(type) {
 		case *nraySchema.NrayNodeMessage_NodeRegister:
 			registeredNode := handleNodeRegister(skeleton.GetNodeRegister(), externalConfig.GetBool("considerClientPoolPreference"), externalConfig.GetBool("allowMultipleNodesPerHost"))
+			if registeredNode.NodeID != "" {
+				registeredNode.Ratelimit, _ = currentConfig.assignRatelimit(registeredNode.NodeID)
+			}
 			for _, handler := range currentConfig.EventHandlers {
 				handler.ProcessEvents([]*nraySchema.Event{skeleton.GetNodeRegister().Envinfo})
 			}
@@ -167,7 +238,11 @@ mainloop:
 						"src":    "server",
 					}).Debugf("Request for more work by node %s", node.Name)
 
-					newJob := pool.GetJobForNode(nodeID)
+					var newJob *Job
+					// Nodes waiting for their share of the rate limits get no work yet
+					if _, waiting := node.getRatelimit(); !waiting && currentConfig.Schedule.isOpen(time.Now()) {
+						newJob = pool.GetJobForNode(nodeID)
+					}
 					if newJob == nil {
 						// Currently no jobs available :(
 						marshalled = createMoreWorkMsg(targetgeneration.AnyTargets{}, 0)
@@ -182,7 +257,7 @@ mainloop:
 			if alreadyRegistered := checkNodeIDIsRegistered(skeleton.GetWorkDone().NodeID); !alreadyRegistered {
 				SendMessage(sock, createUnregisteredMessage(skeleton.GetWorkDone().NodeID))
 			} else {
-				currentConfig.LogEvents(skeleton.GetWorkDone().Events)
+				currentConfig.enrichAndLogEvents(skeleton.GetWorkDone().Events)
 				nodeID := skeleton.GetWorkDone().NodeID
 				poolOfNode := currentConfig.getPoolFromNodeID(nodeID)
 				err := poolOfNode.removeJobFromJobArea(nodeID, skeleton.GetWorkDone().Batchid)
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/nray-scanner/nray/utils"
 	"github.com/spf13/viper"
@@ -35,3 +36,56 @@ func globalConfigPositive(t *testing.T) {
 		}
 	}
 }
+
+func TestLocalNodes(t *testing.T) {
+	testdata := []byte(`
+listen: []
+localNodes: 2
+targetgenerator:
+  standard:
+    enabled: true
+    targets: ["127.0.0.1", "127.0.0.2", "127.0.0.3"]
+    tcpports: ["1", "2"]
+    udpports: []
+    maxHostsPerBatch: 1
+scannerconfig:
+  tcp:
+    timeout: 100ms
+`)
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.ReadConfig(bytes.NewBuffer(testdata))
+	v = utils.ApplyDefaultConfig(v)
+	if err := InitGlobalServerConfig(v); err != nil {
+		t.Fatal(err)
+	}
+	if !v.GetBool("allowMultipleNodesPerHost") {
+		t.Errorf("Local nodes require multiple nodes per host")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		Start()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(60 * time.Second):
+		t.Fatal("Server with local nodes did not shut down")
+	}
+	if CurrentConfig.Pools[0].CountWorkDone != 6 {
+		t.Errorf("Expected 6 targets to be scanned, got %d", CurrentConfig.Pools[0].CountWorkDone)
+	}
+}
+
+func TestRunNodeReturnsErrors(t *testing.T) {
+	err := RunNode(NodeCmdArgs{
+		Server:        "localhost",
+		Port:          "8601",
+		UseTLS:        true,
+		TLSCACertPath: "/nonexistent/ca.pem",
+	})
+	if err == nil {
+		t.Errorf("Missing CA certificate was not reported")
+	}
+}
@@ -1,12 +1,16 @@
 package targetgeneration
 
 import (
+	"bufio"
 	"fmt"
 	"net"
+	"os"
+	"strings"
 
 	"github.com/apparentlymart/go-cidr/cidr"
 
 	"github.com/nray-scanner/nray/utils"
+	"github.com/spf13/viper"
 	"github.com/zmap/go-iptree/blacklist"
 )
 
@@ -15,6 +19,7 @@ type NrayBlacklist struct {
 	ipBlacklist  *blacklist.Blacklist
 	dnsBlacklist *map[string]bool // value type not relevant, taking bool..
 	addressCount uint64
+	entries      []string
 }
 
 // NewBlacklist returns a new blacklist
@@ -26,6 +31,40 @@ func NewBlacklist() *NrayBlacklist {
 	}
 }
 
+// blacklistFromConfig creates a blacklist from the "blacklist" and
+// "blacklistFile" values of a target generator backend configuration
+func blacklistFromConfig(conf *viper.Viper) *NrayBlacklist {
+	nrayBlacklist := NewBlacklist()
+	for _, blacklistItem := range conf.GetStringSlice("blacklist") {
+		// The default blacklist is [""], which must not count as a DNS name
+		if strings.TrimSpace(blacklistItem) != "" {
+			_ = nrayBlacklist.AddToBlacklist(blacklistItem)
+		}
+	}
+	if conf.IsSet("blacklistFile") && strings.Trim(conf.GetString("blacklistFile"), " ") != "" {
+		utils.CheckError(nrayBlacklist.AddFileToBlacklist(conf.GetString("blacklistFile")), false)
+	}
+	return nrayBlacklist
+}
+
+// AddFileToBlacklist adds every non-empty line of a file to the blacklist
+func (blacklist *NrayBlacklist) AddFileToBlacklist(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" {
+			_ = blacklist.AddToBlacklist(line)
+		}
+	}
+	return scanner.Err()
+}
+
 // AddToBlacklist can be used if the type of the element
 // is unclear
 func (blacklist *NrayBlacklist) AddToBlacklist(element string) uint64 {
@@ -54,12 +93,14 @@ func (blacklist *NrayBlacklist) AddNetToBlacklist(network string) {
 	utils.CheckError(err, false)
 	blacklist.addressCount += cidr.AddressCount(parsedNet)
 	blacklist.ipBlacklist.AddEntry(network)
+	blacklist.entries = append(blacklist.entries, network)
 }
 
 // AddDNSNameToBlacklist adds a FQDN to the blacklist
 func (blacklist *NrayBlacklist) AddDNSNameToBlacklist(dnsName string) {
 	if !(*blacklist.dnsBlacklist)[dnsName] {
 		blacklist.addressCount++
+		blacklist.entries = append(blacklist.entries, dnsName)
 	}
 	(*blacklist.dnsBlacklist)[dnsName] = true
 }
@@ -78,3 +119,61 @@ func (blacklist *NrayBlacklist) IsDNSNameBlacklisted(dnsName string) bool {
 	_, blacklisted := (*blacklist.dnsBlacklist)[dnsName]
 	return blacklisted
 }
+
+// overlaps returns all blacklist entries that overlap with a target.
+// Networks overlap if one of them contains the other
+func (blacklist *NrayBlacklist) overlaps(target string) []string {
+	overlapping := make([]string, 0)
+	if utils.Ipv4NetRegexpr.MatchString(target) || utils.Ipv4Regexpr.MatchString(target) {
+		if utils.Ipv4Regexpr.MatchString(target) {
+			target = fmt.Sprintf("%s/32", target)
+		}
+		_, targetNet, err := net.ParseCIDR(target)
+		if err != nil {
+			return overlapping
+		}
+		for _, entry := range blacklist.entries {
+			_, entryNet, err := net.ParseCIDR(entry)
+			if err != nil {
+				continue
+			}
+			if targetNet.Contains(entryNet.IP) || entryNet.Contains(targetNet.IP) {
+				overlapping = append(overlapping, entry)
+			}
+		}
+	} else if blacklist.IsDNSNameBlacklisted(target) {
+		overlapping = append(overlapping, target)
+	}
+	return overlapping
+}
+
+// blacklistedAddressCount returns how many addresses of a network are blacklisted.
+// Entries nested in other entries are only counted once
+func (blacklist *NrayBlacklist) blacklistedAddressCount(ipnet *net.IPNet) uint64 {
+	size := cidr.AddressCount(ipnet)
+	overlapping := make([]*net.IPNet, 0)
+	for _, entry := range blacklist.overlaps(ipnet.String()) {
+		_, entryNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			continue
+		}
+		if entryNet.Contains(ipnet.IP) && cidr.AddressCount(entryNet) >= size {
+			return size
+		}
+		overlapping = append(overlapping, entryNet)
+	}
+	var count uint64
+	for i, entryNet := range overlapping {
+		nested := false
+		for j, other := range overlapping {
+			if i != j && other.Contains(entryNet.IP) && (cidr.AddressCount(other) > cidr.AddressCount(entryNet) || j < i) {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			count += cidr.AddressCount(entryNet)
+		}
+	}
+	return count
+}
@@ -0,0 +1,84 @@
+package targetgeneration
+
+import (
+	"github.com/nray-scanner/nray/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// hostTarget is a single host that is going to be scanned. If the target
+// was given as DNS name and resolved by the target generator, Address is the
+// resolved IP and Hostname the name it was resolved from
+type hostTarget struct {
+	Address  string
+	Hostname string
+}
+
+// newResolverFromConfig returns a resolver if DNS resolution on the server
+// is enabled, nil otherwise
+func newResolverFromConfig(conf *viper.Viper) *utils.Resolver {
+	conf = utils.ApplyDefaultTargetgeneratorResolveConfig(conf)
+	if !conf.GetBool("enabled") {
+		return nil
+	}
+	return utils.NewResolver(conf.GetStringSlice("nameservers"), conf.GetDuration("timeout"), conf.GetDuration("maxCacheTime"))
+}
+
+// resolveTarget resolves a DNS name and returns all addresses that are not
+// blacklisted. Since the blacklist is checked after resolution, a name that
+// points into a blacklisted network can't sneak in blacklisted addresses
+func resolveTarget(resolver *utils.Resolver, blacklist *NrayBlacklist, name string) []hostTarget {
+	addresses, err := resolver.LookupIPv4(name)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"module": "targetgeneration.dnsResolution",
+			"src":    "resolveTarget",
+		}).Warningf("Can't resolve %s, skipping: %v", name, err)
+		return nil
+	}
+	targets := make([]hostTarget, 0, len(addresses))
+	for _, address := range addresses {
+		if blacklist.IsIPBlacklisted(address) {
+			log.WithFields(log.Fields{
+				"module": "targetgeneration.dnsResolution",
+				"src":    "resolveTarget",
+			}).Warningf("%s resolves to blacklisted address %s, skipping", name, address)
+			continue
+		}
+		targets = append(targets, hostTarget{Address: address, Hostname: name})
+	}
+	return targets
+}
+
+// nextHostBatch reads up to maxHosts hosts from the channel. Each address may
+// only be associated with a single hostname in a batch, so if the same address
+// shows up with another name, the batch is finished early and the host is
+// returned as pending to be the first host of the next batch.
+// done is true if the channel is closed.
+func nextHostBatch(targets <-chan hostTarget, maxHosts uint, pending *hostTarget) (hosts []string, hostnames map[string]string, next *hostTarget, done bool) {
+	hosts = make([]string, 0)
+	hostnames = make(map[string]string)
+	seen := make(map[string]bool)
+	for uint(len(hosts)) < maxHosts {
+		var elem hostTarget
+		if pending != nil {
+			elem = *pending
+			pending = nil
+		} else {
+			var ok bool
+			elem, ok = <-targets
+			if !ok { // We're done, process remaining hosts and stop
+				return hosts, hostnames, nil, true
+			}
+		}
+		if seen[elem.Address] && hostnames[elem.Address] != elem.Hostname {
+			return hosts, hostnames, &elem, false
+		}
+		seen[elem.Address] = true
+		if elem.Hostname != "" {
+			hostnames[elem.Address] = elem.Hostname
+		}
+		hosts = append(hosts, elem.Address)
+	}
+	return hosts, hostnames, nil, false
+}
@@ -0,0 +1,189 @@
+package targetgeneration
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nray-scanner/nray/utils"
+	"github.com/spf13/viper"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// stubDNSServer answers A queries for a fixed set of names and
+// counts how often each name was asked for
+type stubDNSServer struct {
+	conn    net.PacketConn
+	records map[string][]string
+	queries map[string]int
+	lock    sync.Mutex
+	// Answers are delayed, so concurrent queries overlap
+	delay time.Duration
+}
+
+func startStubDNSServer(t *testing.T, records map[string][]string) *stubDNSServer {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &stubDNSServer{conn: conn, records: records, queries: make(map[string]int)}
+	go server.serve()
+	t.Cleanup(func() { conn.Close() })
+	return server
+}
+
+func (server *stubDNSServer) serve() {
+	buf := make([]byte, 1500)
+	for {
+		n, addr, err := server.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		var query dnsmessage.Message
+		if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) != 1 {
+			continue
+		}
+		question := query.Questions[0]
+		name := question.Name.String()
+		name = name[:len(name)-1]
+		server.lock.Lock()
+		server.queries[name]++
+		delay := server.delay
+		server.lock.Unlock()
+		time.Sleep(delay)
+
+		response := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: query.Header.ID, Response: true, RecursionAvailable: true},
+			Questions: query.Questions,
+		}
+		addresses, exists := server.records[name]
+		if !exists {
+			response.Header.RCode = dnsmessage.RCodeNameError
+		}
+		for _, address := range addresses {
+			var a [4]byte
+			copy(a[:], net.ParseIP(address).To4())
+			response.Answers = append(response.Answers, dnsmessage.Resource{
+				Header: dnsmessage.ResourceHeader{Name: question.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 300},
+				Body:   &dnsmessage.AResource{A: a},
+			})
+		}
+		packed, err := response.Pack()
+		if err != nil {
+			continue
+		}
+		server.conn.WriteTo(packed, addr)
+	}
+}
+
+func (server *stubDNSServer) queryCount(name string) int {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	return server.queries[name]
+}
+
+func TestResolverCache(t *testing.T) {
+	server := startStubDNSServer(t, map[string][]string{"web.example.test": {"10.0.0.1"}})
+	resolver := utils.NewResolver([]string{server.conn.LocalAddr().String()}, time.Second, time.Minute)
+	for i := 0; i < 3; i++ {
+		addresses, err := resolver.LookupIPv4("web.example.test")
+		if err != nil || len(addresses) != 1 || addresses[0] != "10.0.0.1" {
+			t.Fatalf("Wrong answer: %v, %v", addresses, err)
+		}
+		if _, err := resolver.LookupIPv4("missing.example.test"); err == nil {
+			t.Errorf("Name that does not exist should result in an error")
+		}
+	}
+	if server.queryCount("web.example.test") != 1 || server.queryCount("missing.example.test") != 1 {
+		t.Errorf("Answers were not cached")
+	}
+}
+
+func TestResolverConcurrentMisses(t *testing.T) {
+	server := startStubDNSServer(t, map[string][]string{"web.example.test": {"10.0.0.1"}})
+	server.lock.Lock()
+	server.delay = 100 * time.Millisecond
+	server.lock.Unlock()
+	resolver := utils.NewResolver([]string{server.conn.LocalAddr().String()}, time.Second, time.Minute)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if addresses, err := resolver.LookupIPv4("web.example.test"); err != nil || len(addresses) != 1 {
+				t.Errorf("Wrong answer: %v, %v", addresses, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if count := server.queryCount("web.example.test"); count != 1 {
+		t.Errorf("Concurrent lookups of the same name should share a query, got %d", count)
+	}
+}
+
+func TestStandardTGBackendResolution(t *testing.T) {
+	server := startStubDNSServer(t, map[string][]string{
+		"web.example.test":      {"10.0.0.1", "10.0.0.2"},
+		"db.example.test":       {"10.0.0.1"},
+		"internal.example.test": {"10.0.0.3"},
+	})
+	conf := viper.New()
+	conf.Set("targets", []string{"web.example.test", "db.example.test", "internal.example.test", "missing.example.test", "10.0.1.1"})
+	conf.Set("tcpports", []string{"80"})
+	conf.Set("udpports", []string{})
+	conf.Set("blacklist", []string{"10.0.0.2", "10.0.0.3"})
+	g := standardTGBackend{resolver: utils.NewResolver([]string{server.conn.LocalAddr().String()}, time.Second, time.Minute)}
+	if err := g.configure(conf); err != nil {
+		t.Fatal(err)
+	}
+
+	scanned := make(map[string][]string)
+	for target := range g.receiveTargets() {
+		for _, host := range target.RemoteHosts {
+			if net.ParseIP(host) == nil {
+				t.Errorf("Unresolved host sent to nodes: %s", host)
+			}
+			scanned[host] = append(scanned[host], target.Hostnames[host])
+		}
+	}
+	if _, exists := scanned["10.0.0.2"]; exists {
+		t.Errorf("Name resolving to a blacklisted address was scanned on that address")
+	}
+	if _, exists := scanned["10.0.0.3"]; exists {
+		t.Errorf("Name resolving to a blacklisted address was scanned")
+	}
+	// The address is scanned once for each name it was resolved from, each
+	// time with the correct name
+	if len(scanned["10.0.0.1"]) != 2 || scanned["10.0.0.1"][0] != "web.example.test" || scanned["10.0.0.1"][1] != "db.example.test" {
+		t.Errorf("Hostnames of 10.0.0.1 not preserved: %v", scanned["10.0.0.1"])
+	}
+	if len(scanned["10.0.1.1"]) != 1 || scanned["10.0.1.1"][0] != "" {
+		t.Errorf("IP target not scanned correctly: %v", scanned["10.0.1.1"])
+	}
+	if server.queryCount("web.example.test") != 1 {
+		t.Errorf("Counting and generating targets should share the cache, got %d queries", server.queryCount("web.example.test"))
+	}
+}
+
+func TestNextHostBatch(t *testing.T) {
+	targets := make(chan hostTarget, 10)
+	for _, target := range []hostTarget{
+		{Address: "10.0.0.1", Hostname: "a.example.test"},
+		{Address: "10.0.0.2"},
+		{Address: "10.0.0.1", Hostname: "b.example.test"},
+		{Address: "10.0.0.3"},
+	} {
+		targets <- target
+	}
+	close(targets)
+
+	hosts, hostnames, pending, done := nextHostBatch(targets, 10, nil)
+	if len(hosts) != 2 || done || pending == nil || hostnames["10.0.0.1"] != "a.example.test" {
+		t.Fatalf("Batch not split on conflicting hostname: %v %v %v", hosts, hostnames, pending)
+	}
+	hosts, hostnames, pending, done = nextHostBatch(targets, 10, pending)
+	if len(hosts) != 2 || !done || pending != nil || hostnames["10.0.0.1"] != "b.example.test" {
+		t.Errorf("Wrong second batch: %v %v", hosts, hostnames)
+	}
+}
@@ -0,0 +1,263 @@
+package targetgeneration
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/apparentlymart/go-cidr/cidr"
+	"github.com/spf13/viper"
+
+	"github.com/nray-scanner/nray/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// importTGBackend reads targets from files created by other tools,
+// for example scope lists exported from spreadsheets or results of
+// earlier nmap and masscan runs. If a file specifies ports for a host,
+// exactly these services are scanned, otherwise the configured default
+// ports are scanned on the host
+type importTGBackend struct {
+	rawConfig    *viper.Viper
+	hosts        []importedHost
+	tcpPorts     []uint16
+	udpPorts     []uint16
+	useHostPorts bool
+	maxHosts     uint
+	maxTCPPorts  uint
+	maxUDPPorts  uint
+	maxServices  uint
+	blacklist    *NrayBlacklist
+	resolver     *utils.Resolver
+}
+
+// configure is called to set up the generator
+func (generator *importTGBackend) configure(conf *viper.Viper) error {
+	conf = utils.ApplyDefaultTargetgeneratorImportConfig(conf)
+	generator.rawConfig = conf
+	generator.useHostPorts = conf.GetBool("useHostPorts")
+	generator.maxHosts = uint(conf.GetInt("maxHostsPerBatch"))
+	generator.maxTCPPorts = uint(conf.GetInt("maxTcpPortsPerBatch"))
+	generator.maxUDPPorts = uint(conf.GetInt("maxUdpPortsPerBatch"))
+	generator.maxServices = uint(conf.GetInt("maxServicesPerBatch"))
+	tcpPorts, udpPorts, err := portsFromConfig(conf)
+	if err != nil {
+		return err
+	}
+	generator.tcpPorts = tcpPorts
+	generator.udpPorts = udpPorts
+	generator.blacklist = blacklistFromConfig(conf)
+
+	for _, file := range conf.GetStringSlice("files") {
+		if strings.TrimSpace(file) == "" {
+			continue
+		}
+		hosts, err := importScopeFile(file, conf.GetString("format"))
+		if err != nil {
+			return fmt.Errorf("Failed to import %s: %v", file, err)
+		}
+		log.WithFields(log.Fields{
+			"module": "targetgeneration.importTGBackend",
+			"src":    "configure",
+		}).Debugf("Imported %d entries from %s", len(hosts), file)
+		generator.hosts = append(generator.hosts, hosts...)
+	}
+	return nil
+}
+
+// splitHosts merges the ports of hosts appearing multiple times. Hosts
+// without any ports are returned in the first slice and are scanned on the
+// default ports, the others are returned with their sorted port lists.
+// The order of first appearance is kept
+func (generator *importTGBackend) splitHosts() ([]string, []importedHost) {
+	hostOrder := make([]string, 0)
+	tcpPortsOfHost := make(map[string]map[uint16]bool)
+	udpPortsOfHost := make(map[string]map[uint16]bool)
+	for _, host := range generator.hosts {
+		if _, exists := tcpPortsOfHost[host.Host]; !exists {
+			hostOrder = append(hostOrder, host.Host)
+			tcpPortsOfHost[host.Host] = make(map[uint16]bool)
+			udpPortsOfHost[host.Host] = make(map[uint16]bool)
+		}
+		if !generator.useHostPorts {
+			continue
+		}
+		for _, port := range host.TCPPorts {
+			tcpPortsOfHost[host.Host][port] = true
+		}
+		for _, port := range host.UDPPorts {
+			udpPortsOfHost[host.Host][port] = true
+		}
+	}
+
+	defaultHosts := make([]string, 0)
+	serviceHosts := make([]importedHost, 0)
+	for _, host := range hostOrder {
+		tcpPorts := sortedPortSet(tcpPortsOfHost[host])
+		udpPorts := sortedPortSet(udpPortsOfHost[host])
+		if len(tcpPorts) == 0 && len(udpPorts) == 0 {
+			defaultHosts = append(defaultHosts, host)
+		} else {
+			serviceHosts = append(serviceHosts, importedHost{Host: host, TCPPorts: tcpPorts, UDPPorts: udpPorts})
+		}
+	}
+	return defaultHosts, serviceHosts
+}
+
+// sortedPortSet returns the ports of a set in ascending order
+func sortedPortSet(set map[uint16]bool) []uint16 {
+	ports := make([]uint16, 0, len(set))
+	for port := range set {
+		ports = append(ports, port)
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i] < ports[j] })
+	return ports
+}
+
+// expandHosts sends all hosts that are not blacklisted over the returned channel.
+// Networks are expanded and DNS names are resolved if a resolver is set.
+// The channel is closed when all hosts have been sent
+func (generator *importTGBackend) expandHosts(rawTargets []string) <-chan hostTarget {
+	targets := make(chan hostTarget, 50)
+	go func(targets chan<- hostTarget, rawTargets []string) {
+		for _, rawTarget := range rawTargets {
+			if utils.Ipv4NetRegexpr.MatchString(rawTarget) { // An IPv4 network
+				_, ipnet, err := net.ParseCIDR(rawTarget)
+				utils.CheckError(err, true)
+				for ip := range GenerateIPStreamFromCIDR(ipnet, generator.blacklist) {
+					targets <- hostTarget{Address: ip.String()}
+				}
+			} else if utils.Ipv4Regexpr.MatchString(rawTarget) { // An IPv4 address
+				if !generator.blacklist.IsIPBlacklisted(rawTarget) {
+					targets <- hostTarget{Address: rawTarget}
+				}
+			} else if utils.MayBeFQDN(rawTarget) { // Probably a FQDN
+				if generator.blacklist.IsDNSNameBlacklisted(rawTarget) {
+					continue
+				}
+				if generator.resolver == nil {
+					targets <- hostTarget{Address: rawTarget}
+					continue
+				}
+				for _, resolved := range resolveTarget(generator.resolver, generator.blacklist, rawTarget) {
+					targets <- resolved
+				}
+			} else {
+				log.WithFields(log.Fields{
+					"module": "targetgeneration.importTGBackend",
+					"src":    "expandHosts",
+				}).Debugf("This does not look like a valid target: %s", rawTarget)
+			}
+		}
+		close(targets)
+	}(targets, rawTargets)
+	return targets
+}
+
+// receiveTargets implements the interface stub and returns a channel with targets
+// All targets have been generated when the channel is closed
+func (generator *importTGBackend) receiveTargets() <-chan AnyTargets {
+	resultChan := make(chan AnyTargets, 10)
+	defaultHosts, serviceHosts := generator.splitHosts()
+	go func(resultChan chan<- AnyTargets) {
+		// Hosts with known services are sent as single services
+		services := make([]ServiceTarget, 0, generator.maxServices)
+		// An address may only belong to a single hostname within a batch
+		hostnames := make(map[string]string)
+		for _, serviceHost := range serviceHosts {
+			for host := range generator.expandHosts([]string{serviceHost.Host}) {
+				if hostname, seen := hostnames[host.Address]; seen && hostname != host.Hostname && len(services) > 0 {
+					resultChan <- AnyTargets{Services: services}
+					services = make([]ServiceTarget, 0, generator.maxServices)
+					hostnames = make(map[string]string)
+				}
+				hostnames[host.Address] = host.Hostname
+				for _, protoPorts := range []struct {
+					proto string
+					ports []uint16
+				}{{"tcp", serviceHost.TCPPorts}, {"udp", serviceHost.UDPPorts}} {
+					for _, port := range protoPorts.ports {
+						services = append(services, ServiceTarget{Host: host.Address, Protocol: protoPorts.proto, Port: uint32(port), Hostname: host.Hostname})
+						if uint(len(services)) >= generator.maxServices {
+							resultChan <- AnyTargets{Services: services}
+							services = make([]ServiceTarget, 0, generator.maxServices)
+							hostnames = make(map[string]string)
+							hostnames[host.Address] = host.Hostname
+						}
+					}
+				}
+			}
+		}
+		if len(services) > 0 {
+			resultChan <- AnyTargets{Services: services}
+		}
+
+		// All other hosts are scanned on the default ports
+		targets := generator.expandHosts(defaultHosts)
+		var stop bool
+		var pending *hostTarget
+		for !stop {
+			var hosts []string
+			var hostnames map[string]string
+			hosts, hostnames, pending, stop = nextHostBatch(targets, generator.maxHosts, pending)
+			if len(hosts) == 0 {
+				continue
+			}
+			for _, target := range chunkPorts(hosts, generator.tcpPorts, generator.udpPorts, generator.maxTCPPorts, generator.maxUDPPorts) {
+				target.Hostnames = hostnames
+				resultChan <- target
+			}
+		}
+		close(resultChan)
+	}(resultChan)
+
+	return resultChan
+}
+
+// hostCount returns the number of addresses of a host entry that are not
+// blacklisted. DNS names count with the number of addresses they resolve to
+// if resolution is enabled
+func (generator *importTGBackend) hostCount(host string) (uint64, error) {
+	if utils.Ipv4NetRegexpr.MatchString(host) {
+		_, ipnet, err := net.ParseCIDR(host)
+		if err != nil {
+			return 0, err
+		}
+		return cidr.AddressCount(ipnet) - generator.blacklist.blacklistedAddressCount(ipnet), nil
+	} else if utils.Ipv4Regexpr.MatchString(host) {
+		if !generator.blacklist.IsIPBlacklisted(host) {
+			return 1, nil
+		}
+	} else if utils.MayBeFQDN(host) {
+		if generator.blacklist.IsDNSNameBlacklisted(host) {
+			return 0, nil
+		}
+		if generator.resolver != nil {
+			return uint64(len(resolveTarget(generator.resolver, generator.blacklist, host))), nil
+		}
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// targetCount counts the ports of all imported hosts
+func (generator *importTGBackend) targetCount() (uint64, error) {
+	var count uint64
+	defaultHosts, serviceHosts := generator.splitHosts()
+	for _, host := range defaultHosts {
+		hostCount, err := generator.hostCount(host)
+		if err != nil {
+			return 0, err
+		}
+		count += hostCount * uint64(len(generator.tcpPorts)+len(generator.udpPorts))
+	}
+	for _, host := range serviceHosts {
+		hostCount, err := generator.hostCount(host.Host)
+		if err != nil {
+			return 0, err
+		}
+		count += hostCount * uint64(len(host.TCPPorts)+len(host.UDPPorts))
+	}
+	return count, nil
+}
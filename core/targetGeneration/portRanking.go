@@ -0,0 +1,209 @@
+package targetgeneration
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nray-scanner/nray/utils"
+)
+
+// RankedPort is a port together with the frequency it is found open
+type RankedPort struct {
+	Port      uint16
+	Frequency float64
+}
+
+// PortRanking orders TCP and UDP ports by how likely they are found open.
+// It is the source for top lists like "top25" or "top10%"
+type PortRanking struct {
+	TCP []RankedPort
+	UDP []RankedPort
+}
+
+var portRanking = defaultPortRanking()
+var portRankingLock sync.RWMutex
+
+// topPortFrequencies holds the open frequencies of the ports in topPorts.go,
+// one "port/proto frequency" per line
+//
+//go:embed topPortFrequencies.txt
+var topPortFrequencies string
+
+// defaultPortRanking is built from the static nmap lists in topPorts.go.
+// Frequencies are added from topPortFrequencies, the order of the lists is kept
+func defaultPortRanking() *PortRanking {
+	ranking := &PortRanking{
+		TCP: make([]RankedPort, 0, len(TopTCPPorts)),
+		UDP: make([]RankedPort, 0, len(TopUDPPorts)),
+	}
+	for _, port := range TopTCPPorts {
+		ranking.TCP = append(ranking.TCP, RankedPort{Port: port})
+	}
+	for _, port := range TopUDPPorts {
+		ranking.UDP = append(ranking.UDP, RankedPort{Port: port})
+	}
+	frequencies, err := ParsePortRanking(strings.NewReader(topPortFrequencies))
+	utils.CheckError(err, true)
+	ranking.addFrequencies(frequencies)
+	return ranking
+}
+
+// addFrequencies copies the frequencies of another ranking without changing the order
+func (ranking *PortRanking) addFrequencies(frequencies *PortRanking) {
+	for _, proto := range []string{"tcp", "udp"} {
+		known := make(map[uint16]float64)
+		for _, rankedPort := range frequencies.ports(proto) {
+			known[rankedPort.Port] = rankedPort.Frequency
+		}
+		rankedPorts := ranking.ports(proto)
+		for i := range rankedPorts {
+			rankedPorts[i].Frequency = known[rankedPorts[i].Port]
+		}
+	}
+}
+
+// SetPortRanking replaces the ranking used for top lists. Passing nil
+// restores the embedded default
+func SetPortRanking(ranking *PortRanking) {
+	portRankingLock.Lock()
+	defer portRankingLock.Unlock()
+	if ranking == nil {
+		ranking = defaultPortRanking()
+	}
+	portRanking = ranking
+}
+
+func currentPortRanking() *PortRanking {
+	portRankingLock.RLock()
+	defer portRankingLock.RUnlock()
+	return portRanking
+}
+
+// LoadPortRanking reads a ranking from a file, see ParsePortRanking for the format
+func LoadPortRanking(path string) (*PortRanking, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	ranking, err := ParsePortRanking(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return ranking, nil
+}
+
+// ParsePortRanking reads files in nmap-services format, e.g.
+// "http	80/tcp	0.484143	# World Wide Web HTTP"
+// as well as simpler frequency files with lines like "80/tcp 0.48".
+// Ports are ordered by descending frequency. If a file has no frequencies
+// at all, the order of the file is kept. Protocols other than TCP and UDP
+// are ignored
+func ParsePortRanking(reader io.Reader) (*PortRanking, error) {
+	ranking := &PortRanking{TCP: make([]RankedPort, 0), UDP: make([]RankedPort, 0)}
+	seen := map[string]map[uint16]bool{"tcp": {}, "udp": {}}
+	lineScanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for lineScanner.Scan() {
+		lineNumber++
+		line := lineScanner.Text()
+		if pos := strings.Index(line, "#"); pos != -1 {
+			line = line[:pos]
+		}
+		fields := strings.Fields(strings.ReplaceAll(line, ",", " "))
+		if len(fields) == 0 {
+			continue
+		}
+		var portProto []string
+		var pos int
+		for pos = range fields {
+			if portProto = strings.Split(fields[pos], "/"); len(portProto) == 2 {
+				break
+			}
+		}
+		if len(portProto) != 2 {
+			return nil, fmt.Errorf("line %d: no port/protocol found", lineNumber)
+		}
+		port, err := strconv.ParseUint(portProto[0], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid port %s", lineNumber, portProto[0])
+		}
+		rankedPort := RankedPort{Port: uint16(port)}
+		if pos+1 < len(fields) {
+			if rankedPort.Frequency, err = strconv.ParseFloat(fields[pos+1], 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid frequency %s", lineNumber, fields[pos+1])
+			}
+		}
+		proto := strings.ToLower(portProto[1])
+		if seen[proto] == nil || seen[proto][rankedPort.Port] {
+			continue
+		}
+		seen[proto][rankedPort.Port] = true
+		if proto == "tcp" {
+			ranking.TCP = append(ranking.TCP, rankedPort)
+		} else {
+			ranking.UDP = append(ranking.UDP, rankedPort)
+		}
+	}
+	if err := lineScanner.Err(); err != nil {
+		return nil, err
+	}
+	for _, ports := range [][]RankedPort{ranking.TCP, ranking.UDP} {
+		sort.SliceStable(ports, func(i, j int) bool { return ports[i].Frequency > ports[j].Frequency })
+	}
+	return ranking, nil
+}
+
+func (ranking *PortRanking) ports(proto string) []RankedPort {
+	if proto == "udp" {
+		return ranking.UDP
+	}
+	return ranking.TCP
+}
+
+// Top returns the topN ports of a protocol
+func (ranking *PortRanking) Top(proto string, topN uint) []uint16 {
+	rankedPorts := ranking.ports(proto)
+	if topN > uint(len(rankedPorts)) {
+		topN = uint(len(rankedPorts))
+	}
+	ports := make([]uint16, 0, topN)
+	for _, rankedPort := range rankedPorts[:topN] {
+		ports = append(ports, rankedPort.Port)
+	}
+	return ports
+}
+
+// TopPercent returns the most frequent ports that together account for
+// the given percentage of the summed up frequency of all ports of a protocol,
+// e.g. "top 90%" are the ports where 90% of all open ports are expected
+func (ranking *PortRanking) TopPercent(proto string, percent float64) ([]uint16, error) {
+	if percent <= 0 || percent > 100 {
+		return nil, fmt.Errorf("percentage %g is not between 0 and 100", percent)
+	}
+	rankedPorts := ranking.ports(proto)
+	var total float64
+	for _, rankedPort := range rankedPorts {
+		total += rankedPort.Frequency
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("the port ranking has no %s frequencies, set topPortsFile or --top-ports-file to a services file with frequencies", proto)
+	}
+	ports := make([]uint16, 0)
+	var covered float64
+	for _, rankedPort := range rankedPorts {
+		if covered >= total*percent/100 {
+			break
+		}
+		covered += rankedPort.Frequency
+		ports = append(ports, rankedPort.Port)
+	}
+	return ports, nil
+}
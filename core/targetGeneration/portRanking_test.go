@@ -0,0 +1,105 @@
+package targetgeneration
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testNmapServices = `# Fields in this file are: Service name, portnum/protocol, open-frequency, optional comments
+#
+tcpmux	1/tcp	0.001995	# TCP Port Service Multiplexer [rfc-1078]
+ssh	22/tcp	0.182286	# Secure Shell Login
+http	80/tcp	0.484143	# World Wide Web HTTP
+domain	53/udp	0.213496	# Domain Name Server
+https	443/tcp	0.208669	# secure http (SSL)
+snmp	161/udp	0.433467	# Simple Net Mgmt Proto
+http	80/sctp	0.000000
+unknown	9999/tcp	0.001000
+`
+
+func TestParsePortRanking(t *testing.T) {
+	ranking, err := ParsePortRanking(strings.NewReader(testNmapServices))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ranking.Top("tcp", 3), []uint16{80, 443, 22}) {
+		t.Errorf("Wrong TCP ranking: %v", ranking.TCP)
+	}
+	if ports := ranking.Top("udp", 100); len(ports) != 2 || ports[0] != 161 {
+		t.Errorf("Wrong UDP ranking: %v", ports)
+	}
+	// 80 and 443 make up ~79% of the TCP frequencies
+	ports, err := ranking.TopPercent("tcp", 70)
+	if err != nil || !portsEqual(ports, []uint16{80, 443}) {
+		t.Errorf("Wrong top 70%%: %v %v", ports, err)
+	}
+	if ports, _ := ranking.TopPercent("tcp", 100); len(ports) != 5 {
+		t.Errorf("Top 100%% should contain all ports: %v", ports)
+	}
+
+	// Without frequencies, the order of the file is kept
+	ranking, err = ParsePortRanking(strings.NewReader("8080/tcp\n22/tcp\n53/udp\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ports := ranking.Top("tcp", 2); ports[0] != 8080 || ports[1] != 22 {
+		t.Errorf("Order of file not kept: %v", ports)
+	}
+	if _, err := ranking.TopPercent("tcp", 50); err == nil {
+		t.Errorf("Percentages without frequencies should result in an error")
+	}
+
+	if _, err := ParsePortRanking(strings.NewReader("http 80/tcp often\n")); err == nil {
+		t.Errorf("Invalid frequency should result in an error")
+	}
+}
+
+func TestPortSpecWithRanking(t *testing.T) {
+	defaultTop := GetNmapTopTCPPorts(10)
+	ranking, err := ParsePortRanking(strings.NewReader(testNmapServices))
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetPortRanking(ranking)
+	defer SetPortRanking(nil)
+
+	tcpPorts, udpPorts, err := ParsePortSpec([]string{"top1", "U:top40%"}, "tcp")
+	if err != nil || !portsEqual(tcpPorts, []uint16{80}) || !portsEqual(udpPorts, []uint16{161}) {
+		t.Errorf("Wrong ports: %v %v %v", tcpPorts, udpPorts, err)
+	}
+	if _, _, err := ParsePortSpec([]string{"top101%"}, "tcp"); err == nil {
+		t.Errorf("More than 100%% should result in an error")
+	}
+
+	SetPortRanking(nil)
+	if !portsEqual(GetNmapTopTCPPorts(10), defaultTop) {
+		t.Errorf("Default ranking not restored")
+	}
+	if len(GetNmapTopTCPPorts(20000)) != len(TopTCPPorts) {
+		t.Errorf("Top lists with more than 4 digits should be capped at the ranking size")
+	}
+}
+
+func TestAddFrequencies(t *testing.T) {
+	frequencies, err := ParsePortRanking(strings.NewReader(testNmapServices))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ranking := &PortRanking{TCP: []RankedPort{{Port: 22}, {Port: 80}, {Port: 8080}}, UDP: []RankedPort{{Port: 161}}}
+	ranking.addFrequencies(frequencies)
+	if !reflect.DeepEqual(ranking.Top("tcp", 3), []uint16{22, 80, 8080}) {
+		t.Errorf("Order of the ranking must be kept: %v", ranking.TCP)
+	}
+	if ports, err := ranking.TopPercent("tcp", 50); err != nil || !portsEqual(ports, []uint16{22, 80}) {
+		t.Errorf("Wrong top 50%%: %v %v", ports, err)
+	}
+
+	// The embedded frequencies must not change the default top lists
+	if !reflect.DeepEqual(defaultPortRanking().Top("tcp", uint(len(TopTCPPorts))), TopTCPPorts[:]) {
+		t.Errorf("Default TCP top list changed")
+	}
+	if !reflect.DeepEqual(defaultPortRanking().Top("udp", uint(len(TopUDPPorts))), TopUDPPorts[:]) {
+		t.Errorf("Default UDP top list changed")
+	}
+}
@@ -0,0 +1,243 @@
+package targetgeneration
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nray-scanner/nray/utils"
+	"github.com/spf13/viper"
+)
+
+var openPortRangeRegexpr = regexp.MustCompile(`^([0-9]{0,5})-([0-9]{0,5})$`)
+var topPortsRegexpr = regexp.MustCompile(utils.RegexTopPorts)
+
+// ParsePortSpec parses a port specification similar to the one nmap uses.
+// Each element may contain multiple comma separated entries. Entries are
+//   - single ports: 80
+//   - ranges, optionally open ended: 20-25, -1024, 60000-
+//   - top lists: top25, or by frequency: top90% (see PortRanking.TopPercent)
+//   - all ports: all
+//   - service names from the bundled services table: http, ssh, smb
+//
+// Entries may be prefixed with T: or U: to select the protocol, which then
+// also applies to the following entries of the same element, e.g. "T:80,443,U:53".
+// Entries without a prefix use defaultProto, if defaultProto is empty they apply
+// to TCP and UDP. Entries prefixed with ! are excluded, e.g. "all,!9100" or
+// "T:1-1024,!T:139". Port 0 can't be scanned. Any invalid entry results in an error
+func ParsePortSpec(rawPorts []string, defaultProto string) ([]uint16, []uint16, error) {
+	included := map[string]*orderedPortSet{"tcp": newOrderedPortSet(), "udp": newOrderedPortSet()}
+	excluded := map[string]*orderedPortSet{"tcp": newOrderedPortSet(), "udp": newOrderedPortSet()}
+	if protosOf(defaultProto) == nil {
+		return nil, nil, fmt.Errorf("Unknown protocol %s", defaultProto)
+	}
+	for _, rawPort := range rawPorts {
+		protos := protosOf(defaultProto)
+		for _, entry := range strings.Split(rawPort, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			target := included
+			if strings.HasPrefix(entry, "!") {
+				target = excluded
+				entry = entry[1:]
+			}
+			if pos := strings.Index(entry, ":"); pos != -1 {
+				switch strings.ToUpper(entry[:pos]) {
+				case "T":
+					protos = []string{"tcp"}
+				case "U":
+					protos = []string{"udp"}
+				default:
+					return nil, nil, fmt.Errorf("Unknown protocol prefix in port specification %s", rawPort)
+				}
+				entry = entry[pos+1:]
+			}
+			if strings.HasPrefix(entry, "!") {
+				target = excluded
+				entry = entry[1:]
+			}
+			for _, proto := range protos {
+				ports, err := parsePortEntry(entry, proto, len(protos) > 1)
+				if err != nil {
+					return nil, nil, fmt.Errorf("Invalid port specification %s: %v", rawPort, err)
+				}
+				target[proto].add(ports...)
+			}
+		}
+	}
+	return included["tcp"].without(excluded["tcp"]), included["udp"].without(excluded["udp"]), nil
+}
+
+// ExcludePorts removes all ports matching the specification from the port lists.
+// Entries without a protocol prefix are removed from both lists
+func ExcludePorts(tcpPorts []uint16, udpPorts []uint16, excludeSpec []string) ([]uint16, []uint16, error) {
+	excludedTCP, excludedUDP, err := ParsePortSpec(excludeSpec, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	tcpSet := newOrderedPortSet()
+	tcpSet.add(excludedTCP...)
+	udpSet := newOrderedPortSet()
+	udpSet.add(excludedUDP...)
+	tcpResult := newOrderedPortSet()
+	tcpResult.add(tcpPorts...)
+	udpResult := newOrderedPortSet()
+	udpResult.add(udpPorts...)
+	return tcpResult.without(tcpSet), udpResult.without(udpSet), nil
+}
+
+// ValidatePortConfig checks the port specifications of the target generator
+// configuration, so mistakes show up when the server starts and not once the
+// pools are set up. The ranking of topPortsFile is loaded for this
+func ValidatePortConfig(config *viper.Viper) error {
+	if config == nil {
+		config = viper.New()
+	}
+	if topPortsFile := config.GetString("topPortsFile"); topPortsFile != "" {
+		ranking, err := LoadPortRanking(topPortsFile)
+		if err != nil {
+			return err
+		}
+		SetPortRanking(ranking)
+	}
+	if _, _, err := portsFromConfig(utils.ApplyDefaultTargetgeneratorStandardConfig(config.Sub("standard"))); err != nil {
+		return fmt.Errorf("targetgenerator.standard: %v", err)
+	}
+	if config.GetBool("import.enabled") {
+		if _, _, err := portsFromConfig(utils.ApplyDefaultTargetgeneratorImportConfig(config.Sub("import"))); err != nil {
+			return fmt.Errorf("targetgenerator.import: %v", err)
+		}
+	}
+	return nil
+}
+
+// portsFromConfig reads the port configuration of a target generator backend.
+// tcpports and udpports default to their protocol, entries of ports default to TCP.
+// excludePorts are removed afterwards
+func portsFromConfig(conf *viper.Viper) ([]uint16, []uint16, error) {
+	tcpSet := newOrderedPortSet()
+	udpSet := newOrderedPortSet()
+	for _, spec := range []struct {
+		key   string
+		proto string
+	}{{"tcpports", "tcp"}, {"udpports", "udp"}, {"ports", "tcp"}} {
+		tcpPorts, udpPorts, err := ParsePortSpec(conf.GetStringSlice(spec.key), spec.proto)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", spec.key, err)
+		}
+		tcpSet.add(tcpPorts...)
+		udpSet.add(udpPorts...)
+	}
+	tcpPorts, udpPorts, err := ExcludePorts(tcpSet.ports, udpSet.ports, conf.GetStringSlice("excludePorts"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("excludePorts: %v", err)
+	}
+	return tcpPorts, udpPorts, nil
+}
+
+func protosOf(proto string) []string {
+	switch strings.ToLower(proto) {
+	case "":
+		return []string{"tcp", "udp"}
+	case "tcp":
+		return []string{"tcp"}
+	case "udp":
+		return []string{"udp"}
+	}
+	return nil
+}
+
+// parsePortEntry parses a single entry without protocol prefix or exclusion mark.
+// If lenient is set, service names that are unknown for the protocol are no error
+// because the entry applies to multiple protocols
+func parsePortEntry(entry string, proto string, lenient bool) ([]uint16, error) {
+	ports := make([]uint16, 0)
+	if parsed, err := strconv.ParseUint(entry, 10, 16); err == nil { // A single port
+		if parsed == 0 {
+			return nil, fmt.Errorf("port 0 can't be scanned")
+		}
+		return append(ports, uint16(parsed)), nil
+	} else if matches := openPortRangeRegexpr.FindStringSubmatch(entry); matches != nil && entry != "-" { // A port range
+		first, second := uint64(1), uint64(math.MaxUint16)
+		var err error
+		if matches[1] != "" {
+			if first, err = strconv.ParseUint(matches[1], 10, 16); err != nil {
+				return nil, fmt.Errorf("%s is not a valid port", matches[1])
+			}
+		}
+		if matches[2] != "" {
+			if second, err = strconv.ParseUint(matches[2], 10, 16); err != nil {
+				return nil, fmt.Errorf("%s is not a valid port", matches[2])
+			}
+		}
+		if first > second {
+			first, second = second, first
+		}
+		if first == 0 {
+			return nil, fmt.Errorf("port 0 can't be scanned")
+		}
+		for i := first; i <= second; i++ {
+			ports = append(ports, uint16(i))
+		}
+		return ports, nil
+	} else if matches := topPortsRegexpr.FindStringSubmatch(entry); matches != nil { // A toplist
+		if matches[2] == "%" {
+			percent, err := strconv.ParseFloat(matches[1], 64)
+			if err != nil {
+				return nil, err
+			}
+			return currentPortRanking().TopPercent(proto, percent)
+		}
+		topN, err := strconv.ParseUint(matches[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a valid number of top ports", matches[1])
+		}
+		return currentPortRanking().Top(proto, uint(topN)), nil
+	} else if entry == "all" {
+		return parsePortEntry("1-", proto, lenient)
+	} else if servicePorts, known := serviceNames[strings.ToLower(entry)]; known { // A service name
+		for _, servicePort := range servicePorts {
+			if servicePort.Proto == proto {
+				ports = append(ports, servicePort.Port)
+			}
+		}
+		if len(ports) == 0 && !lenient {
+			return nil, fmt.Errorf("service %s has no %s port", entry, proto)
+		}
+		return ports, nil
+	}
+	return nil, fmt.Errorf("%s is neither a port, a range, a top list nor a known service", entry)
+}
+
+// orderedPortSet keeps ports in the order they were added without duplicates
+type orderedPortSet struct {
+	ports    []uint16
+	contains map[uint16]bool
+}
+
+func newOrderedPortSet() *orderedPortSet {
+	return &orderedPortSet{ports: make([]uint16, 0), contains: make(map[uint16]bool)}
+}
+
+func (set *orderedPortSet) add(ports ...uint16) {
+	for _, port := range ports {
+		if !set.contains[port] {
+			set.contains[port] = true
+			set.ports = append(set.ports, port)
+		}
+	}
+}
+
+func (set *orderedPortSet) without(excluded *orderedPortSet) []uint16 {
+	ports := make([]uint16, 0, len(set.ports))
+	for _, port := range set.ports {
+		if !excluded.contains[port] {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
@@ -0,0 +1,87 @@
+package targetgeneration
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestParsePortSpec(t *testing.T) {
+	testcases := []struct {
+		spec         []string
+		defaultProto string
+		tcp          []uint16
+		udp          []uint16
+	}{
+		{[]string{"T:80,443,U:53"}, "tcp", []uint16{80, 443}, []uint16{53}},
+		{[]string{"22", "U:161,162"}, "tcp", []uint16{22}, []uint16{161, 162}},
+		{[]string{"ssh,http,smb"}, "tcp", []uint16{22, 80, 139, 445}, []uint16{}},
+		{[]string{"U:domain,snmp"}, "tcp", []uint16{}, []uint16{53, 161}},
+		{[]string{"-5"}, "udp", []uint16{}, []uint16{1, 2, 3, 4, 5}},
+		{[]string{"65533-"}, "tcp", []uint16{65533, 65534, 65535}, []uint16{}},
+		{[]string{"1-10,!2-9"}, "tcp", []uint16{1, 10}, []uint16{}},
+		{[]string{"T:1-3,U:1-3,!T:2,U:!3"}, "tcp", []uint16{1, 3}, []uint16{1, 2}},
+		{[]string{"domain"}, "", []uint16{53}, []uint16{53}},
+		{[]string{"80,80,443"}, "tcp", []uint16{80, 443}, []uint16{}},
+		// A prefix only applies to its own element
+		{[]string{"U:53", "80"}, "tcp", []uint16{80}, []uint16{53}},
+	}
+	for _, testcase := range testcases {
+		tcpPorts, udpPorts, err := ParsePortSpec(testcase.spec, testcase.defaultProto)
+		if err != nil {
+			t.Errorf("%v: %v", testcase.spec, err)
+			continue
+		}
+		if !portsEqual(tcpPorts, testcase.tcp) || !portsEqual(udpPorts, testcase.udp) {
+			t.Errorf("%v: got %v %v, expected %v %v", testcase.spec, tcpPorts, udpPorts, testcase.tcp, testcase.udp)
+		}
+	}
+
+	tcpPorts, _, err := ParsePortSpec([]string{"all,!9100"}, "tcp")
+	if err != nil || len(tcpPorts) != 65534 {
+		t.Errorf("all,!9100 returned %d ports: %v", len(tcpPorts), err)
+	}
+
+	for _, invalid := range []string{"lorem ipsum", "www.google.com", "70000", "1-70000", "X:80", "U:ssh", "-", "80:", "0", "0-10", "0-"} {
+		if _, _, err := ParsePortSpec([]string{invalid}, "tcp"); err == nil {
+			t.Errorf("%s should result in an error", invalid)
+		}
+	}
+}
+
+func TestPortsFromConfig(t *testing.T) {
+	conf := viper.New()
+	conf.Set("tcpports", []string{"21-23"})
+	conf.Set("udpports", []string{"53"})
+	conf.Set("ports", []string{"T:80,U:161"})
+	conf.Set("excludePorts", []string{"22", "U:53"})
+	tcpPorts, udpPorts, err := portsFromConfig(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !portsEqual(tcpPorts, []uint16{21, 23, 80}) || !portsEqual(udpPorts, []uint16{161}) {
+		t.Errorf("Wrong ports: %v %v", tcpPorts, udpPorts)
+	}
+
+	conf.Set("udpports", []string{"notaport"})
+	if _, _, err := portsFromConfig(conf); err == nil {
+		t.Errorf("Invalid port should result in an error")
+	}
+}
+
+func TestValidatePortConfig(t *testing.T) {
+	if err := ValidatePortConfig(nil); err != nil {
+		t.Errorf("The default ports must be valid: %v", err)
+	}
+	config := viper.New()
+	config.Set("standard.tcpports", []string{"0-10"})
+	if err := ValidatePortConfig(config); err == nil {
+		t.Errorf("Expected an error for port 0")
+	}
+	config = viper.New()
+	config.Set("import.enabled", true)
+	config.Set("import.udpports", []string{"U:ssh"})
+	if err := ValidatePortConfig(config); err == nil {
+		t.Errorf("Expected an error for the import backend")
+	}
+}
@@ -0,0 +1,73 @@
+package targetgeneration
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nray-scanner/nray/utils"
+)
+
+// ScopeIssue is a configured target entry that deserves a second look
+// before starting a scan, e.g. because it matches nothing or because
+// it is (partially) blacklisted
+type ScopeIssue struct {
+	Generator string `json:"generator"`
+	Entry     string `json:"entry"`
+	Problem   string `json:"problem"`
+}
+
+// checkRawTargets returns issues for entries that target generation
+// silently drops and for entries overlapping with the blacklist
+func checkRawTargets(generator string, rawTargets []string, blacklist *NrayBlacklist, resolver *utils.Resolver) []ScopeIssue {
+	issues := make([]ScopeIssue, 0)
+	for _, rawTarget := range rawTargets {
+		if rawTarget == "" {
+			continue
+		}
+		if !utils.Ipv4NetRegexpr.MatchString(rawTarget) && !utils.Ipv4Regexpr.MatchString(rawTarget) && !utils.MayBeFQDN(rawTarget) {
+			issues = append(issues, ScopeIssue{generator, rawTarget, "does not look like a valid target and matches nothing"})
+			continue
+		}
+		if overlapping := blacklist.overlaps(rawTarget); len(overlapping) > 0 {
+			issues = append(issues, ScopeIssue{generator, rawTarget, fmt.Sprintf("overlaps with blacklist entries %s", strings.Join(overlapping, ", "))})
+			continue
+		}
+		if resolver == nil || utils.Ipv4NetRegexpr.MatchString(rawTarget) || utils.Ipv4Regexpr.MatchString(rawTarget) {
+			continue
+		}
+		addresses, err := resolver.LookupIPv4(rawTarget)
+		if err != nil {
+			issues = append(issues, ScopeIssue{generator, rawTarget, fmt.Sprintf("can't be resolved: %v", err)})
+			continue
+		}
+		for _, address := range addresses {
+			if blacklist.IsIPBlacklisted(address) {
+				issues = append(issues, ScopeIssue{generator, rawTarget, fmt.Sprintf("resolves to blacklisted address %s", address)})
+			}
+		}
+	}
+	return issues
+}
+
+// scopeIssues implements the interface stub
+func (generator *standardTGBackend) scopeIssues() []ScopeIssue {
+	return checkRawTargets("standard", generator.rawTargets, generator.blacklist, generator.resolver)
+}
+
+// scopeIssues implements the interface stub
+func (generator *importTGBackend) scopeIssues() []ScopeIssue {
+	rawTargets := make([]string, 0, len(generator.hosts))
+	for _, host := range generator.hosts {
+		rawTargets = append(rawTargets, host.Host)
+	}
+	return checkRawTargets("import", rawTargets, generator.blacklist, generator.resolver)
+}
+
+// ScopeIssues returns the issues found in the targets of all configured backends
+func (tg *TargetGenerator) ScopeIssues() []ScopeIssue {
+	issues := make([]ScopeIssue, 0)
+	for _, backend := range tg.backends {
+		issues = append(issues, backend.scopeIssues()...)
+	}
+	return issues
+}
@@ -0,0 +1,60 @@
+package targetgeneration
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestCheckRawTargets(t *testing.T) {
+	blacklist := NewBlacklist()
+	blacklist.AddToBlacklist("10.0.0.128/25")
+	blacklist.AddToBlacklist("10.0.1.5")
+	blacklist.AddToBlacklist("intranet.example.local")
+
+	issues := checkRawTargets("standard", []string{"10.0.0.0/24", "10.0.1.0/29", "10.0.2.1", "intranet.example.local", "www.example.local", "http://example.local/", ""}, blacklist, nil)
+	problems := make(map[string]bool)
+	for _, issue := range issues {
+		problems[issue.Entry] = true
+	}
+	for _, entry := range []string{"10.0.0.0/24", "10.0.1.0/29", "intranet.example.local", "http://example.local/"} {
+		if !problems[entry] {
+			t.Errorf("No issue reported for %s", entry)
+		}
+	}
+	if len(issues) != 4 {
+		t.Errorf("Expected 4 issues, got %+v", issues)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	config := viper.New()
+	config.Set("buffersize", 5)
+	config.Set("standard.targets", []string{"10.0.0.0/29", "10.0.1.1", "10.0.1.1"})
+	config.Set("standard.tcpports", []string{"22", "80-82"})
+	config.Set("standard.udpports", []string{"53"})
+	config.Set("standard.blacklist", []string{"10.0.0.4/30"})
+	config.Set("standard.maxHostsPerBatch", 3)
+	config.Set("standard.maxTcpPortsPerBatch", 2)
+
+	tg := TargetGenerator{}
+	tg.Init(config)
+	summary := tg.Summarize(true)
+	// 4 addresses of the network and the single IP twice
+	if summary.HostCount != 5 || len(summary.Hosts) != 5 {
+		t.Errorf("Wrong host count: %d %v", summary.HostCount, summary.Hosts)
+	}
+	if len(summary.TCPPorts) != 4 || len(summary.UDPPorts) != 1 {
+		t.Errorf("Wrong ports: %v %v", summary.TCPPorts, summary.UDPPorts)
+	}
+	// 6 hosts in batches of 3 with 2 port chunks each
+	if summary.BatchCount != 4 {
+		t.Errorf("Wrong batch count: %d", summary.BatchCount)
+	}
+	if summary.TargetCount != 30 {
+		t.Errorf("Wrong target count: %d", summary.TargetCount)
+	}
+	if len(summary.Issues) != 1 || summary.Issues[0].Entry != "10.0.0.0/29" {
+		t.Errorf("Wrong issues: %+v", summary.Issues)
+	}
+}
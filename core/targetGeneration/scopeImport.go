@@ -0,0 +1,302 @@
+package targetgeneration
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// importedHost is a single host read from a scope or result file.
+// If the file also specified ports for this host, they are set
+// as well, otherwise the port slices are empty
+type importedHost struct {
+	Host     string
+	TCPPorts []uint16
+	UDPPorts []uint16
+}
+
+// Supported import formats
+const (
+	importFormatAuto        = "auto"
+	importFormatNmapXML     = "nmap-xml"
+	importFormatMasscanJSON = "masscan-json"
+	importFormatMasscanList = "masscan-list"
+	importFormatCSV         = "csv"
+)
+
+// importScopeFile reads a file in one of the supported formats and returns
+// the hosts contained in there. If format is "auto", the format is guessed
+// from the file extension and the content
+func importScopeFile(path string, format string) ([]importedHost, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if format == "" || format == importFormatAuto {
+		format = detectImportFormat(path, content)
+	}
+	switch format {
+	case importFormatNmapXML:
+		return parseNmapXML(bytes.NewReader(content))
+	case importFormatMasscanJSON:
+		return parseMasscanJSON(content)
+	case importFormatMasscanList:
+		return parseMasscanList(bytes.NewReader(content))
+	case importFormatCSV:
+		return parseScopeCSV(bytes.NewReader(content))
+	default:
+		return nil, fmt.Errorf("Unknown import format %s for file %s", format, path)
+	}
+}
+
+// detectImportFormat tries to guess the format of a file. The file extension wins,
+// if it is not conclusive the first characters of the file are inspected
+func detectImportFormat(path string, content []byte) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml":
+		return importFormatNmapXML
+	case ".json":
+		return importFormatMasscanJSON
+	case ".csv":
+		return importFormatCSV
+	}
+	trimmed := bytes.TrimSpace(content)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return importFormatNmapXML
+	case bytes.HasPrefix(trimmed, []byte("[")), bytes.HasPrefix(trimmed, []byte("{")):
+		return importFormatMasscanJSON
+	case bytes.HasPrefix(trimmed, []byte("#masscan")), bytes.HasPrefix(trimmed, []byte("open ")):
+		return importFormatMasscanList
+	default:
+		return importFormatCSV
+	}
+}
+
+// appendPort adds a port to the host depending on its protocol.
+// Protocols other than TCP and UDP are ignored
+func (host *importedHost) appendPort(proto string, port uint16) {
+	switch strings.ToLower(proto) {
+	case "tcp":
+		host.TCPPorts = append(host.TCPPorts, port)
+	case "udp":
+		host.UDPPorts = append(host.UDPPorts, port)
+	}
+}
+
+type nmapRun struct {
+	Hosts []nmapHost `xml:"host"`
+}
+
+type nmapHost struct {
+	Status struct {
+		State string `xml:"state,attr"`
+	} `xml:"status"`
+	Addresses []struct {
+		Addr     string `xml:"addr,attr"`
+		AddrType string `xml:"addrtype,attr"`
+	} `xml:"address"`
+	Hostnames []struct {
+		Name string `xml:"name,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"hostnames>hostname"`
+	Ports []struct {
+		Protocol string `xml:"protocol,attr"`
+		PortID   uint16 `xml:"portid,attr"`
+		State    struct {
+			State string `xml:"state,attr"`
+		} `xml:"state"`
+	} `xml:"ports>port"`
+}
+
+// parseNmapXML parses the output of nmap -oX. Hosts that are down are skipped,
+// only open ports are taken over. If a host has no IPv4 address, the user supplied
+// hostname is used
+func parseNmapXML(reader io.Reader) ([]importedHost, error) {
+	var run nmapRun
+	if err := xml.NewDecoder(reader).Decode(&run); err != nil {
+		return nil, err
+	}
+	hosts := make([]importedHost, 0, len(run.Hosts))
+	for _, nmapHost := range run.Hosts {
+		if nmapHost.Status.State == "down" {
+			continue
+		}
+		host := importedHost{}
+		for _, address := range nmapHost.Addresses {
+			if address.AddrType == "ipv4" {
+				host.Host = address.Addr
+				break
+			}
+		}
+		if host.Host == "" {
+			for _, hostname := range nmapHost.Hostnames {
+				if hostname.Type == "user" {
+					host.Host = hostname.Name
+					break
+				}
+			}
+		}
+		if host.Host == "" {
+			continue
+		}
+		for _, port := range nmapHost.Ports {
+			if port.State.State == "open" {
+				host.appendPort(port.Protocol, port.PortID)
+			}
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+type masscanRecord struct {
+	IP    string `json:"ip"`
+	Ports []struct {
+		Port   uint16 `json:"port"`
+		Proto  string `json:"proto"`
+		Status string `json:"status"`
+	} `json:"ports"`
+}
+
+// parseMasscanJSON parses the output of masscan -oJ. Depending on the version,
+// masscan writes JSON that is not valid as a whole (trailing commas), so if the
+// file can't be decoded in one go, each line is decoded on its own
+func parseMasscanJSON(content []byte) ([]importedHost, error) {
+	var records []masscanRecord
+	if err := json.Unmarshal(content, &records); err != nil {
+		records = records[:0]
+		lineScanner := bufio.NewScanner(bytes.NewReader(content))
+		for lineScanner.Scan() {
+			line := strings.Trim(strings.TrimSpace(lineScanner.Text()), ",")
+			if !strings.HasPrefix(line, "{") {
+				continue
+			}
+			var record masscanRecord
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				return nil, err
+			}
+			records = append(records, record)
+		}
+		if err := lineScanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	hosts := make([]importedHost, 0, len(records))
+	for _, record := range records {
+		host := importedHost{Host: record.IP}
+		for _, port := range record.Ports {
+			if port.Status == "" || port.Status == "open" {
+				host.appendPort(port.Proto, port.Port)
+			}
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// parseMasscanList parses the output of masscan -oL.
+// Lines look like "open tcp 80 10.0.0.1 1556019380"
+func parseMasscanList(reader io.Reader) ([]importedHost, error) {
+	hosts := make([]importedHost, 0)
+	lineScanner := bufio.NewScanner(reader)
+	for lineScanner.Scan() {
+		line := strings.TrimSpace(lineScanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			return nil, fmt.Errorf("Malformed masscan line: %s", line)
+		}
+		if fields[0] != "open" {
+			continue
+		}
+		port, err := strconv.ParseUint(fields[2], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("Malformed port in masscan line: %s", line)
+		}
+		host := importedHost{Host: fields[3]}
+		host.appendPort(fields[1], uint16(port))
+		hosts = append(hosts, host)
+	}
+	return hosts, lineScanner.Err()
+}
+
+// parseScopeCSV parses CSV files as exported by spreadsheets. If the first row
+// contains a column named host, ip, address or target, the row is treated as header
+// and the columns port(s) and proto(col) are looked up by name. Otherwise the
+// columns are expected in the order host, ports, protocol. Ports may be separated
+// by semicolons or spaces and may carry a protocol suffix like "53/udp"
+func parseScopeCSV(reader io.Reader) ([]importedHost, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	csvReader.Comment = '#'
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	hostCol, portCol, protoCol := 0, 1, 2
+	if len(records) > 0 {
+		header := make(map[string]int)
+		for pos, column := range records[0] {
+			header[strings.ToLower(strings.TrimSpace(column))] = pos
+		}
+		for _, name := range []string{"host", "ip", "address", "target", "hostname"} {
+			if pos, ok := header[name]; ok {
+				hostCol, portCol, protoCol = pos, -1, -1
+				for _, portName := range []string{"port", "ports"} {
+					if pos, ok := header[portName]; ok {
+						portCol = pos
+					}
+				}
+				for _, protoName := range []string{"proto", "protocol"} {
+					if pos, ok := header[protoName]; ok {
+						protoCol = pos
+					}
+				}
+				records = records[1:]
+				break
+			}
+		}
+	}
+
+	hosts := make([]importedHost, 0, len(records))
+	for _, record := range records {
+		if hostCol >= len(record) || strings.TrimSpace(record[hostCol]) == "" {
+			continue
+		}
+		host := importedHost{Host: strings.TrimSpace(record[hostCol])}
+		proto := "tcp"
+		if protoCol >= 0 && protoCol < len(record) && strings.TrimSpace(record[protoCol]) != "" {
+			proto = strings.ToLower(strings.TrimSpace(record[protoCol]))
+		}
+		if portCol >= 0 && portCol < len(record) {
+			for _, rawPort := range strings.FieldsFunc(record[portCol], func(r rune) bool { return r == ';' || r == ' ' }) {
+				portProto := proto
+				if pos := strings.Index(rawPort, "/"); pos != -1 {
+					portProto = strings.ToLower(rawPort[pos+1:])
+					rawPort = rawPort[:pos]
+				}
+				ports := ParsePorts([]string{rawPort}, portProto)
+				if len(ports) == 0 {
+					return nil, fmt.Errorf("Can't parse port %s of host %s", rawPort, host.Host)
+				}
+				for _, port := range ports {
+					host.appendPort(portProto, port)
+				}
+			}
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
@@ -0,0 +1,261 @@
+package targetgeneration
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const testNmapXML = `<?xml version="1.0" encoding="UTF-8"?>
+<nmaprun scanner="nmap">
+<host><status state="up" reason="syn-ack"/>
+<address addr="10.0.0.1" addrtype="ipv4"/>
+<hostnames><hostname name="web.example.local" type="PTR"/></hostnames>
+<ports>
+<port protocol="tcp" portid="80"><state state="open" reason="syn-ack"/></port>
+<port protocol="tcp" portid="443"><state state="open" reason="syn-ack"/></port>
+<port protocol="tcp" portid="8080"><state state="closed" reason="reset"/></port>
+<port protocol="udp" portid="161"><state state="open" reason="udp-response"/></port>
+</ports>
+</host>
+<host><status state="down" reason="no-response"/>
+<address addr="10.0.0.2" addrtype="ipv4"/>
+</host>
+<host><status state="up" reason="user-set"/>
+<address addr="10.0.0.3" addrtype="ipv4"/>
+</host>
+</nmaprun>`
+
+const testMasscanJSON = `[
+{   "ip": "10.0.0.1",   "timestamp": "1556019380", "ports": [ {"port": 22, "proto": "tcp", "status": "open", "reason": "syn-ack", "ttl": 64} ] },
+{   "ip": "10.0.0.1",   "timestamp": "1556019381", "ports": [ {"port": 80, "proto": "tcp", "status": "open", "reason": "syn-ack", "ttl": 64} ] },
+{   "ip": "10.0.0.4",   "timestamp": "1556019382", "ports": [ {"port": 53, "proto": "udp", "status": "open", "reason": "none", "ttl": 64} ] },
+]`
+
+const testMasscanList = `#masscan
+open tcp 22 10.0.0.5 1556019380
+open tcp 3389 10.0.0.5 1556019381
+open udp 53 10.0.0.6 1556019382
+# end
+`
+
+const testScopeCSV = `Owner,IP,Ports,Protocol
+Finance,10.0.1.1,80;443,tcp
+Finance,10.0.1.2,53/udp 22,
+HR,intranet.example.local,,
+HR,10.0.2.0/30,,
+`
+
+func sortedPorts(ports []uint16) []uint16 {
+	sort.Slice(ports, func(i, j int) bool { return ports[i] < ports[j] })
+	return ports
+}
+
+func portsEqual(a []uint16, b []uint16) bool {
+	a = sortedPorts(a)
+	b = sortedPorts(b)
+	if len(a) != len(b) {
+		return false
+	}
+	for pos := range a {
+		if a[pos] != b[pos] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestParseNmapXML(t *testing.T) {
+	hosts, err := parseNmapXML(strings.NewReader(testNmapXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 2 {
+		t.Fatalf("Expected 2 hosts, got %d", len(hosts))
+	}
+	if hosts[0].Host != "10.0.0.1" || !portsEqual(hosts[0].TCPPorts, []uint16{80, 443}) || !portsEqual(hosts[0].UDPPorts, []uint16{161}) {
+		t.Errorf("Wrong host or ports: %+v", hosts[0])
+	}
+	if hosts[1].Host != "10.0.0.3" || len(hosts[1].TCPPorts) != 0 || len(hosts[1].UDPPorts) != 0 {
+		t.Errorf("Wrong host or ports: %+v", hosts[1])
+	}
+}
+
+func TestParseMasscan(t *testing.T) {
+	hosts, err := parseMasscanJSON([]byte(testMasscanJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(hosts))
+	}
+	if hosts[2].Host != "10.0.0.4" || !portsEqual(hosts[2].UDPPorts, []uint16{53}) {
+		t.Errorf("Wrong host or ports: %+v", hosts[2])
+	}
+
+	hosts, err = parseMasscanList(strings.NewReader(testMasscanList))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(hosts))
+	}
+	if hosts[1].Host != "10.0.0.5" || !portsEqual(hosts[1].TCPPorts, []uint16{3389}) {
+		t.Errorf("Wrong host or ports: %+v", hosts[1])
+	}
+}
+
+func TestParseScopeCSV(t *testing.T) {
+	hosts, err := parseScopeCSV(strings.NewReader(testScopeCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 4 {
+		t.Fatalf("Expected 4 hosts, got %d", len(hosts))
+	}
+	if hosts[0].Host != "10.0.1.1" || !portsEqual(hosts[0].TCPPorts, []uint16{80, 443}) {
+		t.Errorf("Wrong host or ports: %+v", hosts[0])
+	}
+	if hosts[1].Host != "10.0.1.2" || !portsEqual(hosts[1].TCPPorts, []uint16{22}) || !portsEqual(hosts[1].UDPPorts, []uint16{53}) {
+		t.Errorf("Wrong host or ports: %+v", hosts[1])
+	}
+	if hosts[2].Host != "intranet.example.local" || len(hosts[2].TCPPorts) != 0 {
+		t.Errorf("Wrong host or ports: %+v", hosts[2])
+	}
+
+	// No header, columns are host and ports
+	hosts, err = parseScopeCSV(strings.NewReader("10.0.3.1,8080\n10.0.3.2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 2 || !portsEqual(hosts[0].TCPPorts, []uint16{8080}) || hosts[1].Host != "10.0.3.2" {
+		t.Errorf("Wrong hosts: %+v", hosts)
+	}
+
+	if _, err = parseScopeCSV(strings.NewReader("10.0.3.1,notaport\n")); err == nil {
+		t.Errorf("Invalid port should result in an error")
+	}
+}
+
+func TestDetectImportFormat(t *testing.T) {
+	testcases := map[string]string{
+		testNmapXML:     importFormatNmapXML,
+		testMasscanJSON: importFormatMasscanJSON,
+		testMasscanList: importFormatMasscanList,
+		testScopeCSV:    importFormatCSV,
+	}
+	for content, expected := range testcases {
+		if format := detectImportFormat("scope.txt", []byte(content)); format != expected {
+			t.Errorf("Detected %s instead of %s", format, expected)
+		}
+	}
+	if format := detectImportFormat("results.xml", []byte("")); format != importFormatNmapXML {
+		t.Errorf("Detected %s instead of %s", format, importFormatNmapXML)
+	}
+}
+
+func TestImportTGBackend(t *testing.T) {
+	dir := t.TempDir()
+	xmlFile := filepath.Join(dir, "results.xml")
+	csvFile := filepath.Join(dir, "scope.csv")
+	if err := os.WriteFile(xmlFile, []byte(testNmapXML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(csvFile, []byte(testScopeCSV), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g := importTGBackend{}
+	g.hosts = make([]importedHost, 0)
+	for _, file := range []string{xmlFile, csvFile} {
+		hosts, err := importScopeFile(file, importFormatAuto)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.hosts = append(g.hosts, hosts...)
+	}
+	g.useHostPorts = true
+	g.tcpPorts = []uint16{22}
+	g.maxHosts = 2
+	g.maxTCPPorts = 1
+	g.maxUDPPorts = 1
+	g.maxServices = 2
+	g.blacklist = NewBlacklist()
+	g.blacklist.AddToBlacklist("10.0.2.1")
+	g.blacklist.AddToBlacklist("intranet.example.local")
+
+	scanned := make(map[string][]uint16)
+	for target := range g.receiveTargets() {
+		if uint(len(target.RemoteHosts)) > g.maxHosts || uint(len(target.TCPPorts)) > g.maxTCPPorts || uint(len(target.UDPPorts)) > g.maxUDPPorts {
+			t.Errorf("Batch limits exceeded: %+v", target)
+		}
+		if uint(len(target.Services)) > g.maxServices {
+			t.Errorf("Batch limits exceeded: %+v", target)
+		}
+		for _, host := range target.RemoteHosts {
+			for _, port := range target.TCPPorts {
+				scanned[host] = append(scanned[host], uint16(port))
+			}
+		}
+		for _, service := range target.Services {
+			if service.Protocol == "tcp" {
+				scanned[service.Host] = append(scanned[service.Host], uint16(service.Port))
+			}
+		}
+	}
+	if _, exists := scanned["10.0.2.1"]; exists {
+		t.Errorf("Blacklisted IP was scanned")
+	}
+	if _, exists := scanned["intranet.example.local"]; exists {
+		t.Errorf("Blacklisted host was scanned")
+	}
+	if !portsEqual(scanned["10.0.0.1"], []uint16{80, 443}) {
+		t.Errorf("Host specific ports were not used: %v", scanned["10.0.0.1"])
+	}
+	for _, host := range []string{"10.0.0.3", "10.0.2.0", "10.0.2.2", "10.0.2.3"} {
+		if !portsEqual(scanned[host], []uint16{22}) {
+			t.Errorf("Default ports were not used for %s: %v", host, scanned[host])
+		}
+	}
+	// 10.0.0.1: 80, 443, 161/udp; 10.0.1.1: 80, 443; 10.0.1.2: 22, 53/udp; 10.0.0.3, 10.0.2.0, 10.0.2.2 and 10.0.2.3: 22
+	if count, _ := g.targetCount(); count != 11 {
+		t.Errorf("Wrong target count: %d", count)
+	}
+}
+
+func TestBlacklistedAddressCount(t *testing.T) {
+	blacklist := NewBlacklist()
+	blacklist.AddToBlacklist("10.0.0.0/30")
+	blacklist.AddToBlacklist("10.0.0.1")
+	blacklist.AddToBlacklist("10.0.0.8")
+	blacklist.AddToBlacklist("10.0.1.0/24")
+	blacklist.AddToBlacklist("example.local")
+	for _, test := range []struct {
+		network  string
+		expected uint64
+	}{
+		{"10.0.0.0/28", 5},
+		{"10.0.0.0/31", 2},
+		{"10.0.0.16/28", 0},
+		{"10.0.1.64/26", 64},
+	} {
+		_, ipnet, _ := net.ParseCIDR(test.network)
+		if count := blacklist.blacklistedAddressCount(ipnet); count != test.expected {
+			t.Errorf("Wrong blacklisted address count for %s: %d, expected %d", test.network, count, test.expected)
+		}
+	}
+}
+
+func TestServiceTargetCount(t *testing.T) {
+	targets := AnyTargets{
+		RemoteHosts: []string{"10.0.0.1", "10.0.0.2"},
+		TCPPorts:    []uint32{80, 443},
+		UDPPorts:    []uint32{53},
+		Services:    []ServiceTarget{{Host: "10.0.0.3", Protocol: "tcp", Port: 22}},
+	}
+	if targets.TargetCount() != 7 {
+		t.Errorf("Wrong target count: %d", targets.TargetCount())
+	}
+}
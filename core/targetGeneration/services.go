@@ -0,0 +1,147 @@
+package targetgeneration
+
+// servicePort is a port a named service is commonly found on
+type servicePort struct {
+	Proto string
+	Port  uint16
+}
+
+func tcpService(ports ...uint16) []servicePort {
+	return protoPorts("tcp", ports)
+}
+
+func udpService(ports ...uint16) []servicePort {
+	return protoPorts("udp", ports)
+}
+
+func protoPorts(proto string, ports []uint16) []servicePort {
+	servicePorts := make([]servicePort, 0, len(ports))
+	for _, port := range ports {
+		servicePorts = append(servicePorts, servicePort{proto, port})
+	}
+	return servicePorts
+}
+
+func tcpUDPService(ports ...uint16) []servicePort {
+	return append(tcpService(ports...), udpService(ports...)...)
+}
+
+// serviceNames maps service names that may be used in port specifications
+// to the ports they are commonly found on. Names follow the IANA / nmap-services
+// naming, some well known aliases (e.g. smb, rdp) are added for convenience
+var serviceNames = map[string][]servicePort{
+	"ftp-data":      tcpService(20),
+	"ftp":           tcpService(21),
+	"ssh":           tcpService(22),
+	"telnet":        tcpService(23),
+	"smtp":          tcpService(25),
+	"time":          tcpUDPService(37),
+	"whois":         tcpService(43),
+	"tacacs":        tcpUDPService(49),
+	"domain":        tcpUDPService(53),
+	"dns":           tcpUDPService(53),
+	"bootps":        udpService(67),
+	"dhcp":          udpService(67),
+	"bootpc":        udpService(68),
+	"tftp":          udpService(69),
+	"gopher":        tcpService(70),
+	"finger":        tcpService(79),
+	"http":          tcpService(80),
+	"kerberos":      tcpUDPService(88),
+	"pop3":          tcpService(110),
+	"sunrpc":        tcpUDPService(111),
+	"rpcbind":       tcpUDPService(111),
+	"portmap":       tcpUDPService(111),
+	"ident":         tcpService(113),
+	"auth":          tcpService(113),
+	"nntp":          tcpService(119),
+	"ntp":           udpService(123),
+	"msrpc":         tcpService(135),
+	"epmap":         tcpUDPService(135),
+	"netbios-ns":    udpService(137),
+	"netbios-dgm":   udpService(138),
+	"netbios-ssn":   tcpService(139),
+	"imap":          tcpService(143),
+	"snmp":          udpService(161),
+	"snmptrap":      udpService(162),
+	"bgp":           tcpService(179),
+	"irc":           tcpService(194),
+	"ldap":          tcpUDPService(389),
+	"https":         tcpService(443),
+	"microsoft-ds":  tcpService(445),
+	"smb":           tcpService(139, 445),
+	"kpasswd":       tcpUDPService(464),
+	"smtps":         tcpService(465),
+	"submissions":   tcpService(465),
+	"isakmp":        udpService(500),
+	"ike":           udpService(500),
+	"rexec":         tcpService(512),
+	"exec":          tcpService(512),
+	"rlogin":        tcpService(513),
+	"login":         tcpService(513),
+	"rsh":           tcpService(514),
+	"shell":         tcpService(514),
+	"syslog":        udpService(514),
+	"printer":       tcpService(515),
+	"lpd":           tcpService(515),
+	"rip":           udpService(520),
+	"afp":           tcpService(548),
+	"rtsp":          tcpUDPService(554),
+	"submission":    tcpService(587),
+	"ipp":           tcpUDPService(631),
+	"ldaps":         tcpService(636),
+	"rsync":         tcpService(873),
+	"vmware-auth":   tcpService(902),
+	"ftps":          tcpService(990),
+	"imaps":         tcpService(993),
+	"pop3s":         tcpService(995),
+	"socks":         tcpService(1080),
+	"openvpn":       tcpUDPService(1194),
+	"ms-sql-s":      tcpService(1433),
+	"mssql":         tcpService(1433),
+	"ms-sql-m":      udpService(1434),
+	"oracle":        tcpService(1521),
+	"pptp":          tcpService(1723),
+	"radius":        udpService(1812),
+	"radius-acct":   udpService(1813),
+	"mqtt":          tcpService(1883),
+	"upnp":          udpService(1900),
+	"ssdp":          udpService(1900),
+	"nfs":           tcpUDPService(2049),
+	"docker":        tcpService(2375),
+	"docker-s":      tcpService(2376),
+	"etcd":          tcpService(2379),
+	"squid-http":    tcpService(3128),
+	"http-proxy":    tcpService(3128, 8080),
+	"globalcatldap": tcpService(3268),
+	"mysql":         tcpService(3306),
+	"ms-wbt-server": tcpService(3389),
+	"rdp":           tcpService(3389),
+	"svn":           tcpService(3690),
+	"ipsec-nat-t":   udpService(4500),
+	"sip":           tcpUDPService(5060),
+	"sips":          tcpService(5061),
+	"xmpp-client":   tcpService(5222),
+	"mdns":          udpService(5353),
+	"llmnr":         udpService(5355),
+	"postgresql":    tcpService(5432),
+	"postgres":      tcpService(5432),
+	"amqp":          tcpService(5672),
+	"vnc":           tcpService(5900),
+	"couchdb":       tcpService(5984),
+	"winrm":         tcpService(5985, 5986),
+	"wsman":         tcpService(5985),
+	"wsmans":        tcpService(5986),
+	"x11":           tcpService(6000),
+	"redis":         tcpService(6379),
+	"kubernetes":    tcpService(6443),
+	"irc-alt":       tcpService(6667),
+	"cassandra":     tcpService(9042),
+	"http-alt":      tcpService(8080),
+	"https-alt":     tcpService(8443),
+	"ajp13":         tcpService(8009),
+	"jetdirect":     tcpService(9100),
+	"elasticsearch": tcpService(9200),
+	"memcached":     tcpUDPService(11211),
+	"mongodb":       tcpService(27017),
+}
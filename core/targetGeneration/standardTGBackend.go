@@ -26,6 +26,7 @@ type standardTGBackend struct {
 	maxTCPPorts    uint
 	maxUDPPorts    uint
 	blacklist      *NrayBlacklist
+	resolver       *utils.Resolver
 }
 
 // Configure is called to set up the generator
@@ -57,28 +58,14 @@ func (generator *standardTGBackend) configure(conf *viper.Viper) error {
 		}
 	}
 
-	generator.blacklist = NewBlacklist()
-	for _, blacklistItem := range conf.GetStringSlice("blacklist") {
-		_ = generator.blacklist.AddToBlacklist(blacklistItem)
-	}
-	if conf.IsSet("blacklistFile") && strings.Trim(conf.GetString("blacklistFile"), " ") != "" {
-		file, err := os.Open(conf.GetString("blacklistFile"))
-		utils.CheckError(err, false)
-		defer file.Close()
+	generator.blacklist = blacklistFromConfig(conf)
 
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			line := strings.TrimSpace(scanner.Text())
-			if line != "" {
-				_ = generator.blacklist.AddToBlacklist(line)
-			}
-		}
-		err = scanner.Err()
-		utils.CheckError(err, false)
+	tcpPorts, udpPorts, err := portsFromConfig(conf)
+	if err != nil {
+		return err
 	}
-
-	generator.tcpPorts = ParsePorts(conf.GetStringSlice("tcpports"), "tcp")
-	generator.udpPorts = ParsePorts(conf.GetStringSlice("udpports"), "udp")
+	generator.tcpPorts = tcpPorts
+	generator.udpPorts = udpPorts
 
 	// Count targets
 	for _, rawTarget := range generator.rawTargets {
@@ -91,7 +78,12 @@ func (generator *standardTGBackend) configure(conf *viper.Viper) error {
 		} else if utils.Ipv4Regexpr.MatchString(rawTarget) { // An IPv4 address
 			generator.rawTargetCount++
 		} else if utils.MayBeFQDN(rawTarget) { // Probably a FQDN
-			generator.rawTargetCount++
+			if generator.resolver != nil && !generator.blacklist.IsDNSNameBlacklisted(rawTarget) {
+				// A name may resolve to multiple addresses, each of them is scanned
+				generator.rawTargetCount += uint64(len(resolveTarget(generator.resolver, generator.blacklist, rawTarget)))
+			} else {
+				generator.rawTargetCount++
+			}
 		} else {
 		}
 	}
@@ -104,9 +96,9 @@ func (generator *standardTGBackend) receiveTargets() <-chan AnyTargets {
 	resultChan := make(chan AnyTargets, 10) // Keeping 10 Targets waiting should be sufficient
 
 	// All targets are sent over this channel
-	targets := make(chan string, 50)
+	targets := make(chan hostTarget, 50)
 	// Decides if input is an IP, net or domain and fills the target channel with target strings
-	go func(targetChan chan<- string, rawTargets []string) {
+	go func(targetChan chan<- hostTarget, rawTargets []string) {
 		for _, rawTarget := range rawTargets {
 			if rawTarget == "" {
 				continue
@@ -115,15 +107,22 @@ func (generator *standardTGBackend) receiveTargets() <-chan AnyTargets {
 				utils.CheckError(err, true)
 				ipStream := GenerateIPStreamFromCIDR(ipnet, generator.blacklist)
 				for ip := range ipStream {
-					targets <- ip.String()
+					targets <- hostTarget{Address: ip.String()}
 				}
 			} else if utils.Ipv4Regexpr.MatchString(rawTarget) { // An IPv4 address
 				if !generator.blacklist.IsIPBlacklisted(rawTarget) {
-					targets <- rawTarget
+					targets <- hostTarget{Address: rawTarget}
 				}
 			} else if utils.MayBeFQDN(rawTarget) { // Probably a FQDN
-				if !generator.blacklist.IsDNSNameBlacklisted(rawTarget) {
-					targets <- rawTarget
+				if generator.blacklist.IsDNSNameBlacklisted(rawTarget) {
+					continue
+				}
+				if generator.resolver == nil {
+					targets <- hostTarget{Address: rawTarget}
+					continue
+				}
+				for _, resolved := range resolveTarget(generator.resolver, generator.blacklist, rawTarget) {
+					targets <- resolved
 				}
 			} else {
 				log.WithFields(log.Fields{
@@ -145,21 +144,19 @@ func (generator *standardTGBackend) receiveTargets() <-chan AnyTargets {
 	//     maxTcp/maxUdpPorts or streams are closed (done in chunkPorts())
 	//     6. send the AnyTarget back
 	// 7. When the host generator is done, close the stream
-	go func(resultChan chan<- AnyTargets, targets <-chan string) {
+	go func(resultChan chan<- AnyTargets, targets <-chan hostTarget) {
 		var stop bool
+		var pending *hostTarget
 		for !stop {
 			// Get the hosts
-			hosts := make([]string, 0)
-
-			for i := uint(0); i < generator.maxHosts; i++ {
-				elem, ok := <-targets
-				if !ok { // We're done, set stop mark, process remaining hosts and stop
-					stop = true
-					break
-				}
-				hosts = append(hosts, elem)
+			var hosts []string
+			var hostnames map[string]string
+			hosts, hostnames, pending, stop = nextHostBatch(targets, generator.maxHosts, pending)
+			if len(hosts) == 0 { // Don't send batches without any hosts
+				continue
 			}
 			for _, target := range chunkPorts(hosts, generator.tcpPorts, generator.udpPorts, generator.maxTCPPorts, generator.maxUDPPorts) {
+				target.Hostnames = hostnames
 				resultChan <- target
 			}
 		}
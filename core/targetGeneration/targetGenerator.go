@@ -1,12 +1,8 @@
 package targetgeneration
 
 import (
-	"math"
 	"math/rand"
 	"net"
-	"regexp"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/apparentlymart/go-cidr/cidr"
@@ -17,16 +13,29 @@ import (
 
 // AnyTargets is the most abstract type holding information
 // regarding targets. Any number of hosts, networks, ports etc.
-// is allowed
+// is allowed. Every host in RemoteHosts is scanned on every port
+// in TCPPorts and UDPPorts, Services are scanned as they are
 type AnyTargets struct {
 	RemoteHosts []string
 	TCPPorts    []uint32
 	UDPPorts    []uint32
+	Services    []ServiceTarget
+	// Hostnames maps resolved addresses to the DNS names they were resolved from
+	Hostnames map[string]string
+}
+
+// ServiceTarget is a single port on a single host. It is used by backends
+// that know exactly which services should be scanned
+type ServiceTarget struct {
+	Host     string
+	Protocol string
+	Port     uint32
+	Hostname string
 }
 
 // TargetCount returns the number of targets, meaning individual ports on individual systems
 func (at *AnyTargets) TargetCount() uint64 {
-	return uint64(len(at.RemoteHosts) * (len(at.TCPPorts) + len(at.UDPPorts)))
+	return uint64(len(at.RemoteHosts)*(len(at.TCPPorts)+len(at.UDPPorts)) + len(at.Services))
 }
 
 // TargetGenerator is the type that unifies all backends and provides
@@ -35,6 +44,7 @@ type TargetGenerator struct {
 	targetChannels []<-chan AnyTargets
 	targetChan     chan AnyTargets
 	targetCount    uint64
+	backends       []targetGeneratorBackend
 }
 
 // Init takes the target generation subtree of the configuration
@@ -42,14 +52,37 @@ type TargetGenerator struct {
 func (tg *TargetGenerator) Init(config *viper.Viper) {
 	tg.targetChan = make(chan AnyTargets, config.GetInt("buffersize"))
 
-	backend := &standardTGBackend{}
-	// Supply config
-	err := backend.configure(config.Sub("standard"))
-	utils.CheckError(err, true)
-	tg.targetCount, err = backend.targetCount()
-	utils.CheckError(err, false)
-	// Append channel to slice holding all channels that are sending work
-	tg.targetChannels = append(tg.targetChannels, backend.receiveTargets())
+	// Top lists are built from the embedded nmap lists unless a services file is configured
+	if topPortsFile := config.GetString("topPortsFile"); topPortsFile != "" {
+		ranking, err := LoadPortRanking(topPortsFile)
+		utils.CheckError(err, true)
+		SetPortRanking(ranking)
+	}
+
+	// DNS names are resolved by the server if enabled, so the blacklist
+	// can be applied to the addresses that are actually scanned
+	resolver := newResolverFromConfig(config.Sub("resolve"))
+	backends := map[string]targetGeneratorBackend{
+		"standard": &standardTGBackend{resolver: resolver},
+	}
+	if config.GetBool("import.enabled") {
+		backends["import"] = &importTGBackend{resolver: resolver}
+	}
+	for _, name := range []string{"standard", "import"} {
+		backend, enabled := backends[name]
+		if !enabled {
+			continue
+		}
+		// Supply config
+		err := backend.configure(config.Sub(name))
+		utils.CheckError(err, true)
+		count, err := backend.targetCount()
+		utils.CheckError(err, false)
+		tg.targetCount += count
+		tg.backends = append(tg.backends, backend)
+		// Append channel to slice holding all channels that are sending work
+		tg.targetChannels = append(tg.targetChannels, backend.receiveTargets())
+	}
 	go tg.zipChannels()
 }
 
@@ -95,35 +128,19 @@ type targetGeneratorBackend interface {
 	configure(*viper.Viper) error
 	receiveTargets() <-chan AnyTargets
 	targetCount() (uint64, error)
-}
-
-// Taken from https://www.rosettacode.org/wiki/Remove_duplicate_elements#Map_solution
-func uniq(list []uint16) []uint16 {
-	uniqueSet := make(map[uint16]bool, len(list))
-	for _, x := range list {
-		uniqueSet[x] = true
-	}
-	result := make([]uint16, 0, len(uniqueSet))
-	for x := range uniqueSet {
-		result = append(result, x)
-	}
-	return result
+	scopeIssues() []ScopeIssue
 }
 
 // GetNmapTopTCPPorts returns an array containing the topN TCP ports
+// of the current port ranking
 func GetNmapTopTCPPorts(topN uint) []uint16 {
-	if topN > uint(len(TopTCPPorts)) {
-		topN = uint(len(TopTCPPorts))
-	}
-	return TopTCPPorts[0:uint(topN)]
+	return currentPortRanking().Top("tcp", topN)
 }
 
 // GetNmapTopUDPPorts returns an array containing the topN UDP ports
+// of the current port ranking
 func GetNmapTopUDPPorts(topN uint) []uint16 {
-	if topN > uint(len(TopUDPPorts)) {
-		topN = uint(len(TopUDPPorts))
-	}
-	return TopUDPPorts[0:uint(topN)]
+	return currentPortRanking().Top("udp", topN)
 }
 
 // GenerateIPStreamFromCIDR uses the ZMap algorithm to expand a CIDR network.
@@ -201,65 +218,24 @@ func GeneratePortStream(ports []uint16) <-chan uint16 {
 	return returnChan
 }
 
-// ParsePorts takes the a list of target strings supplied by the user
-// and tries to parse them into a slice of uint32s
-// Errors are sent back over errorChan
+// ParsePorts takes the a list of port specifications supplied by the user
+// and parses them into a slice of ports of the given protocol.
+// Invalid entries are skipped with a warning, use ParsePortSpec
+// to treat them as errors
 func ParsePorts(rawPorts []string, proto string) []uint16 {
-	ports := make([]uint16, 0)
-	portRangeRegexpr := regexp.MustCompile(utils.RegexPortRange)
-	topPortsRegexpr := regexp.MustCompile(utils.RegexTopPorts)
-	thousandNumberRegexp := regexp.MustCompile(utils.RegexThousandNumber)
+	valid := make([]string, 0, len(rawPorts))
 	for _, candidate := range rawPorts {
-		// A single port
-		parsed, err := strconv.ParseUint(candidate, 10, 32)
-		if err == nil && parsed < math.MaxUint16 {
-			ports = append(ports, uint16(parsed))
-			continue
-		} else if portRangeRegexpr.MatchString(candidate) { // A port range. Split, sort, flatten.
-			splitted := strings.Split(candidate, "-")
-			first, err := strconv.ParseUint(splitted[0], 10, 32)
-			if err == nil && first <= math.MaxUint16 {
-				second, err := strconv.ParseUint(splitted[1], 10, 32)
-				if err == nil && second <= math.MaxUint16 {
-					var start, end uint16
-					if first <= second {
-						start = uint16(first)
-						end = uint16(second)
-					} else {
-						start = uint16(second)
-						end = uint16(first)
-					}
-					for i := start; i <= end; i++ {
-						ports = append(ports, i)
-						if i == math.MaxUint16 { // Otherwise there is a nasty overflow causing a memory leak until you get killed by OOM
-							break
-						}
-					}
-					continue
-				}
-			}
-		} else if topPortsRegexpr.MatchString(candidate) { // A toplist
-			topN, err := strconv.ParseUint(thousandNumberRegexp.FindString(candidate), 10, 32)
-			utils.CheckError(err, true)
-			if proto == "udp" {
-				ports = append(ports, GetNmapTopUDPPorts(uint(topN))...)
-			} else {
-				ports = append(ports, GetNmapTopTCPPorts(uint(topN))...)
-			}
-			continue
-		} else if candidate == "all" {
-			for i := uint16(1); i <= math.MaxUint16; i++ {
-				ports = append(ports, i)
-				if i == math.MaxUint16 { // Otherwise there is a nasty overflow causing a memory leak until you get killed by OOM
-					break
-				}
-			}
-		} else {
+		if _, _, err := ParsePortSpec([]string{candidate}, proto); err != nil {
 			log.Warningf("Can't parse port list %s, skipping.", candidate)
+			continue
 		}
+		valid = append(valid, candidate)
 	}
-
-	return uniq(ports)
+	tcpPorts, udpPorts, _ := ParsePortSpec(valid, proto)
+	if proto == "udp" {
+		return udpPorts
+	}
+	return tcpPorts
 }
 
 // chunkPorts creates a slice of AnyTargets that contain all provided hosts with the specified port chunkings
@@ -0,0 +1,64 @@
+package targetgeneration
+
+// TargetSummary describes everything a target generator produces.
+// It is used to inspect the scope before a scan is started
+type TargetSummary struct {
+	Hosts         []string     `json:"hosts,omitempty"`
+	HostCount     uint64       `json:"hostCount"`
+	TCPPorts      []uint16     `json:"tcpPorts"`
+	UDPPorts      []uint16     `json:"udpPorts"`
+	BatchCount    uint64       `json:"batchCount"`
+	TargetCount   uint64       `json:"targetCount"`
+	ExpectedCount uint64       `json:"expectedCount"`
+	Issues        []ScopeIssue `json:"issues"`
+}
+
+// Summarize consumes all targets of the generator, so it can't be used
+// for scanning afterwards. Hosts are only kept if collectHosts is set.
+// TargetCount is the number of targets actually generated, ExpectedCount
+// the number estimated up front that is used for progress reporting
+func (tg *TargetGenerator) Summarize(collectHosts bool) TargetSummary {
+	summary := TargetSummary{
+		Hosts:         make([]string, 0),
+		ExpectedCount: tg.TargetCount(),
+		Issues:        tg.ScopeIssues(),
+	}
+	seenHosts := make(map[string]bool)
+	tcpPorts := make(map[uint16]bool)
+	udpPorts := make(map[uint16]bool)
+	addHost := func(host string) {
+		if seenHosts[host] {
+			return
+		}
+		seenHosts[host] = true
+		summary.HostCount++
+		if collectHosts {
+			summary.Hosts = append(summary.Hosts, host)
+		}
+	}
+	for targets := range tg.GetTargetChan() {
+		summary.BatchCount++
+		summary.TargetCount += targets.TargetCount()
+		for _, host := range targets.RemoteHosts {
+			addHost(host)
+		}
+		for _, port := range targets.TCPPorts {
+			tcpPorts[uint16(port)] = true
+		}
+		for _, port := range targets.UDPPorts {
+			udpPorts[uint16(port)] = true
+		}
+		for _, service := range targets.Services {
+			addHost(service.Host)
+			switch service.Protocol {
+			case "tcp":
+				tcpPorts[uint16(service.Port)] = true
+			case "udp":
+				udpPorts[uint16(service.Port)] = true
+			}
+		}
+	}
+	summary.TCPPorts = sortedPortSet(tcpPorts)
+	summary.UDPPorts = sortedPortSet(udpPorts)
+	return summary
+}
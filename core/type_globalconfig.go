@@ -16,6 +16,15 @@ type GlobalConfig struct {
 	TLSConfig     *tls.Config
 	Pools         []*Pool
 	EventHandlers []events.EventHandler
+	// Number of scanner nodes started in the server process
+	LocalNodes int
+	// Global rate limits in probes per second, 0 if unlimited
+	RatelimitTotal   float64
+	RatelimitPerPool float64
+	// Time windows scanning is allowed in, nil if there is no restriction
+	Schedule *scanSchedule
+	// Adds DNS and MMDB data to results, nil if disabled
+	Enricher *enricher
 }
 
 // Returns a pointer to the node with the given ID
@@ -68,8 +77,22 @@ func (gc GlobalConfig) LogEvents(events []*nraySchema.Event) {
 	}
 }
 
+// enrichAndLogEvents sends events to the enricher, which logs them afterwards.
+// Without enricher, they are logged right away
+func (gc GlobalConfig) enrichAndLogEvents(events []*nraySchema.Event) {
+	if gc.Enricher == nil {
+		gc.LogEvents(events)
+		return
+	}
+	gc.Enricher.submit(events)
+}
+
 // CloseEventHandlers calls Close() on all registered event handlers
+// after all events waiting for enrichment are logged
 func (gc GlobalConfig) CloseEventHandlers() {
+	if gc.Enricher != nil {
+		gc.Enricher.close()
+	}
 	for _, eventHandler := range gc.EventHandlers {
 		eventHandler.Close()
 	}
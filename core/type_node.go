@@ -18,6 +18,11 @@ type Node struct {
 	scanPaused    bool
 	stopNode      bool
 	stopLock      sync.RWMutex
+	// Share of the global rate limits the node was last sent. A node waiting
+	// for a share gets no work until one is free
+	ratelimit        float64
+	ratelimitWaiting bool
+	ratelimitLock    sync.Mutex
 }
 
 func (node *Node) setStop(value bool) {
@@ -31,3 +36,16 @@ func (node *Node) getStop() bool {
 	defer node.stopLock.Unlock()
 	return node.stopNode
 }
+
+func (node *Node) setRatelimit(share float64, waiting bool) {
+	node.ratelimitLock.Lock()
+	defer node.ratelimitLock.Unlock()
+	node.ratelimit = share
+	node.ratelimitWaiting = waiting
+}
+
+func (node *Node) getRatelimit() (float64, bool) {
+	node.ratelimitLock.Lock()
+	defer node.ratelimitLock.Unlock()
+	return node.ratelimit, node.ratelimitWaiting
+}
@@ -307,6 +307,12 @@ func (p *Pool) printProgress(pause time.Duration) {
 			"module": "core.type_pool",
 			"src":    "printProgress",
 		}).Infof("All: %d; TODO: %d; Done: %d (%.2f%%)", all, all-done, done, ratio*100)
+		if CurrentConfig.Schedule != nil {
+			log.WithFields(log.Fields{
+				"module": "core.type_pool",
+				"src":    "printProgress",
+			}).Infof("Schedule: %s", CurrentConfig.Schedule)
+		}
 	}
 }
 
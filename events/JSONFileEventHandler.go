@@ -18,6 +18,7 @@ type JSONFileEventHandler struct {
 	filedescriptor *os.File
 	eventChan      chan string
 	flushChan      chan bool
+	writerDone     chan struct{}
 	eventFilter    map[string]interface{}
 	waitgroup      sync.WaitGroup
 }
@@ -44,7 +45,7 @@ func (handler *JSONFileEventHandler) Configure(config *viper.Viper) error {
 	}).Debugf("Opening file: %s", config.GetString("filename"))
 	mode := os.O_RDWR | os.O_CREATE | os.O_EXCL
 	if config.GetBool("overwriteExisting") {
-		mode = os.O_RDWR | os.O_CREATE
+		mode = os.O_RDWR | os.O_CREATE | os.O_TRUNC
 	}
 	handler.filedescriptor, err = os.OpenFile(config.GetString("filename"), mode, 0644)
 	if err != nil {
@@ -60,6 +61,7 @@ func (handler *JSONFileEventHandler) Configure(config *viper.Viper) error {
 	}).Debugf("Event channel size is going to be %d", config.GetInt("internal.channelsize"))
 	handler.eventChan = make(chan string, config.GetInt("internal.channelsize"))
 	handler.flushChan = make(chan bool)
+	handler.writerDone = make(chan struct{})
 	handler.eventFilter = config.GetStringMap("filter")
 	log.WithFields(log.Fields{
 		"module": "events.JSONFileEventHandler",
@@ -73,8 +75,8 @@ func (handler *JSONFileEventHandler) Configure(config *viper.Viper) error {
 // ProcessEvents takes a pointer to an array with events and passes them
 // to the internal processing
 func (handler *JSONFileEventHandler) ProcessEvents(events []*nraySchema.Event) {
+	handler.waitgroup.Add(1)
 	go func(events []*nraySchema.Event) {
-		handler.waitgroup.Add(1)
 		for _, event := range events {
 			serialized, err := protomarshaller.MarshalToString(event)
 			utils.CheckError(err, false)
@@ -110,6 +112,7 @@ func (handler *JSONFileEventHandler) Close() error {
 		time.Sleep(1 * time.Second)
 	}
 	close(handler.eventChan)
+	<-handler.writerDone
 
 	err := handler.filedescriptor.Close()
 	return err
@@ -139,6 +142,7 @@ func (handler *JSONFileEventHandler) startEventWriter() {
 				handler.filedescriptor.Write([]byte{'\n'})
 			} else {
 				handler.filedescriptor.Write([]byte{'\n'})
+				close(handler.writerDone)
 				return
 			}
 		case <-handler.flushChan:
@@ -1,6 +1,8 @@
 package events
 
 import (
+	"sync"
+
 	nraySchema "github.com/nray-scanner/nray/schemas"
 	"github.com/nray-scanner/nray/utils"
 
@@ -12,6 +14,7 @@ import (
 type TerminalEventHandler struct {
 	eventChan   chan string
 	eventFilter map[string]interface{}
+	pending     sync.WaitGroup
 }
 
 // Configure does currently nothing as there is nothing to configure yet
@@ -29,6 +32,7 @@ func (t *TerminalEventHandler) Configure(config *viper.Viper) error {
 
 // ProcessEvents logs all events to stdout
 func (t *TerminalEventHandler) ProcessEvents(events []*nraySchema.Event) {
+	t.pending.Add(len(events))
 	go func(events []*nraySchema.Event) {
 		for _, event := range events {
 			serialized, err := protomarshaller.MarshalToString(event)
@@ -47,12 +51,14 @@ func (t *TerminalEventHandler) ProcessEventStream(eventStream <-chan *nraySchema
 	for event := range eventStream {
 		serialized, err := protomarshaller.MarshalToString(event)
 		utils.CheckError(err, false)
+		t.pending.Add(1)
 		t.eventChan <- string(serialized)
 	}
 }
 
-// Close has to do nothing since no output channel has to be closed
+// Close waits until all events passed so far are printed
 func (t *TerminalEventHandler) Close() error {
+	t.pending.Wait()
 	return nil
 }
 
@@ -65,6 +71,7 @@ func (t *TerminalEventHandler) startEventPrinter() {
 		event, more := <-t.eventChan
 		if more {
 			log.Infof("Event: %s", event)
+			t.pending.Done()
 		} else {
 			return
 		}
@@ -9,11 +9,15 @@ require (
 	github.com/denisbrodbeck/machineid v1.0.1
 	github.com/golang/protobuf v1.5.4
 	github.com/golang/time v0.12.0
+	github.com/oschwald/maxminddb-golang v1.13.1
 	github.com/shirou/gopsutil v3.21.11+incompatible
 	github.com/sirupsen/logrus v1.9.3
 	github.com/spf13/cobra v1.9.1
+	github.com/spf13/pflag v1.0.6
 	github.com/spf13/viper v1.20.1
 	github.com/zmap/go-iptree v0.0.0-20210731043055-d4e632617837
+	golang.org/x/crypto v0.39.0
+	golang.org/x/net v0.41.0
 	nanomsg.org/go/mangos/v2 v2.0.8
 )
 
@@ -30,7 +34,6 @@ require (
 	github.com/sourcegraph/conc v0.3.0 // indirect
 	github.com/spf13/afero v1.14.0 // indirect
 	github.com/spf13/cast v1.9.2 // indirect
-	github.com/spf13/pflag v1.0.6 // indirect
 	github.com/subosito/gotenv v1.6.0 // indirect
 	github.com/tklauser/go-sysconf v0.3.15 // indirect
 	github.com/tklauser/numcpus v0.10.0 // indirect
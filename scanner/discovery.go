@@ -0,0 +1,292 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	nraySchema "github.com/nray-scanner/nray/schemas"
+	"github.com/nray-scanner/nray/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+// hostDiscovery checks which hosts of a batch are alive before their ports are scanned
+type hostDiscovery struct {
+	enabled  bool
+	icmp     bool
+	tcpPorts []uint32
+	ackPorts []uint32
+	udpPorts []uint32
+	timeout  time.Duration
+	source   *sourceBinding
+	// Used for TCP pings, so they go through proxies as well
+	dial     dialFunc
+	resolver *nodeResolver
+}
+
+// newHostDiscovery reads the discovery configuration
+func newHostDiscovery(config *viper.Viper) *hostDiscovery {
+	config = utils.ApplyDefaultScannerDiscoveryConfig(config)
+	discovery := &hostDiscovery{
+		enabled: config.GetBool("enabled"),
+		icmp:    config.GetBool("icmp"),
+		timeout: config.GetDuration("timeout"),
+	}
+	for _, port := range config.GetIntSlice("tcpPorts") {
+		discovery.tcpPorts = append(discovery.tcpPorts, uint32(port))
+	}
+	for _, port := range config.GetIntSlice("tcpAckPorts") {
+		discovery.ackPorts = append(discovery.ackPorts, uint32(port))
+	}
+	for _, port := range config.GetIntSlice("udpPorts") {
+		discovery.udpPorts = append(discovery.udpPorts, uint32(port))
+	}
+	return discovery
+}
+
+// pingResult is the answer to a single discovery probe
+type pingResult struct {
+	method  string
+	rtt     time.Duration
+	up      bool
+	address string
+}
+
+// probeCount returns how many probes are sent to each host
+func (discovery *hostDiscovery) probeCount() int {
+	probes := len(discovery.tcpPorts) + len(discovery.ackPorts) + len(discovery.udpPorts)
+	if discovery.icmp {
+		probes++
+	}
+	return probes
+}
+
+// probe sends all discovery probes to host at once and returns the first answer.
+// Any answer counts, even if it says that a port is closed
+func (discovery *hostDiscovery) probe(host string) pingResult {
+	address, err := discovery.resolver.resolve(host)
+	if err != nil {
+		return pingResult{}
+	}
+	answers := make(chan pingResult, discovery.probeCount())
+	probes := 0
+	if discovery.icmp {
+		probes++
+		go func() { answers <- icmpEcho(address, discovery.timeout, discovery.source) }()
+	}
+	for _, port := range discovery.tcpPorts {
+		probes++
+		go func(port uint32) { answers <- tcpPing(host, port, discovery.timeout, discovery.dial) }(port)
+	}
+	for _, port := range discovery.ackPorts {
+		probes++
+		go func(port uint32) { answers <- discovery.ackPing(host, address, port) }(port)
+	}
+	for _, port := range discovery.udpPorts {
+		probes++
+		go func(port uint32) { answers <- udpPing(address, port, discovery.timeout, discovery.source) }(port)
+	}
+	for i := 0; i < probes; i++ {
+		if answer := <-answers; answer.up {
+			answer.address = address
+			return answer
+		}
+	}
+	return pingResult{address: address}
+}
+
+// tcpPing connects to a port. An accepted or refused connection means the host is up
+func tcpPing(host string, port uint32, timeout time.Duration, dial dialFunc) pingResult {
+	if dial == nil {
+		dial = net.DialTimeout
+	}
+	start := time.Now()
+	conn, err := dial("tcp", net.JoinHostPort(host, fmt.Sprint(port)), timeout)
+	if err == nil {
+		conn.Close()
+	}
+	up := err == nil || isRefused(err)
+	return pingResult{method: fmt.Sprintf("tcp/%d", port), rtt: time.Since(start), up: up}
+}
+
+// ackPing sends a TCP ACK ping. If that is not possible, e.g. without CAP_NET_RAW
+// or for IPv6 addresses, a connect ping is sent instead
+func (discovery *hostDiscovery) ackPing(host string, address string, port uint32) pingResult {
+	result, err := ackPing(address, port, discovery.timeout, discovery.source)
+	if err == nil {
+		return result
+	}
+	log.WithFields(log.Fields{
+		"module": "scanner.discovery",
+		"src":    "ackPing",
+	}).Debugf("ACK ping to %s is not possible, sending a connect ping: %v", address, err)
+	return tcpPing(host, port, discovery.timeout, discovery.dial)
+}
+
+// udpPing sends an empty datagram to a port. Any response, including ICMP port
+// unreachable, means the host is up
+func udpPing(host string, port uint32, timeout time.Duration, source *sourceBinding) pingResult {
+	conn, err := source.dial("udp", net.JoinHostPort(host, fmt.Sprint(port)), timeout)
+	if err != nil {
+		return pingResult{}
+	}
+	defer conn.Close()
+	start := time.Now()
+	conn.SetDeadline(start.Add(timeout))
+	if _, err := conn.Write([]byte{}); err != nil {
+		return pingResult{}
+	}
+	_, err = conn.Read(make([]byte, 1))
+	up := err == nil || errors.Is(err, syscall.ECONNREFUSED)
+	return pingResult{method: fmt.Sprintf("udp/%d", port), rtt: time.Since(start), up: up}
+}
+
+// icmpEcho sends an ICMP echo request. Raw sockets are used if permitted,
+// otherwise unprivileged ping sockets. If neither is allowed, no probe is sent.
+// Of the source, only the IP address is used
+func icmpEcho(host string, timeout time.Duration, source *sourceBinding) pingResult {
+	address, err := net.ResolveIPAddr("ip4", host)
+	if err != nil {
+		return pingResult{}
+	}
+	listenAddress := "0.0.0.0"
+	if ip := source.ipv4(); ip != nil {
+		listenAddress = ip.String()
+	}
+	privileged := true
+	conn, err := icmp.ListenPacket("ip4:icmp", listenAddress)
+	if err != nil {
+		privileged = false
+		if conn, err = icmp.ListenPacket("udp4", listenAddress); err != nil {
+			log.WithFields(log.Fields{
+				"module": "scanner.discovery",
+				"src":    "icmpEcho",
+			}).Debugf("ICMP echo is not permitted: %v", err)
+			return pingResult{}
+		}
+	}
+	defer conn.Close()
+
+	// Not cryptographically relevant, so seeding with time should be OK
+	id := rand.New(rand.NewSource(time.Now().UnixNano())).Intn(0xffff)
+	request, err := (&icmp.Message{
+		Type: ipv4.ICMPTypeEcho,
+		Body: &icmp.Echo{ID: id, Seq: 1, Data: []byte("nray")},
+	}).Marshal(nil)
+	if err != nil {
+		return pingResult{}
+	}
+	var destination net.Addr = address
+	if !privileged {
+		destination = &net.UDPAddr{IP: address.IP}
+	}
+	start := time.Now()
+	conn.SetDeadline(start.Add(timeout))
+	if _, err := conn.WriteTo(request, destination); err != nil {
+		return pingResult{}
+	}
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			return pingResult{}
+		}
+		reply, err := icmp.ParseMessage(1, buf[:n])
+		if err != nil || reply.Type != ipv4.ICMPTypeEchoReply {
+			continue
+		}
+		echo, ok := reply.Body.(*icmp.Echo)
+		// Raw sockets see all replies, the kernel sets the ID of unprivileged ones
+		if !ok || echo.Seq != 1 || (privileged && echo.ID != id) {
+			continue
+		}
+		var peerIP net.IP
+		switch peer := peer.(type) {
+		case *net.IPAddr:
+			peerIP = peer.IP
+		case *net.UDPAddr:
+			peerIP = peer.IP
+		}
+		if peerIP.Equal(address.IP) {
+			return pingResult{method: "icmp-echo", rtt: time.Since(start), up: true}
+		}
+	}
+}
+
+// discoverHosts probes all hosts of the batch and reports if they are up.
+// The returned batch only contains the hosts that are up, services are always kept.
+// The batch must already be limited to the node's scope
+func (controller *ScanController) discoverHosts(workBatch *nraySchema.MoreWorkReply) *nraySchema.MoreWorkReply {
+	hosts := workBatch.GetTargets().GetRhosts()
+	if !controller.discovery.enabled || len(hosts) == 0 {
+		return workBatch
+	}
+	up := make([]bool, len(hosts))
+	var wg sync.WaitGroup
+	// Like the workers, this limits how many hosts are probed at once
+	slots := make(chan struct{}, controller.scannerConfig.GetInt("workers"))
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			// Waiting for the politeness limits of one host must not hold a slot
+			release := controller.politeness.acquire(host, controller.discovery.probeCount())
+			defer release()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			for i := 0; i < controller.discovery.probeCount(); i++ {
+				controller.ratelimiter.Wait(context.TODO())
+			}
+			result := controller.discovery.probe(host)
+			up[i] = result.up
+			controller.reportHost(host, result)
+		}(i, host)
+	}
+	wg.Wait()
+
+	live := make([]string, 0, len(hosts))
+	for i, host := range hosts {
+		if up[i] {
+			live = append(live, host)
+		}
+	}
+	log.WithFields(log.Fields{
+		"module": "scanner.discovery",
+		"src":    "discoverHosts",
+	}).Debugf("%d of %d hosts of batch %d are up", len(live), len(hosts), workBatch.Batchid)
+	targets := proto.Clone(workBatch.Targets).(*nraySchema.ScanTargets)
+	targets.Rhosts = live
+	return &nraySchema.MoreWorkReply{Batchid: workBatch.Batchid, Targets: targets}
+}
+
+// reportHost creates the event telling if a host is up
+func (controller *ScanController) reportHost(host string, result pingResult) {
+	timestamp, _ := ptypes.TimestampProto(currentTime())
+	hostResult := &nraySchema.HostDiscoveryResult{
+		Target:   host,
+		Hostname: controller.Hostname(host),
+		Up:       result.up,
+		Address:  result.address,
+	}
+	if result.up {
+		hostResult.Method = result.method
+		hostResult.Rtt = uint32(result.rtt / time.Millisecond)
+	}
+	controller.eventQueue <- &nraySchema.Event{
+		NodeID:      controller.nodeID,
+		NodeName:    controller.nodeName,
+		EventData:   &nraySchema.Event_Host{Host: hostResult},
+		Scannername: "host-discovery",
+		Timestamp:   timestamp,
+	}
+}
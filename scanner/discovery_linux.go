@@ -0,0 +1,110 @@
+//go:build linux
+
+package scanner
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"net"
+	"syscall"
+	"time"
+)
+
+// ackPing sends a TCP ACK over a raw socket. Hosts answer ACKs without a
+// connection with a RST, no matter if the port is open or closed, so any RST
+// acknowledging the probe means the host is up. This fails without CAP_NET_RAW
+// and for hosts that are no IPv4 addresses
+func ackPing(host string, port uint32, timeout time.Duration, source *sourceBinding) (pingResult, error) {
+	ip := net.ParseIP(host).To4()
+	if ip == nil {
+		return pingResult{}, fmt.Errorf("ACK pings are only supported for IPv4 addresses")
+	}
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_RAW, syscall.IPPROTO_TCP)
+	if err != nil {
+		return pingResult{}, err
+	}
+	defer syscall.Close(fd)
+	if source != nil && source.iface != "" {
+		if err := syscall.BindToDevice(fd, source.iface); err != nil {
+			return pingResult{}, err
+		}
+	}
+	localIP := source.ipv4()
+	if localIP == nil {
+		// Only asks the kernel for the route, no packet is sent
+		conn, err := source.dial("udp4", net.JoinHostPort(host, "9"), timeout)
+		if err != nil {
+			return pingResult{}, err
+		}
+		localIP = conn.LocalAddr().(*net.UDPAddr).IP.To4()
+		conn.Close()
+	}
+
+	// Not cryptographically relevant, so seeding with time should be OK
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	sourcePort := uint16(40000 + random.Intn(20000))
+	ack := random.Uint32()
+	packet := buildACK(localIP, ip, sourcePort, uint16(port), random.Uint32(), ack)
+	destination := &syscall.SockaddrInet4{}
+	copy(destination.Addr[:], ip)
+	start := time.Now()
+	if err := syscall.Sendto(fd, packet, 0, destination); err != nil {
+		return pingResult{}, err
+	}
+	method := fmt.Sprintf("tcp-ack/%d", port)
+	buf := make([]byte, 1500)
+	for {
+		remaining := timeout - time.Since(start)
+		if remaining <= 0 {
+			return pingResult{method: method}, nil
+		}
+		readTimeout := syscall.NsecToTimeval(remaining.Nanoseconds())
+		if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &readTimeout); err != nil {
+			return pingResult{}, err
+		}
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			// Timeouts end up here as well
+			return pingResult{method: method}, nil
+		}
+		peer, peerPort, seq, ok := parseRST(buf[:n], sourcePort)
+		if ok && peer.Equal(ip) && peerPort == uint16(port) && seq == ack {
+			return pingResult{method: method, rtt: time.Since(start), up: true}, nil
+		}
+	}
+}
+
+// buildACK builds the TCP header of an ACK without payload. The IP header is added by the kernel
+func buildACK(source net.IP, destination net.IP, sourcePort uint16, destinationPort uint16, seq uint32, ack uint32) []byte {
+	header := make([]byte, 20)
+	binary.BigEndian.PutUint16(header[0:2], sourcePort)
+	binary.BigEndian.PutUint16(header[2:4], destinationPort)
+	binary.BigEndian.PutUint32(header[4:8], seq)
+	binary.BigEndian.PutUint32(header[8:12], ack)
+	header[12] = 5 << 4 // data offset in 32 bit words
+	header[13] = tcpFlagACK
+	binary.BigEndian.PutUint16(header[14:16], 1024) // window
+	binary.BigEndian.PutUint16(header[16:18], tcpChecksum(source, destination, header))
+	return header
+}
+
+// parseRST returns source address, port and sequence number of an IPv4 packet
+// if it is a RST to the given local port
+func parseRST(packet []byte, localPort uint16) (net.IP, uint16, uint32, bool) {
+	if len(packet) < 20 || packet[0]>>4 != 4 || packet[9] != syscall.IPPROTO_TCP {
+		return nil, 0, 0, false
+	}
+	headerLength := int(packet[0]&0x0f) * 4
+	if len(packet) < headerLength+20 {
+		return nil, 0, 0, false
+	}
+	segment := packet[headerLength:]
+	if binary.BigEndian.Uint16(segment[2:4]) != localPort || segment[13]&tcpFlagRST == 0 {
+		return nil, 0, 0, false
+	}
+	return net.IP(packet[12:16]), binary.BigEndian.Uint16(segment[0:2]), binary.BigEndian.Uint32(segment[4:8]), true
+}
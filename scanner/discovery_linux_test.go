@@ -0,0 +1,71 @@
+package scanner
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestBuildACK(t *testing.T) {
+	source, destination := net.IPv4(192, 0, 2, 1), net.IPv4(198, 51, 100, 1)
+	segment := buildACK(source, destination, 40000, 80, 1, 0xdeadbeef)
+	if segment[13] != tcpFlagACK || binary.BigEndian.Uint32(segment[8:12]) != 0xdeadbeef {
+		t.Errorf("Wrong ACK: %x", segment)
+	}
+	if checksum := tcpChecksum(source, destination, segment); checksum != 0 {
+		t.Errorf("Invalid checksum, got %#x", checksum)
+	}
+}
+
+func TestParseRST(t *testing.T) {
+	reply := func(flags byte) []byte {
+		packet := make([]byte, 40)
+		packet[0] = 0x45
+		packet[9] = 6
+		copy(packet[12:16], net.IPv4(192, 0, 2, 1).To4())
+		binary.BigEndian.PutUint16(packet[20:22], 80)
+		binary.BigEndian.PutUint16(packet[22:24], 40000)
+		binary.BigEndian.PutUint32(packet[24:28], 1234)
+		packet[33] = flags
+		return packet
+	}
+	ip, port, seq, ok := parseRST(reply(tcpFlagRST), 40000)
+	if !ok || ip.String() != "192.0.2.1" || port != 80 || seq != 1234 {
+		t.Errorf("Wrong RST: %s %d %d %t", ip, port, seq, ok)
+	}
+	if _, _, _, ok := parseRST(reply(tcpFlagACK), 40000); ok {
+		t.Errorf("ACK must not be accepted")
+	}
+	if _, _, _, ok := parseRST(reply(tcpFlagRST), 40001); ok {
+		t.Errorf("Replies to other ports must not be accepted")
+	}
+}
+
+func TestACKPing(t *testing.T) {
+	if fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_RAW, syscall.IPPROTO_TCP); err != nil {
+		t.Skipf("ACK pings not possible here: %v", err)
+	} else {
+		syscall.Close(fd)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	port := uint32(listener.Addr().(*net.TCPAddr).Port)
+	result, err := ackPing("127.0.0.1", port, discoveryTestTimeout, nil)
+	if err != nil || !result.up || result.method != "tcp-ack/"+fmt.Sprint(port) {
+		t.Errorf("Open port must answer with a RST, got %+v %v", result, err)
+	}
+	if _, err := ackPing("::1", port, discoveryTestTimeout, nil); err == nil {
+		t.Errorf("IPv6 addresses must fall back to connect pings")
+	}
+
+	// Without a raw socket, the discovery falls back to a connect ping
+	discovery := &hostDiscovery{timeout: discoveryTestTimeout}
+	if result := discovery.ackPing("localhost", "::1", port); result.method != "tcp/"+fmt.Sprint(port) {
+		t.Errorf("Expected a connect ping, got %+v", result)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package scanner
+
+import (
+	"fmt"
+	"time"
+)
+
+// ackPing is only implemented on Linux, callers fall back to connect pings
+func ackPing(host string, port uint32, timeout time.Duration, source *sourceBinding) (pingResult, error) {
+	return pingResult{}, fmt.Errorf("ACK pings are only supported on Linux")
+}
@@ -0,0 +1,75 @@
+package scanner
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	nraySchema "github.com/nray-scanner/nray/schemas"
+	"github.com/spf13/viper"
+)
+
+const discoveryTestTimeout = 300 * time.Millisecond
+
+func TestTCPPing(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := uint32(listener.Addr().(*net.TCPAddr).Port)
+	if result := tcpPing("127.0.0.1", port, discoveryTestTimeout, nil); !result.up || result.method != fmt.Sprintf("tcp/%d", port) {
+		t.Errorf("Open port must mean up, got %+v", result)
+	}
+	listener.Close()
+	if result := tcpPing("127.0.0.1", port, discoveryTestTimeout, nil); !result.up {
+		t.Errorf("Refused connection must mean up, got %+v", result)
+	}
+}
+
+func TestUDPPing(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := uint32(conn.LocalAddr().(*net.UDPAddr).Port)
+	conn.Close()
+	if result := udpPing("127.0.0.1", port, discoveryTestTimeout, nil); !result.up {
+		t.Errorf("Port unreachable must mean up, got %+v", result)
+	}
+}
+
+func TestDiscoverHosts(t *testing.T) {
+	config := viper.New()
+	config.Set("discovery.enabled", true)
+	config.Set("discovery.icmp", false)
+	config.Set("discovery.tcpPorts", []int{1})
+	config.Set("discovery.udpPorts", []int{})
+	config.Set("discovery.timeout", "300ms")
+	controller, err := CreateScanController("test", "test", 0, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Names under .invalid never resolve, so the host cannot answer
+	batch := &nraySchema.MoreWorkReply{Batchid: 1, Targets: &nraySchema.ScanTargets{
+		Rhosts:   []string{"127.0.0.1", "down.invalid"},
+		Tcpports: []uint32{1},
+		Services: []*nraySchema.ServiceTarget{{Rhost: "192.0.2.2", Proto: "tcp", Port: 22}},
+	}}
+	discovered := controller.discoverHosts(batch)
+	if hosts := discovered.Targets.GetRhosts(); len(hosts) != 1 || hosts[0] != "127.0.0.1" {
+		t.Errorf("Expected the live host, got %v", hosts)
+	}
+	if len(discovered.Targets.GetServices()) != 1 || len(batch.Targets.GetRhosts()) != 2 {
+		t.Errorf("Services must be kept and the original batch must not change")
+	}
+	up := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		host := (<-controller.eventQueue).GetHost()
+		up[host.GetTarget()] = host.GetUp()
+	}
+	if len(up) != 2 || !up["127.0.0.1"] || up["down.invalid"] {
+		t.Errorf("Wrong discovery events: %v", up)
+	}
+}
@@ -0,0 +1,200 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/golang/time/rate"
+	"github.com/nray-scanner/nray/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// ScanTask is a fully prepared scan that just has to be run by a worker.
+// Host is the target of the scan, it is used to enforce politeness limits
+type ScanTask struct {
+	Host string
+	Run  func()
+	// Set once the task reserved its politeness rate limit tokens
+	reserved bool
+}
+
+// politenessRetryInterval is how long a task waits for connection slots
+// before it is tried again
+const politenessRetryInterval = 50 * time.Millisecond
+
+// targetLimit holds the rate limiter and connection slots of a single host or network
+type targetLimit struct {
+	limiter     *rate.Limiter
+	connections chan struct{}
+}
+
+// politeness limits the load a node puts on single hosts and networks,
+// regardless of how many other targets are in the same batch
+type politeness struct {
+	hostRatelimit         rate.Limit
+	maxConnectionsPerHost int
+	networkRatelimit      rate.Limit
+	maxConnectionsPerNet  int
+	networkMask           net.IPMask
+	networkMaskIPv6       net.IPMask
+	hosts                 map[string]*targetLimit
+	networks              map[string]*targetLimit
+	lock                  sync.Mutex
+}
+
+// newPoliteness reads the politeness limits from the scanner configuration
+func newPoliteness(config *viper.Viper) *politeness {
+	config = utils.ApplyDefaultScannerPolitenessConfig(config)
+	p := &politeness{
+		hostRatelimit:         politenessRatelimit(config.GetString("hostRatelimit")),
+		maxConnectionsPerHost: config.GetInt("maxConnectionsPerHost"),
+		networkRatelimit:      politenessRatelimit(config.GetString("networkRatelimit")),
+		maxConnectionsPerNet:  config.GetInt("maxConnectionsPerNetwork"),
+		networkMask:           net.CIDRMask(config.GetInt("networkPrefix"), 32),
+		networkMaskIPv6:       net.CIDRMask(config.GetInt("networkPrefixIPv6"), 128),
+		hosts:                 make(map[string]*targetLimit),
+		networks:              make(map[string]*targetLimit),
+	}
+	if p.networkMask == nil || p.networkMaskIPv6 == nil {
+		log.WithFields(log.Fields{
+			"module": "scanner.politeness",
+			"src":    "newPoliteness",
+		}).Errorf("Invalid network prefix %d or IPv6 network prefix %d, using /24 and /64", config.GetInt("networkPrefix"), config.GetInt("networkPrefixIPv6"))
+		p.networkMask = net.CIDRMask(24, 32)
+		p.networkMaskIPv6 = net.CIDRMask(64, 128)
+	}
+	return p
+}
+
+// politenessRatelimit parses a rate limit, "none" or anything that is not a positive number means no limit
+func politenessRatelimit(value string) rate.Limit {
+	var limit float64
+	if _, err := fmt.Sscanf(value, "%g", &limit); err != nil || limit <= 0 {
+		if value != "none" {
+			log.WithFields(log.Fields{
+				"module": "scanner.politeness",
+				"src":    "politenessRatelimit",
+			}).Errorf("Invalid rate limit %s, not limiting", value)
+		}
+		return rate.Inf
+	}
+	return rate.Limit(limit)
+}
+
+// enabled returns if any limit is configured at all
+func (p *politeness) enabled() bool {
+	return p.hostRatelimit != rate.Inf || p.maxConnectionsPerHost > 0 ||
+		p.networkRatelimit != rate.Inf || p.maxConnectionsPerNet > 0
+}
+
+// network returns the network a host belongs to
+func (p *politeness) network(host string) string {
+	return networkOf(host, p.networkMask, p.networkMaskIPv6)
+}
+
+// limitFor returns the limits for key, creating them if they don't exist yet
+func (p *politeness) limitFor(limits map[string]*targetLimit, key string, ratelimit rate.Limit, maxConnections int) *targetLimit {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	limit, exists := limits[key]
+	if !exists {
+		limit = &targetLimit{limiter: rate.NewLimiter(ratelimit, 1)}
+		if maxConnections > 0 {
+			limit.connections = make(chan struct{}, maxConnections)
+		}
+		limits[key] = limit
+	}
+	return limit
+}
+
+// acquire blocks until a scan of host is allowed by all limits and returns
+// a function that must be called once the scan is done. probes is the number
+// of rate limit tokens taken. Connection slots are always taken for the network
+// first and then for the host, so callers waiting for each other can't deadlock
+func (p *politeness) acquire(host string, probes int) (release func()) {
+	if !p.enabled() {
+		return func() {}
+	}
+	network, target := p.limitsOf(host)
+	for _, limit := range []*targetLimit{network, target} {
+		if limit.connections != nil {
+			limit.connections <- struct{}{}
+		}
+	}
+	for i := 0; i < probes; i++ {
+		for _, limit := range []*targetLimit{network, target} {
+			limit.limiter.Wait(context.TODO())
+		}
+	}
+	return p.releaseFunc(network, target)
+}
+
+// wait blocks until the rate limits allow another probe to host. It is meant for
+// scans that already hold their connection slots and send more than one probe
+func (p *politeness) wait(host string) {
+	if !p.enabled() {
+		return
+	}
+	network, target := p.limitsOf(host)
+	for _, limit := range []*targetLimit{network, target} {
+		limit.limiter.Wait(context.TODO())
+	}
+}
+
+// tryAcquire works like acquire for a single probe but never blocks. If a limit
+// has no room, ok is false and the task should be tried again after wait. The
+// rate limit tokens are reserved for the task then, so it isn't delayed by the
+// rate limits again and tasks of a host are run in the order they were tried
+func (p *politeness) tryAcquire(task *ScanTask) (release func(), wait time.Duration, ok bool) {
+	if !p.enabled() {
+		return func() {}, 0, true
+	}
+	network, target := p.limitsOf(task.Host)
+	if !task.reserved {
+		task.reserved = true
+		for _, limit := range []*targetLimit{network, target} {
+			wait = max(wait, limit.limiter.Reserve().Delay())
+		}
+		if wait > 0 {
+			return nil, wait, false
+		}
+	}
+	taken := make([]*targetLimit, 0, 2)
+	for _, limit := range []*targetLimit{network, target} {
+		if limit.connections == nil {
+			continue
+		}
+		select {
+		case limit.connections <- struct{}{}:
+			taken = append(taken, limit)
+		default:
+			for _, takenLimit := range taken {
+				<-takenLimit.connections
+			}
+			return nil, politenessRetryInterval, false
+		}
+	}
+	return p.releaseFunc(network, target), 0, true
+}
+
+// limitsOf returns the limits of the network and of host itself
+func (p *politeness) limitsOf(host string) (*targetLimit, *targetLimit) {
+	network := p.limitFor(p.networks, p.network(host), p.networkRatelimit, p.maxConnectionsPerNet)
+	target := p.limitFor(p.hosts, host, p.hostRatelimit, p.maxConnectionsPerHost)
+	return network, target
+}
+
+// releaseFunc returns a function freeing the connection slots of a scan
+func (p *politeness) releaseFunc(network *targetLimit, target *targetLimit) func() {
+	return func() {
+		for _, limit := range []*targetLimit{target, network} {
+			if limit.connections != nil {
+				<-limit.connections
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package scanner
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestPolitenessNetwork(t *testing.T) {
+	config := viper.New()
+	config.Set("networkPrefix", 16)
+	p := newPoliteness(config)
+	tests := map[string]string{
+		"10.1.2.3":          "10.1.0.0/16",
+		"2001:db8::1":       "2001:db8::/64",
+		"scanme.example.io": "scanme.example.io",
+	}
+	for host, expected := range tests {
+		if network := p.network(host); network != expected {
+			t.Errorf("Expected network %s for %s, got %s", expected, host, network)
+		}
+	}
+}
+
+func TestPolitenessMaxConnections(t *testing.T) {
+	config := viper.New()
+	config.Set("maxConnectionsPerHost", 2)
+	config.Set("maxConnectionsPerNetwork", 3)
+	p := newPoliteness(config)
+
+	var running, maxRunning int64
+	perHost := map[string]*int64{"10.0.0.1": new(int64), "10.0.0.2": new(int64)}
+	var maxPerHost int64
+	updateMax := func(max *int64, current int64) {
+		for {
+			old := atomic.LoadInt64(max)
+			if current <= old || atomic.CompareAndSwapInt64(max, old, current) {
+				return
+			}
+		}
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		host := "10.0.0.1"
+		if i%2 == 0 {
+			host = "10.0.0.2"
+		}
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			release := p.acquire(host, 1)
+			defer release()
+			updateMax(&maxRunning, atomic.AddInt64(&running, 1))
+			updateMax(&maxPerHost, atomic.AddInt64(perHost[host], 1))
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt64(perHost[host], -1)
+			atomic.AddInt64(&running, -1)
+		}(host)
+	}
+	wg.Wait()
+	if maxRunning > 3 {
+		t.Errorf("Network connection limit exceeded: %d", maxRunning)
+	}
+	if maxPerHost > 2 {
+		t.Errorf("Host connection limit exceeded: %d", maxPerHost)
+	}
+}
+
+func TestPolitenessRatelimit(t *testing.T) {
+	config := viper.New()
+	config.Set("hostRatelimit", 20)
+	p := newPoliteness(config)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		p.acquire("10.0.0.1", 1)()
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Host rate limit not applied, 5 scans took %s", elapsed)
+	}
+	start = time.Now()
+	p.acquire("10.0.0.2", 1)()
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("Rate limit of one host delayed another one: %s", elapsed)
+	}
+	if newPoliteness(nil).enabled() {
+		t.Errorf("Politeness limits must be disabled by default")
+	}
+}
+
+func TestPolitenessTryAcquire(t *testing.T) {
+	config := viper.New()
+	config.Set("hostRatelimit", 5)
+	config.Set("maxConnectionsPerHost", 1)
+	p := newPoliteness(config)
+
+	first := &ScanTask{Host: "10.0.0.1"}
+	release, _, ok := p.tryAcquire(first)
+	if !ok {
+		t.Fatal("The first scan of a host must not wait")
+	}
+	// The rate limit is reserved although no connection slot is free
+	second := &ScanTask{Host: "10.0.0.1"}
+	if _, wait, ok := p.tryAcquire(second); ok || wait < 150*time.Millisecond || !second.reserved {
+		t.Errorf("Expected to wait for the rate limit, got %s %t", wait, ok)
+	}
+	if _, wait, ok := p.tryAcquire(second); ok || wait != politenessRetryInterval {
+		t.Errorf("Expected to wait for a connection slot, got %s %t", wait, ok)
+	}
+	if otherRelease, _, ok := p.tryAcquire(&ScanTask{Host: "10.0.0.2"}); !ok {
+		t.Errorf("Limits of one host must not delay another one")
+	} else {
+		otherRelease()
+	}
+	release()
+	if release, _, ok := p.tryAcquire(second); !ok {
+		t.Errorf("Reserved task must run once a connection slot is free")
+	} else {
+		release()
+	}
+}
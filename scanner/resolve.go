@@ -0,0 +1,100 @@
+package scanner
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/nray-scanner/nray/utils"
+	"github.com/spf13/viper"
+)
+
+// nodeResolver resolves target names for all scanners of a node, so each name
+// is looked up once per TTL instead of once per scanned port
+type nodeResolver struct {
+	resolver *utils.Resolver
+	// "ip4", "ip6" or "ip" for both
+	network string
+}
+
+// newNodeResolver reads the resolver configuration
+func newNodeResolver(config *viper.Viper) (*nodeResolver, error) {
+	config = utils.ApplyDefaultScannerResolverConfig(config)
+	network, known := map[string]string{"ipv4": "ip4", "ipv6": "ip6", "both": "ip"}[config.GetString("addressFamily")]
+	if !known {
+		return nil, fmt.Errorf("invalid address family %s, use ipv4, ipv6 or both", config.GetString("addressFamily"))
+	}
+	resolver := utils.NewResolver(config.GetStringSlice("nameservers"), config.GetDuration("timeout"), config.GetDuration("maxCacheTime"))
+	resolver.SetUseTCP(config.GetBool("tcp"))
+	return &nodeResolver{resolver: resolver, network: network}, nil
+}
+
+// resolve returns the IP address host is scanned on. IP addresses are returned
+// unchanged, names are resolved to their first address, IPv4 addresses first.
+// Without resolver, host is returned and resolved by the operating system
+func (r *nodeResolver) resolve(host string) (string, error) {
+	addresses, err := r.resolveAll(host)
+	if err != nil {
+		return "", err
+	}
+	return addresses[0], nil
+}
+
+// resolveAll works like resolve but returns all addresses of a name
+func (r *nodeResolver) resolveAll(host string) ([]string, error) {
+	if r == nil || net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+	return r.resolver.Lookup(host, r.network)
+}
+
+// resolveAddress resolves the host of a host:port address
+func (r *nodeResolver) resolveAddress(address string) (string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", err
+	}
+	ip, err := r.resolve(host)
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(ip, port), nil
+}
+
+// dial resolves the host of a host:port address and connects to its addresses
+// one after another until a connection is established, like the operating
+// system's dialer does. If all fail, the error of the first one is returned
+func (r *nodeResolver) dial(address string, dial func(address string) (net.Conn, error)) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := r.resolveAll(host)
+	if err != nil {
+		return nil, err
+	}
+	var firstErr error
+	for _, ip := range addresses {
+		conn, err := dial(net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// remoteIP returns the IP address a connection was opened to. For connections
+// through proxies that resolved the name themselves, it is unknown
+func remoteIP(conn net.Conn) string {
+	if proxied, ok := conn.(*proxiedConn); ok {
+		host, _, _ := net.SplitHostPort(proxied.address)
+		if net.ParseIP(host) == nil {
+			return ""
+		}
+		return host
+	}
+	host, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+	return host
+}
@@ -0,0 +1,219 @@
+package scanner
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// stubNameserver answers A and AAAA queries over UDP and TCP on the same port.
+// Names in truncate get truncated answers over UDP, queries like "TypeAAAA/name"
+// in servfail fail
+type stubNameserver struct {
+	address  string
+	records  map[string][]string
+	truncate map[string]bool
+	servfail map[string]bool
+	queries  map[string]int
+	lock     sync.Mutex
+}
+
+func startStubNameserver(t *testing.T, records map[string][]string, truncate map[string]bool) *stubNameserver {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	packetConn, err := net.ListenPacket("udp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close(); packetConn.Close() })
+	server := &stubNameserver{address: listener.Addr().String(), records: records, truncate: truncate, queries: make(map[string]int)}
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := packetConn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if response := server.answer(buf[:n], "udp"); response != nil {
+				packetConn.WriteTo(response, addr)
+			}
+		}
+	}()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			length := make([]byte, 2)
+			if _, err := io.ReadFull(conn, length); err == nil {
+				query := make([]byte, binary.BigEndian.Uint16(length))
+				io.ReadFull(conn, query)
+				if response := server.answer(query, "tcp"); response != nil {
+					conn.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(response))), response...))
+				}
+			}
+			conn.Close()
+		}
+	}()
+	return server
+}
+
+func (server *stubNameserver) answer(packet []byte, network string) []byte {
+	var query dnsmessage.Message
+	if err := query.Unpack(packet); err != nil || len(query.Questions) != 1 {
+		return nil
+	}
+	question := query.Questions[0]
+	name := question.Name.String()
+	name = name[:len(name)-1]
+	server.lock.Lock()
+	server.queries[network+"/"+question.Type.String()+"/"+name]++
+	servfail := server.servfail[question.Type.String()+"/"+name]
+	server.lock.Unlock()
+
+	response := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: query.Header.ID, Response: true, RecursionAvailable: true},
+		Questions: query.Questions,
+	}
+	if servfail {
+		response.Header.RCode = dnsmessage.RCodeServerFailure
+	} else if network == "udp" && server.truncate[name] {
+		response.Header.Truncated = true
+	} else {
+		for _, address := range server.records[name] {
+			header := dnsmessage.ResourceHeader{Name: question.Name, Type: question.Type, Class: dnsmessage.ClassINET, TTL: 300}
+			ip := net.ParseIP(address)
+			if ip4 := ip.To4(); ip4 != nil && question.Type == dnsmessage.TypeA {
+				resource := &dnsmessage.AResource{}
+				copy(resource.A[:], ip4)
+				response.Answers = append(response.Answers, dnsmessage.Resource{Header: header, Body: resource})
+			} else if ip.To4() == nil && question.Type == dnsmessage.TypeAAAA {
+				resource := &dnsmessage.AAAAResource{}
+				copy(resource.AAAA[:], ip)
+				response.Answers = append(response.Answers, dnsmessage.Resource{Header: header, Body: resource})
+			}
+		}
+	}
+	packed, _ := response.Pack()
+	return packed
+}
+
+func (server *stubNameserver) queryCount(key string) int {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	return server.queries[key]
+}
+
+func testNodeResolver(t *testing.T, settings map[string]interface{}) *nodeResolver {
+	config := viper.New()
+	for key, value := range settings {
+		config.Set(key, value)
+	}
+	resolver, err := newNodeResolver(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolver
+}
+
+func TestNodeResolver(t *testing.T) {
+	server := startStubNameserver(t, map[string][]string{
+		"dual.example.test": {"fd00::1", "10.0.0.1"},
+		"v6.example.test":   {"fd00::2"},
+		"big.example.test":  {"10.0.0.3"},
+	}, map[string]bool{"big.example.test": true})
+
+	resolver := testNodeResolver(t, map[string]interface{}{"nameservers": []string{server.address}})
+	for host, expected := range map[string]string{
+		"dual.example.test": "10.0.0.1",
+		"v6.example.test":   "fd00::2",
+		"192.0.2.1":         "192.0.2.1",
+	} {
+		if address, err := resolver.resolve(host); err != nil || address != expected {
+			t.Errorf("Expected %s for %s, got %s %v", expected, host, address, err)
+		}
+	}
+	// Truncated answers are repeated over TCP
+	if address, err := resolver.resolve("big.example.test"); err != nil || address != "10.0.0.3" {
+		t.Errorf("Truncated answer not repeated over TCP, got %s %v", address, err)
+	}
+	if server.queryCount("tcp/TypeA/big.example.test") != 1 {
+		t.Errorf("Expected a query over TCP")
+	}
+
+	ipv4 := testNodeResolver(t, map[string]interface{}{"nameservers": []string{server.address}, "addressFamily": "ipv4"})
+	if _, err := ipv4.resolve("v6.example.test"); err == nil {
+		t.Errorf("Names without A records must not resolve with addressFamily ipv4")
+	}
+	tcp := testNodeResolver(t, map[string]interface{}{"nameservers": []string{server.address}, "tcp": true, "addressFamily": "ipv6"})
+	if address, err := tcp.resolve("dual.example.test"); err != nil || address != "fd00::1" {
+		t.Errorf("Expected fd00::1, got %s %v", address, err)
+	}
+	if server.queryCount("tcp/TypeAAAA/dual.example.test") != 1 || server.queryCount("udp/TypeAAAA/dual.example.test") != 1 {
+		t.Errorf("Expected one query over TCP and one over UDP from the first resolver")
+	}
+
+	config := viper.New()
+	config.Set("addressFamily", "ipx")
+	if _, err := newNodeResolver(config); err == nil {
+		t.Errorf("Invalid address family must be refused")
+	}
+}
+
+func TestTCPScannerSharesResolverCache(t *testing.T) {
+	server := startStubNameserver(t, map[string][]string{"local.example.test": {"127.0.0.1"}}, nil)
+	_, port := startBannerServer(t)
+	tcpScanner := &TCPScanner{
+		timeout:  time.Second,
+		resolver: testNodeResolver(t, map[string]interface{}{"nameservers": []string{server.address}, "addressFamily": "ipv4"}),
+	}
+	for i := 0; i < 3; i++ {
+		result, err := tcpScanner.scan("local.example.test", port)
+		if err != nil || result == nil || result.Address != "127.0.0.1" || result.Target != "local.example.test" {
+			t.Fatalf("Expected an open port on 127.0.0.1, got %+v %v", result, err)
+		}
+	}
+	if count := server.queryCount("udp/TypeA/local.example.test"); count != 1 {
+		t.Errorf("Expected a single query, got %d", count)
+	}
+}
+
+func TestNodeResolverPartialAnswers(t *testing.T) {
+	server := startStubNameserver(t, map[string][]string{"dual.example.test": {"fd00::1", "10.0.0.1"}}, nil)
+	server.lock.Lock()
+	server.servfail = map[string]bool{"TypeAAAA/dual.example.test": true}
+	server.lock.Unlock()
+	resolver := testNodeResolver(t, map[string]interface{}{"nameservers": []string{server.address}})
+	if addresses, err := resolver.resolveAll("dual.example.test"); err != nil || len(addresses) != 1 || addresses[0] != "10.0.0.1" {
+		t.Errorf("Expected the IPv4 address although the AAAA query failed, got %v %v", addresses, err)
+	}
+}
+
+func TestNodeResolverDialsAllAddresses(t *testing.T) {
+	// Nothing listens on 127.0.0.2, so the second address must be tried
+	server := startStubNameserver(t, map[string][]string{"local.example.test": {"127.0.0.2", "127.0.0.1"}}, nil)
+	_, port := startBannerServer(t)
+	resolver := testNodeResolver(t, map[string]interface{}{"nameservers": []string{server.address}})
+	tried := make([]string, 0)
+	conn, err := resolver.dial(net.JoinHostPort("local.example.test", fmt.Sprint(port)), func(address string) (net.Conn, error) {
+		tried = append(tried, address)
+		return net.DialTimeout("tcp", address, time.Second)
+	})
+	if err != nil {
+		t.Fatalf("Expected a connection to the second address, tried %v: %v", tried, err)
+	}
+	conn.Close()
+	if len(tried) != 2 || remoteIP(conn) != "127.0.0.1" {
+		t.Errorf("Expected both addresses to be tried, got %v", tried)
+	}
+}
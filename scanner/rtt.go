@@ -0,0 +1,116 @@
+package scanner
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/nray-scanner/nray/utils"
+	"github.com/spf13/viper"
+)
+
+// Keeps memory bounded in internet wide scans. Once exceeded, all estimates are dropped
+const maxRTTNetworks = 65536
+
+// rttStats is the smoothed round trip time of one network as described in RFC 6298
+type rttStats struct {
+	srtt   time.Duration
+	rttvar time.Duration
+}
+
+// rttEstimator measures round trip times per destination network and derives
+// timeouts from them. A nil estimator always returns the fixed timeout
+type rttEstimator struct {
+	min             time.Duration
+	max             time.Duration
+	networkMask     net.IPMask
+	networkMaskIPv6 net.IPMask
+	networks        map[string]*rttStats
+	lock            sync.Mutex
+}
+
+// newRTTEstimator returns an estimator if adaptive timeouts are enabled, otherwise nil
+func newRTTEstimator(config *viper.Viper) *rttEstimator {
+	config = utils.ApplyDefaultScannerAdaptiveTimeoutConfig(config)
+	if !config.GetBool("enabled") {
+		return nil
+	}
+	estimator := &rttEstimator{
+		min:             config.GetDuration("min"),
+		max:             config.GetDuration("max"),
+		networkMask:     net.CIDRMask(config.GetInt("networkPrefix"), 32),
+		networkMaskIPv6: net.CIDRMask(config.GetInt("networkPrefixIPv6"), 128),
+		networks:        make(map[string]*rttStats),
+	}
+	if estimator.networkMask == nil || estimator.networkMaskIPv6 == nil {
+		estimator.networkMask = net.CIDRMask(24, 32)
+		estimator.networkMaskIPv6 = net.CIDRMask(64, 128)
+	}
+	return estimator
+}
+
+// networkOf returns the network a host belongs to. Hosts that are not IP
+// addresses are treated as a network of their own
+func networkOf(host string, mask net.IPMask, maskIPv6 net.IPMask) string {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return host
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ones, _ := mask.Size()
+		return fmt.Sprintf("%s/%d", ip4.Mask(mask), ones)
+	}
+	ones, _ := maskIPv6.Size()
+	return fmt.Sprintf("%s/%d", ip.Mask(maskIPv6), ones)
+}
+
+// observe adds a measured round trip time to the network of host
+func (e *rttEstimator) observe(host string, rtt time.Duration) {
+	if e == nil || rtt <= 0 {
+		return
+	}
+	network := networkOf(host, e.networkMask, e.networkMaskIPv6)
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	stats, exists := e.networks[network]
+	if !exists {
+		if len(e.networks) >= maxRTTNetworks {
+			e.networks = make(map[string]*rttStats)
+		}
+		e.networks[network] = &rttStats{srtt: rtt, rttvar: rtt / 2}
+		return
+	}
+	deviation := stats.srtt - rtt
+	if deviation < 0 {
+		deviation = -deviation
+	}
+	stats.rttvar = (3*stats.rttvar + deviation) / 4
+	stats.srtt = (7*stats.srtt + rtt) / 8
+}
+
+// timeout returns SRTT + 4*RTTVAR of the network of host within the configured
+// bounds. Without measurements for the network, fallback is returned
+func (e *rttEstimator) timeout(host string, fallback time.Duration) time.Duration {
+	if e == nil {
+		return fallback
+	}
+	network := networkOf(host, e.networkMask, e.networkMaskIPv6)
+	e.lock.Lock()
+	stats, exists := e.networks[network]
+	var timeout time.Duration
+	if exists {
+		timeout = stats.srtt + 4*stats.rttvar
+	}
+	e.lock.Unlock()
+	if !exists {
+		return fallback
+	}
+	if timeout < e.min {
+		return e.min
+	}
+	if timeout > e.max {
+		return e.max
+	}
+	return timeout
+}
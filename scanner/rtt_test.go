@@ -0,0 +1,79 @@
+package scanner
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestRTTEstimator(t *testing.T) {
+	var disabled *rttEstimator
+	disabled.observe("10.0.0.1", time.Second)
+	if timeout := disabled.timeout("10.0.0.1", time.Second); timeout != time.Second {
+		t.Errorf("Disabled estimator must return the fixed timeout, got %s", timeout)
+	}
+	if newRTTEstimator(nil) != nil {
+		t.Errorf("Adaptive timeouts must be disabled by default")
+	}
+
+	config := viper.New()
+	config.Set("enabled", true)
+	config.Set("min", "50ms")
+	config.Set("max", "1s")
+	estimator := newRTTEstimator(config)
+	if timeout := estimator.timeout("10.0.0.1", 2*time.Second); timeout != 2*time.Second {
+		t.Errorf("Expected the fixed timeout without measurements, got %s", timeout)
+	}
+	estimator.observe("10.0.0.1", 100*time.Millisecond)
+	// SRTT 100ms, RTTVAR 50ms
+	if timeout := estimator.timeout("10.0.0.200", 2*time.Second); timeout != 300*time.Millisecond {
+		t.Errorf("Expected 300ms for the same /24, got %s", timeout)
+	}
+	estimator.observe("10.0.0.2", 100*time.Millisecond)
+	// SRTT 100ms, RTTVAR 37.5ms
+	if timeout := estimator.timeout("10.0.0.1", 2*time.Second); timeout != 250*time.Millisecond {
+		t.Errorf("Expected 250ms, got %s", timeout)
+	}
+	if timeout := estimator.timeout("10.0.1.1", 2*time.Second); timeout != 2*time.Second {
+		t.Errorf("Other networks must not be affected, got %s", timeout)
+	}
+	estimator.observe("10.0.2.1", time.Millisecond)
+	if timeout := estimator.timeout("10.0.2.1", 2*time.Second); timeout != 50*time.Millisecond {
+		t.Errorf("Expected the minimum timeout, got %s", timeout)
+	}
+	estimator.observe("10.0.3.1", 3*time.Second)
+	if timeout := estimator.timeout("10.0.3.1", 2*time.Second); timeout != time.Second {
+		t.Errorf("Expected the maximum timeout, got %s", timeout)
+	}
+}
+
+func TestTCPConnectRTT(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := uint32(listener.Addr().(*net.TCPAddr).Port)
+	listener.Close()
+	if result, rtt, err := tcpConnect("127.0.0.1", port, time.Second, nil); result != nil || rtt <= 0 || err != nil {
+		t.Errorf("Refused connection should be closed with a round trip time, got %v %s %v", result, rtt, err)
+	}
+
+	listener, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	config := viper.New()
+	config.Set("enabled", true)
+	config.Set("min", "200ms")
+	scanner := &TCPScanner{timeout: time.Second, rtt: newRTTEstimator(config)}
+	port = uint32(listener.Addr().(*net.TCPAddr).Port)
+	if result, err := scanner.scan("127.0.0.1", port); result == nil || err != nil || result.Timeout != time.Second {
+		t.Fatalf("Expected open port with the fixed timeout, got %v %v", result, err)
+	}
+	if result, _ := scanner.scan("127.0.0.1", port); result == nil || result.Timeout != 200*time.Millisecond {
+		t.Errorf("Expected the adaptive timeout to be reported, got %v", result)
+	}
+}
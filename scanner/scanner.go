@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -23,12 +24,7 @@ func currentTime() time.Time {
 // The workbatch channel is used to send workbatches
 // Data sent to dataChan will be picked by the nodes send/recv loop and transferred to the server,
 // so it is used for sending requests for more work or reporting results
-// TODO: Scan options
 func RunNodeScannerLoop(controller *ScanController, workBatchChan <-chan *nraySchema.MoreWorkReply, dataChan chan<- *nraySchema.NrayNodeMessage) {
-	var tcpscanner = &TCPScanner{}
-	var udpscanner = &UDPScanner{}
-	tcpscanner.Configure(controller.scannerConfig.Sub("tcp")) // TODO: actual configuration and create struct via New()
-	udpscanner.Configure(controller.scannerConfig.Sub("udp"))
 	for {
 		// if the scan is paused, sleep 2 seconds before checking again
 		if controller.Pause.GetValue() {
@@ -50,60 +46,87 @@ func RunNodeScannerLoop(controller *ScanController, workBatchChan <-chan *nraySc
 			continue
 		}
 
-		controller.Refresh() // Resets internal channels and starts house keeping goroutines
-
-		// Spin up workers
-		// Each worker has access to ScanController's work queue
-		// Work queue contains functions that are fully prepared, this means they
-		// have full state regarding targets, timeouts, configuration, where and how
-		// to report. Workers are just here to control the level of concurrency
-		var wg sync.WaitGroup
+		results := controller.ScanBatch(workBatch)
 		log.WithFields(log.Fields{
 			"module": "scanner.scanner",
 			"src":    "RunNodeScannerLoop",
-		}).Debugf("Starting workers: %d", controller.scannerConfig.GetInt("workers"))
-		for i := 0; i < controller.scannerConfig.GetInt("workers"); i++ {
-			wg.Add(1)
-			go func(queue <-chan func()) {
-				for queuedTask := range queue {
-					atomic.AddInt64(&controller.scansRunning, 1)
-					controller.ratelimiter.Wait(context.TODO())
-					queuedTask()
-					atomic.AddInt64(&controller.scansRunning, -1)
-				}
-				wg.Done()
-			}(controller.scanQueue)
-		}
+		}).Info("Finished work batch, submitting results")
+		dataChan <- reportResults(controller.nodeID, workBatch.Batchid, results)
+	}
+}
 
-		for scanFunc := range PrepareScanFuncs(tcpscanner, udpscanner, workBatch, controller.portscanResultQueue) {
-			controller.scanQueue <- scanFunc
-		}
+// ScanBatch scans the targets of a work batch and returns the resulting events.
+// It blocks until all port scans and all scans of protocol scanners triggered
+// by their results are done
+func (controller *ScanController) ScanBatch(workBatch *nraySchema.MoreWorkReply) []*nraySchema.Event {
+	controller.Refresh() // Resets internal channels and starts house keeping goroutines
+	// The scope is checked once, before any probe is sent. Names are replaced by
+	// the addresses that were checked, so nothing resolves them again
+	workBatch = applyScope(controller.scope, workBatch, controller.reportSkippedTarget)
+	controller.setHostnames(workBatch.GetTargets())
+	// Hosts that are down are dropped from the batch. The server still counts
+	// them as done because the whole batch is reported back
+	workBatch = controller.discoverHosts(workBatch)
 
-		go controller.waitForScanToFinishAndEventsToBeProcessed()
-
-		// STEPS
-
-		// 1: Register modules (in scannernode.go)
-		// 2: Implement abstract port scanning
-		// 2.1: message format should also support stuff like networks (for ZMAP)
-		// 2.2: which port scanner to chose is definied in the controller (see comment above)
-		// 3: Port scan results are sent to controller
-		// 4: Controller parses results and creates/forwards events to send them upstream
-		// 5: Controller triggers higher level scanners to do their job
-		// 6: Done when
-		// 6.1: Port scanner is done AND
-		// 6.2: No higher level scans are queued (queue should be empty)
-		// 6.3: All higher level scans have been performed (use a semaphore for counting active tasks?)
-
-		wg.Wait()
-		controller.workersDone = true
-		close(controller.portscanResultQueue)
-		log.WithFields(log.Fields{
-			"module": "scanner.scanner",
-			"src":    "RunNodeScannerLoop",
-		}).Info("Finished work batch, submitting results")
-		dataChan <- reportResults(controller.nodeID, workBatch.Batchid, controller.getResults())
+	// Spin up workers
+	// Each worker has access to ScanController's work queue
+	// Work queue contains functions that are fully prepared, this means they
+	// have full state regarding targets, timeouts, configuration, where and how
+	// to report. Workers are just here to control the level of concurrency
+	var wg sync.WaitGroup
+	log.WithFields(log.Fields{
+		"module": "scanner.scanner",
+		"src":    "ScanBatch",
+	}).Debugf("Starting workers: %d", controller.scannerConfig.GetInt("workers"))
+	for i := 0; i < controller.scannerConfig.GetInt("workers"); i++ {
+		wg.Add(1)
+		go func(queue chan *ScanTask, politeness *politeness) {
+			for queuedTask := range queue {
+				atomic.AddInt64(&controller.scansRunning, 1)
+				release, wait, ok := politeness.tryAcquire(queuedTask)
+				if !ok {
+					// The worker moves on to other targets while this one waits for its limits.
+					// The task counts as running until it is queued again, so the queue stays open
+					task := queuedTask
+					time.AfterFunc(wait, func() {
+						queue <- task
+						atomic.AddInt64(&controller.scansRunning, -1)
+					})
+					continue
+				}
+				controller.ratelimiter.Wait(context.TODO())
+				queuedTask.Run()
+				release()
+				atomic.AddInt64(&controller.scansRunning, -1)
+			}
+			wg.Done()
+		}(controller.scanQueue, controller.politeness)
 	}
+
+	for scanTask := range PrepareScanFuncs(controller.tcpPortScanner, controller.udpScanner, workBatch, controller.portscanResultQueue) {
+		controller.scanQueue <- scanTask
+	}
+
+	go controller.waitForScanToFinishAndEventsToBeProcessed()
+
+	// STEPS
+
+	// 1: Register modules (in scannernode.go)
+	// 2: Implement abstract port scanning
+	// 2.1: message format should also support stuff like networks (for ZMAP)
+	// 2.2: which port scanner to chose is definied in the controller (see comment above)
+	// 3: Port scan results are sent to controller
+	// 4: Controller parses results and creates/forwards events to send them upstream
+	// 5: Controller triggers higher level scanners to do their job
+	// 6: Done when
+	// 6.1: Port scanner is done AND
+	// 6.2: No higher level scans are queued (queue should be empty)
+	// 6.3: All higher level scans have been performed (use a semaphore for counting active tasks?)
+
+	wg.Wait()
+	controller.workersDone = true
+	close(controller.portscanResultQueue)
+	return controller.getResults()
 }
 
 // build a MoreWorkRequest and return the serialized message
@@ -134,36 +157,110 @@ func reportResults(nodeID string, batchID uint64, events []*nraySchema.Event) *n
 	return message
 }
 
-// PrepareScanFuncs returns a channel where scan functions are sent over
-// They are completely prepared and just have to be called
-func PrepareScanFuncs(tcpscanner *TCPScanner, udpscanner *UDPScanner, targetMsg *nraySchema.MoreWorkReply, results chan<- *PortscanResult) <-chan func() {
-	scanFuncs := make(chan func(), 100)
+// withHostname sets the DNS name the target was resolved from by the server, if any
+func withHostname(result *PortscanResult, hostname string) *PortscanResult {
+	if result != nil {
+		result.Hostname = hostname
+	}
+	return result
+}
+
+// applyScope removes the targets outside of the node's scope from a batch and
+// passes them to skip. In strict mode, no target of the batch is kept if any is
+// out of scope. DNS names are replaced by the addresses they were checked on
+func applyScope(scope *NodeScope, targetMsg *nraySchema.MoreWorkReply, skip func(*nraySchema.SkippedTarget)) *nraySchema.MoreWorkReply {
+	targets, skipped := scope.apply(targetMsg.Targets)
+	abort := len(skipped) > 0 && scope.Strict
+	for _, skippedTarget := range skipped {
+		skippedTarget.BatchAborted = abort
+		log.WithFields(log.Fields{
+			"module": "scanner.scanner",
+			"src":    "applyScope",
+		}).Warningf("Target %s is out of scope: %s", skippedTarget.Target, skippedTarget.Reason)
+		skip(skippedTarget)
+	}
+	if abort {
+		log.WithFields(log.Fields{
+			"module": "scanner.scanner",
+			"src":    "applyScope",
+		}).Errorf("Batch %d contains %d targets out of scope, aborting it", targetMsg.Batchid, len(skipped))
+		targets = &nraySchema.ScanTargets{}
+	}
+	return &nraySchema.MoreWorkReply{Batchid: targetMsg.Batchid, Targets: targets}
+}
+
+// PrepareScanFuncs returns a channel where scan tasks are sent over
+// They are completely prepared and just have to be run.
+// Targets are ordered host by host. The scope has to be applied before,
+// see applyScope. TCP tasks are prepared by the given TCP port scanner
+func PrepareScanFuncs(tcpscanner TCPPortScanner, udpscanner *UDPScanner, targetMsg *nraySchema.MoreWorkReply, results chan<- *PortscanResult) <-chan *ScanTask {
+	scanFuncs := make(chan *ScanTask, 100)
 
 	go func(targetMsg *nraySchema.MoreWorkReply, results chan<- *PortscanResult) {
-		for _, target := range targetMsg.Targets.GetRhosts() {
-			for _, targetTCPPort := range targetMsg.Targets.GetTcpports() {
-				// Reassign variables in new scope to avoid data race
-				t := target
-				port := targetTCPPort
-				timeout := tcpscanner.timeout
-				scanFuncs <- func() {
-					result, err := TCPConnectIsOpen(t, port, timeout)
-					utils.CheckError(err, false)
-					results <- result
-				}
+		hostnames := targetMsg.Targets.GetHostnames()
+		inScope := targetMsg.Targets.GetRhosts()
+		tcpServices := make([]*nraySchema.ServiceTarget, 0)
+		udpServices := make([]*nraySchema.ServiceTarget, 0)
+		for _, service := range targetMsg.Targets.GetServices() {
+			switch strings.ToLower(service.GetProto()) {
+			case "tcp":
+				tcpServices = append(tcpServices, service)
+			case "udp":
+				udpServices = append(udpServices, service)
+			default:
+				log.WithFields(log.Fields{
+					"module": "scanner.scanner",
+					"src":    "PrepareScanFuncs",
+				}).Warningf("Unknown protocol %s for target %s:%d, skipping", service.GetProto(), service.GetRhost(), service.GetPort())
 			}
+		}
+
+		// TCP targets in scope are scanned by the configured TCP port scanner
+		tcpTargets := &nraySchema.MoreWorkReply{
+			Batchid: targetMsg.Batchid,
+			Targets: &nraySchema.ScanTargets{
+				Rhosts:    inScope,
+				Tcpports:  targetMsg.Targets.GetTcpports(),
+				Services:  tcpServices,
+				Hostnames: hostnames,
+			},
+		}
+		for scanTask := range tcpscanner.PrepareScanFuncs(tcpTargets, results) {
+			scanFuncs <- scanTask
+		}
+
+		for _, target := range inScope {
 			for _, targetUDPPort := range targetMsg.Targets.GetUdpports() {
 				t := target
 				port := targetUDPPort
-				scanFuncs <- func() {
+				hostname := hostnames[target]
+				scanFuncs <- &ScanTask{Host: t, Run: func() {
 					result, err := UDPProtoScan(t, port, *udpscanner)
 					utils.CheckError(err, false)
-					results <- result
-				}
+					results <- withHostname(result, hostname)
+				}}
 			}
 		}
+		for _, service := range udpServices {
+			t := service.GetRhost()
+			port := service.GetPort()
+			hostname := service.GetHostname()
+			scanFuncs <- &ScanTask{Host: t, Run: func() {
+				result, err := UDPProtoScan(t, port, *udpscanner)
+				utils.CheckError(err, false)
+				results <- withHostname(result, hostname)
+			}}
+		}
 		close(scanFuncs)
 	}(targetMsg, results)
 
 	return scanFuncs
 }
+
+// waitForProbe blocks until the node's rate limit and the politeness limits allow
+// another probe to host. Workers take the token for the first probe of each task,
+// this is for everything a task sends beyond that, e.g. retransmissions
+func (controller *ScanController) waitForProbe(host string) {
+	controller.ratelimiter.Wait(context.TODO())
+	controller.politeness.wait(host)
+}
@@ -0,0 +1,248 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	nraySchema "github.com/nray-scanner/nray/schemas"
+	"github.com/nray-scanner/nray/utils"
+)
+
+// NodeScope is the node's own idea of what it is allowed to scan.
+// It is enforced regardless of what the server sends, so a misconfigured
+// or compromised server can't point the node at arbitrary targets.
+// Deny entries always win. If there are allow entries, targets must match
+// at least one of them
+type NodeScope struct {
+	allowNets    []*net.IPNet
+	allowDomains []string
+	denyNets     []*net.IPNet
+	denyDomains  []string
+	// Strict aborts the whole batch if any target is out of scope
+	Strict bool
+	// Resolves names for the check, ScanController.SetScope sets the node's resolver
+	lookup func(name string) ([]string, error)
+}
+
+// NewNodeScope parses the allow and deny entries. Entries may be IP addresses,
+// CIDR networks or DNS names. A DNS name matches itself and all subdomains
+func NewNodeScope(allow []string, deny []string, strict bool) (*NodeScope, error) {
+	scope := &NodeScope{Strict: strict, lookup: lookupHost}
+	var err error
+	if scope.allowNets, scope.allowDomains, err = parseScopeEntries(allow); err != nil {
+		return nil, err
+	}
+	if scope.denyNets, scope.denyDomains, err = parseScopeEntries(deny); err != nil {
+		return nil, err
+	}
+	return scope, nil
+}
+
+// LoadScopeFile reads scope entries from a file, one per line.
+// Empty lines and lines starting with # are ignored
+func LoadScopeFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	entries := make([]string, 0)
+	lineScanner := bufio.NewScanner(file)
+	for lineScanner.Scan() {
+		line := strings.TrimSpace(lineScanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	return entries, lineScanner.Err()
+}
+
+func parseScopeEntries(entries []string) ([]*net.IPNet, []string, error) {
+	nets := make([]*net.IPNet, 0)
+	domains := make([]string, 0)
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if _, ipnet, err := net.ParseCIDR(entry); err == nil {
+			nets = append(nets, ipnet)
+		} else if ip := net.ParseIP(entry); ip != nil {
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		} else if utils.MayBeFQDN(entry) {
+			domains = append(domains, strings.TrimSuffix(strings.TrimPrefix(entry, "*."), "."))
+		} else {
+			return nil, nil, fmt.Errorf("Invalid scope entry: %s", entry)
+		}
+	}
+	return nets, domains, nil
+}
+
+func lookupHost(name string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return net.DefaultResolver.LookupHost(ctx, name)
+}
+
+// Enabled returns false if neither allow nor deny entries are configured
+func (scope *NodeScope) Enabled() bool {
+	return scope != nil && len(scope.allowNets)+len(scope.allowDomains)+len(scope.denyNets)+len(scope.denyDomains) > 0
+}
+
+// Check returns an empty string if the target may be scanned, otherwise the
+// reason why it may not. DNS names are resolved and each of their addresses
+// is checked. hostname is the name the server claims to have resolved the target
+// from. It is only used to deny targets, never to allow them, because it can't be verified
+func (scope *NodeScope) Check(target string, hostname string) string {
+	_, reason := scope.check(target, hostname)
+	return reason
+}
+
+// check works like Check and additionally returns the addresses that were
+// checked. Names must be scanned on exactly these addresses, resolving them
+// again could return addresses that were not checked
+func (scope *NodeScope) check(target string, hostname string) ([]string, string) {
+	if !scope.Enabled() {
+		return []string{target}, ""
+	}
+	for _, name := range []string{hostname, target} {
+		if domain := matchDomain(scope.denyDomains, name); domain != "" {
+			return nil, fmt.Sprintf("%s is denied by %s", name, domain)
+		}
+	}
+
+	addresses := []string{target}
+	nameAllowed := false
+	if net.ParseIP(target) == nil {
+		var err error
+		addresses, err = scope.lookup(target)
+		if err != nil || len(addresses) == 0 {
+			return nil, fmt.Sprintf("%s can't be resolved to check the scope", target)
+		}
+		nameAllowed = matchDomain(scope.allowDomains, target) != ""
+	}
+	for _, address := range addresses {
+		ip := net.ParseIP(address)
+		if network := matchNet(scope.denyNets, ip); network != "" {
+			return nil, fmt.Sprintf("%s is denied by %s", address, network)
+		}
+		if len(scope.allowNets)+len(scope.allowDomains) > 0 && !nameAllowed && matchNet(scope.allowNets, ip) == "" {
+			return nil, fmt.Sprintf("%s is not on the allow list", address)
+		}
+	}
+	return addresses, ""
+}
+
+func matchDomain(domains []string, name string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if name == "" {
+		return ""
+	}
+	for _, domain := range domains {
+		if name == domain || strings.HasSuffix(name, "."+domain) {
+			return domain
+		}
+	}
+	return ""
+}
+
+func matchNet(nets []*net.IPNet, ip net.IP) string {
+	if ip == nil {
+		return ""
+	}
+	for _, network := range nets {
+		if network.Contains(ip) {
+			return network.String()
+		}
+	}
+	return ""
+}
+
+// apply checks all targets of a batch. It returns the targets that may be
+// scanned and those that must not be, keyed by target. DNS names are replaced
+// by all addresses they were checked on, the names are kept as their hostnames
+func (scope *NodeScope) apply(targets *nraySchema.ScanTargets) (*nraySchema.ScanTargets, map[string]*nraySchema.SkippedTarget) {
+	skipped := make(map[string]*nraySchema.SkippedTarget)
+	if !scope.Enabled() {
+		return targets, skipped
+	}
+	inScope := &nraySchema.ScanTargets{
+		Rhosts:    make([]string, 0, len(targets.GetRhosts())),
+		Tcpports:  targets.GetTcpports(),
+		Udpports:  targets.GetUdpports(),
+		Services:  make([]*nraySchema.ServiceTarget, 0, len(targets.GetServices())),
+		Hostnames: make(map[string]string),
+	}
+	for address, hostname := range targets.GetHostnames() {
+		inScope.Hostnames[address] = hostname
+	}
+	checked := make(map[string][]string)
+	// checkTarget checks a target once and returns the addresses it may be scanned on
+	checkTarget := func(target string, hostname string) []string {
+		if addresses, exists := checked[target]; exists {
+			return addresses
+		}
+		addresses, reason := scope.check(target, hostname)
+		checked[target] = addresses
+		if reason != "" {
+			skipped[target] = &nraySchema.SkippedTarget{
+				Target:   target,
+				Hostname: hostname,
+				Reason:   reason,
+			}
+			return nil
+		}
+		if net.ParseIP(target) == nil {
+			for _, address := range addresses {
+				inScope.Hostnames[address] = target
+			}
+		}
+		return addresses
+	}
+
+	added := make(map[string]bool)
+	for _, target := range targets.GetRhosts() {
+		for _, address := range checkTarget(target, targets.GetHostnames()[target]) {
+			if !added[address] {
+				added[address] = true
+				inScope.Rhosts = append(inScope.Rhosts, address)
+			}
+		}
+		if skippedTarget, exists := skipped[target]; exists && skippedTarget.Tcpports == nil && skippedTarget.Udpports == nil {
+			skippedTarget.Tcpports = targets.GetTcpports()
+			skippedTarget.Udpports = targets.GetUdpports()
+		}
+	}
+	for _, service := range targets.GetServices() {
+		target := service.GetRhost()
+		for _, address := range checkTarget(target, service.GetHostname()) {
+			inScopeService := proto.Clone(service).(*nraySchema.ServiceTarget)
+			inScopeService.Rhost = address
+			if address != target {
+				inScopeService.Hostname = target
+			}
+			inScope.Services = append(inScope.Services, inScopeService)
+		}
+		if skippedTarget, exists := skipped[target]; exists {
+			switch strings.ToLower(service.GetProto()) {
+			case "tcp":
+				skippedTarget.Tcpports = append(skippedTarget.Tcpports, service.GetPort())
+			case "udp":
+				skippedTarget.Udpports = append(skippedTarget.Udpports, service.GetPort())
+			}
+		}
+	}
+	return inScope, skipped
+}
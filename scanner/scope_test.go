@@ -0,0 +1,168 @@
+package scanner
+
+import (
+	"fmt"
+	"testing"
+
+	nraySchema "github.com/nray-scanner/nray/schemas"
+	"github.com/spf13/viper"
+)
+
+func testScope(t *testing.T, allow []string, deny []string) *NodeScope {
+	scope, err := NewNodeScope(allow, deny, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := map[string][]string{
+		"web.example.test":    {"10.0.0.1"},
+		"evil.example.test":   {"192.0.2.1"},
+		"mixed.example.test":  {"10.0.0.2", "10.0.1.1"},
+		"mail.partner.test":   {"198.51.100.1"},
+		"www.internal.test":   {"10.0.0.3"},
+		"admin.internal.test": {"10.0.0.4"},
+	}
+	scope.lookup = func(name string) ([]string, error) {
+		if addresses, exists := records[name]; exists {
+			return addresses, nil
+		}
+		return nil, fmt.Errorf("%s does not exist", name)
+	}
+	return scope
+}
+
+func TestNodeScopeCheck(t *testing.T) {
+	scope := testScope(t, []string{"10.0.0.0/24", "partner.test"}, []string{"10.0.0.4", "admin.internal.test"})
+	testcases := []struct {
+		target   string
+		hostname string
+		allowed  bool
+	}{
+		{"10.0.0.1", "", true},
+		{"10.0.1.1", "", false},
+		{"10.0.0.4", "", false},
+		{"web.example.test", "", true},
+		{"evil.example.test", "", false},
+		{"mixed.example.test", "", false},
+		{"mail.partner.test", "", true},
+		{"missing.example.test", "", false},
+		{"www.internal.test", "", true},
+		// The server claims a denied name
+		{"10.0.0.3", "admin.internal.test", false},
+		// The server claims an allowed name, but the address is not allowed
+		{"198.51.100.1", "mail.partner.test", false},
+	}
+	for _, testcase := range testcases {
+		reason := scope.Check(testcase.target, testcase.hostname)
+		if (reason == "") != testcase.allowed {
+			t.Errorf("%s (%s): expected allowed=%t, reason: %s", testcase.target, testcase.hostname, testcase.allowed, reason)
+		}
+	}
+
+	// Without allow entries, everything not denied is allowed
+	scope = testScope(t, nil, []string{"192.0.2.0/24"})
+	if scope.Check("10.0.1.1", "") != "" || scope.Check("evil.example.test", "") == "" {
+		t.Errorf("Deny list not applied correctly")
+	}
+
+	if !testScope(t, []string{"*.example.test"}, nil).Enabled() || testScope(t, nil, nil).Enabled() {
+		t.Errorf("Wrong enabled state")
+	}
+	if _, err := NewNodeScope([]string{"http://10.0.0.1/"}, nil, false); err == nil {
+		t.Errorf("Invalid entry should result in an error")
+	}
+}
+
+func TestApplyScope(t *testing.T) {
+	targets := &nraySchema.MoreWorkReply{
+		Batchid: 1,
+		Targets: &nraySchema.ScanTargets{
+			Rhosts:   []string{"10.0.0.1", "10.0.1.1"},
+			Tcpports: []uint32{22},
+			Services: []*nraySchema.ServiceTarget{
+				{Rhost: "10.0.0.2", Proto: "tcp", Port: 80},
+				{Rhost: "192.0.2.1", Proto: "tcp", Port: 80},
+				{Rhost: "192.0.2.1", Proto: "udp", Port: 53},
+			},
+		},
+	}
+	for _, strict := range []bool{false, true} {
+		scope := testScope(t, []string{"10.0.0.0/24"}, nil)
+		scope.Strict = strict
+		skipped := make(map[string]*nraySchema.SkippedTarget)
+		inScope := applyScope(scope, targets, func(target *nraySchema.SkippedTarget) {
+			skipped[target.Target] = target
+		})
+		if len(skipped) != 2 || skipped["10.0.1.1"] == nil || skipped["192.0.2.1"] == nil {
+			t.Fatalf("Wrong targets skipped: %v", skipped)
+		}
+		if len(skipped["192.0.2.1"].Tcpports) != 1 || len(skipped["192.0.2.1"].Udpports) != 1 || skipped["192.0.2.1"].BatchAborted != strict {
+			t.Errorf("Wrong skipped target: %v", skipped["192.0.2.1"])
+		}
+		scans := len(inScope.Targets.GetRhosts()) + len(inScope.Targets.GetServices())
+		if !strict && scans != 2 {
+			t.Errorf("Expected 2 scans for targets in scope, got %d", scans)
+		}
+		if strict && scans != 0 {
+			t.Errorf("Strict mode should abort the batch, got %d scans", scans)
+		}
+	}
+}
+
+func TestNodeScopeApply(t *testing.T) {
+	scope := testScope(t, []string{"10.0.0.0/24"}, nil)
+	lookups := 0
+	lookup := scope.lookup
+	scope.lookup = func(name string) ([]string, error) {
+		lookups++
+		return lookup(name)
+	}
+	targets, skipped := scope.apply(&nraySchema.ScanTargets{
+		Rhosts:   []string{"web.example.test", "10.0.0.1", "mixed.example.test"},
+		Tcpports: []uint32{22},
+		Services: []*nraySchema.ServiceTarget{{Rhost: "web.example.test", Proto: "tcp", Port: 443}},
+	})
+	if len(targets.Rhosts) != 1 || targets.Rhosts[0] != "10.0.0.1" || targets.Hostnames["10.0.0.1"] != "web.example.test" {
+		t.Errorf("Names must be replaced by the checked addresses, got %v", targets)
+	}
+	if len(targets.Services) != 1 || targets.Services[0].Rhost != "10.0.0.1" || targets.Services[0].Hostname != "web.example.test" {
+		t.Errorf("Service names must be replaced by the checked addresses, got %v", targets.Services)
+	}
+	if len(skipped) != 1 || skipped["mixed.example.test"] == nil || len(skipped["mixed.example.test"].Tcpports) != 1 {
+		t.Errorf("Wrong targets skipped: %v", skipped)
+	}
+	if lookups != 2 {
+		t.Errorf("Each name must be resolved once, got %d lookups", lookups)
+	}
+}
+
+func TestScanBatchScansCheckedAddresses(t *testing.T) {
+	server := startStubNameserver(t, map[string][]string{"local.example.test": {"127.0.0.1"}}, nil)
+	_, port := startBannerServer(t)
+	config := viper.New()
+	config.Set("workers", 2)
+	config.Set("resolver.nameservers", []string{server.address})
+	config.Set("resolver.addressFamily", "ipv4")
+	controller, err := CreateScanController("test", "test", 0, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scope, err := NewNodeScope([]string{"127.0.0.1"}, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	controller.SetScope(scope)
+	results := controller.ScanBatch(&nraySchema.MoreWorkReply{Batchid: 1, Targets: &nraySchema.ScanTargets{
+		Rhosts:   []string{"local.example.test"},
+		Tcpports: []uint32{port},
+	}})
+	if len(results) != 1 {
+		t.Fatalf("Expected a single result, got %v", results)
+	}
+	if result := results[0].GetResult(); result.GetTarget() != "127.0.0.1" || result.GetHostname() != "local.example.test" || !result.GetPortscan().GetOpen() {
+		t.Errorf("Expected the checked address to be scanned, got %v", result)
+	}
+	// The name is resolved once for the scope check by the node's resolver and not again for scanning
+	if count := server.queryCount("udp/TypeA/local.example.test"); count != 1 {
+		t.Errorf("Expected a single query, got %d", count)
+	}
+}
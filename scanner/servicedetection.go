@@ -0,0 +1,196 @@
+package scanner
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	nraySchema "github.com/nray-scanner/nray/schemas"
+	"github.com/nray-scanner/nray/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	protocolScanners["servicedetection"] = func() ProtocolScanner { return &ServiceDetector{} }
+}
+
+// ServiceDetector identifies services and their versions on open ports with
+// probes and matches in nmap-service-probes format
+type ServiceDetector struct {
+	nodeID          string
+	nodeName        string
+	probes          *serviceProbes
+	intensity       int
+	timeout         time.Duration
+	maxResponseSize int
+	tcp             bool
+	udp             bool
+	controller      *ScanController
+}
+
+// Configure parses the probes and reads the configuration. If the probes sent
+// by the server can't be parsed, the built-in ones are used
+func (detector *ServiceDetector) Configure(config *viper.Viper, nodeID string, nodeName string) {
+	config = utils.ApplyDefaultScannerServiceDetectionConfig(config)
+	detector.nodeID = nodeID
+	detector.nodeName = nodeName
+	content := config.GetString("probes")
+	if content == "" {
+		content = builtinServiceProbes
+	}
+	probes, err := parseServiceProbes(content)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"module": "scanner.servicedetection",
+			"src":    "Configure",
+		}).Errorf("Invalid service probes, using the built-in ones: %v", err)
+		probes, err = parseServiceProbes(builtinServiceProbes)
+		utils.CheckError(err, true)
+	}
+	if probes.skippedMatches > 0 {
+		log.WithFields(log.Fields{
+			"module": "scanner.servicedetection",
+			"src":    "Configure",
+		}).Infof("Skipped %d matches using regular expression features Go doesn't support", probes.skippedMatches)
+	}
+	detector.probes = probes
+	detector.intensity = min(max(config.GetInt("intensity"), 0), 9)
+	detector.timeout = config.GetDuration("timeout")
+	detector.maxResponseSize = config.GetInt("maxResponseSize")
+	detector.tcp = config.GetBool("tcp")
+	detector.udp = config.GetBool("udp")
+}
+
+// Register subscribes to all open TCP and UDP ports
+func (detector *ServiceDetector) Register(controller *ScanController) {
+	detector.controller = controller
+	if detector.tcp {
+		controller.Subscribe("tcp/*", detector.scanFunc)
+	}
+	if detector.udp {
+		controller.Subscribe("udp/*", detector.scanFunc)
+	}
+}
+
+// scanFunc returns a function detecting the service on a port and reporting it to results
+func (detector *ServiceDetector) scanFunc(proto string, host string, port uint, results chan<- *nraySchema.Event) func() {
+	return func() {
+		result := detector.detect(proto, host, port)
+		if result == nil {
+			log.WithFields(log.Fields{
+				"module": "scanner.servicedetection",
+				"src":    "scanFunc",
+			}).Debugf("No service identified on %s/%s:%d", proto, host, port)
+			return
+		}
+		timestamp, _ := ptypes.TimestampProto(currentTime())
+		results <- &nraySchema.Event{
+			NodeID:   detector.nodeID,
+			NodeName: detector.nodeName,
+			EventData: &nraySchema.Event_Result{
+				Result: &nraySchema.ScanResult{
+					Target:   host,
+					Port:     uint32(port),
+					Hostname: detector.controller.Hostname(host),
+					Result: &nraySchema.ScanResult_Service{
+						Service: &nraySchema.ServiceDetectionResult{
+							Protocol:   proto,
+							Service:    result.service,
+							Product:    result.product,
+							Version:    result.version,
+							Info:       result.info,
+							Hostname:   result.hostname,
+							Os:         result.os,
+							Devicetype: result.devicetype,
+							Cpe:        result.cpes,
+							Probe:      result.probe,
+							Softmatch:  result.soft,
+						},
+					},
+				},
+			},
+			Scannername: "service-detection",
+			Timestamp:   timestamp,
+		}
+	}
+}
+
+// detect sends the probes for a port one after another until a response matches.
+// After a softmatch, only probes that may identify the version of that service are sent.
+// Each probe is a connection of its own and counts against the rate limits
+func (detector *ServiceDetector) detect(proto string, host string, port uint) *serviceMatchResult {
+	address := net.JoinHostPort(host, fmt.Sprint(port))
+	var soft *serviceMatchResult
+	connections := 0
+	for _, probe := range detector.probes.forPort(proto, uint32(port), detector.intensity) {
+		if soft != nil && !detector.probes.canMatch(probe, soft.service) {
+			continue
+		}
+		if connections > 0 {
+			detector.controller.waitForProbe(host)
+		}
+		connections++
+		conn, err := detector.dial(proto, address)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"module": "scanner.servicedetection",
+				"src":    "detect",
+			}).Debugf("Connecting to %s/%s failed: %v", proto, address, err)
+			return soft
+		}
+		result := detector.exchange(conn, probe)
+		conn.Close()
+		if result == nil {
+			continue
+		}
+		if !result.soft {
+			return result
+		}
+		if soft == nil {
+			soft = result
+		}
+	}
+	return soft
+}
+
+// dial connects to a target like the port scanners do
+func (detector *ServiceDetector) dial(proto string, address string) (net.Conn, error) {
+	if proto == "tcp" {
+		return detector.controller.Dial("tcp", address, detector.timeout)
+	}
+	address, err := detector.controller.udpScanner.resolver.resolveAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return detector.controller.udpScanner.source.dial("udp", address, detector.timeout)
+}
+
+// exchange sends the payload of a probe and reads the response until it matches,
+// the connection is closed or the probe's wait time is over. Only a single
+// datagram is read for UDP probes
+func (detector *ServiceDetector) exchange(conn net.Conn, probe *serviceProbe) *serviceMatchResult {
+	conn.SetDeadline(time.Now().Add(min(probe.wait, detector.timeout)))
+	if len(probe.payload) > 0 {
+		if _, err := conn.Write(probe.payload); err != nil {
+			return nil
+		}
+	}
+	response := make([]byte, 0)
+	buf := make([]byte, 4096)
+	var result *serviceMatchResult
+	for len(response) < detector.maxResponseSize {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			response = append(response, buf[:n]...)
+			if result = detector.probes.match(probe, response); result != nil && !result.soft {
+				return result
+			}
+		}
+		if err != nil || probe.protocol == "udp" {
+			break
+		}
+	}
+	return result
+}
@@ -0,0 +1,157 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	nraySchema "github.com/nray-scanner/nray/schemas"
+	"github.com/spf13/viper"
+)
+
+// startServiceServer accepts connections, sends banner and answers
+// the first line a client sends with response
+func startServiceServer(t *testing.T, banner string, response string) uint32 {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				conn.Write([]byte(banner))
+				conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+				if _, err := bufio.NewReader(conn).ReadString('\n'); err == nil {
+					conn.Write([]byte(response))
+				}
+			}(conn)
+		}
+	}()
+	return uint32(listener.Addr().(*net.TCPAddr).Port)
+}
+
+func TestServiceDetection(t *testing.T) {
+	sshPort := startServiceServer(t, "SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13.5\r\n", "")
+	httpPort := startServiceServer(t, "", "HTTP/1.1 200 OK\r\nServer: Apache/2.4.58 (Ubuntu)\r\nContent-Length: 0\r\n\r\n")
+	unknownPort := startServiceServer(t, "", "?\n")
+
+	config := viper.New()
+	config.Set("workers", 10)
+	config.Set("tcp.timeout", "500ms")
+	config.Set("servicedetection", map[string]interface{}{"enabled": true, "timeout": "300ms", "udp": false})
+	controller, err := CreateScanController("test", "test", 0, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := controller.ScanBatch(&nraySchema.MoreWorkReply{Batchid: 1, Targets: &nraySchema.ScanTargets{
+		Rhosts:   []string{"127.0.0.1"},
+		Tcpports: []uint32{sshPort, httpPort, unknownPort},
+	}})
+
+	services := make(map[uint32]*nraySchema.ServiceDetectionResult)
+	for _, event := range results {
+		if service := event.GetResult().GetService(); service != nil {
+			if event.Scannername != "service-detection" || event.GetResult().GetTarget() != "127.0.0.1" {
+				t.Errorf("Unexpected service event %v", event)
+			}
+			services[event.GetResult().GetPort()] = service
+		}
+	}
+	if len(services) != 2 {
+		t.Fatalf("Expected services on 2 ports, got %v", services)
+	}
+	ssh := services[sshPort]
+	if ssh.GetService() != "ssh" || ssh.GetProduct() != "OpenSSH" || ssh.GetVersion() != "9.6p1" || ssh.GetProbe() != "NULL" || ssh.GetProtocol() != "tcp" ||
+		len(ssh.GetCpe()) != 1 || ssh.GetCpe()[0] != "cpe:/a:openbsd:openssh:9.6p1" || ssh.GetInfo() != "Ubuntu-3ubuntu13.5, protocol 2.0" {
+		t.Errorf("Wrong SSH service %v", ssh)
+	}
+	http := services[httpPort]
+	if http.GetService() != "http" || http.GetProduct() != "Apache httpd" || http.GetVersion() != "2.4.58" || http.GetInfo() != "(Ubuntu)" || http.GetProbe() != "GetRequest" || http.GetSoftmatch() {
+		t.Errorf("Wrong HTTP service %v", http)
+	}
+}
+
+func TestServiceDetectionUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if string(buf[:n]) == "status" {
+				conn.WriteTo([]byte("ok"), addr)
+			}
+		}
+	}()
+	port := uint(conn.LocalAddr().(*net.UDPAddr).Port)
+
+	config := viper.New()
+	config.Set("probes", "Probe UDP Other q|other|\nrarity 1\nmatch other m|^ok|\n\nProbe UDP Status q|status|\nrarity 1\nsoftmatch status m|^ok|\n")
+	config.Set("timeout", "300ms")
+	detector := &ServiceDetector{}
+	detector.Configure(config, "test", "test")
+	controller, err := CreateScanController("test", "test", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	detector.Register(controller)
+	result := detector.detect("udp", "127.0.0.1", port)
+	if result == nil || result.service != "status" || !result.soft || result.probe != "Status" {
+		t.Errorf("Expected a softmatch by the status probe, got %+v", result)
+	}
+}
+
+func TestServiceDetectorInvalidProbes(t *testing.T) {
+	config := viper.New()
+	config.Set("probes", "Probe TCP NULL")
+	detector := &ServiceDetector{}
+	detector.Configure(config, "test", "test")
+	if len(detector.probes.forPort("tcp", 22, 7)) == 0 {
+		t.Errorf("Expected the built-in probes for invalid ones")
+	}
+}
+
+func TestServiceDetectorRatelimitsConnections(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	port := uint(conn.LocalAddr().(*net.UDPAddr).Port)
+
+	config := viper.New()
+	config.Set("probes", "Probe UDP First q|first|\nrarity 1\nmatch first m|^ok|\n\nProbe UDP Second q|second|\nrarity 1\nmatch second m|^ok|\n")
+	config.Set("timeout", "50ms")
+	detector := &ServiceDetector{}
+	detector.Configure(config, "test", "test")
+	controller, err := CreateScanController("test", "test", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	controller.Refresh()
+	// Both probes time out after 50ms, the second one has to wait for a token first
+	controller.ratelimiter.SetLimit(5)
+	controller.ratelimiter.Wait(context.TODO())
+	detector.Register(controller)
+	start := time.Now()
+	if result := detector.detect("udp", "127.0.0.1", port); result != nil {
+		t.Fatalf("Expected no match, got %+v", result)
+	}
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("Expected the second probe to wait for the rate limit, took %s", elapsed)
+	}
+}
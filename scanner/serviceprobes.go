@@ -0,0 +1,580 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/viper"
+)
+
+// Rarity of probes that don't set one, and how long they wait for a response
+const (
+	defaultServiceProbeRarity = 5
+	defaultServiceProbeWait   = 5 * time.Second
+)
+
+// serviceProbe is a probe of an nmap-service-probes file
+type serviceProbe struct {
+	protocol string
+	name     string
+	payload  []byte
+	// Ports the probe is most likely to succeed on, it is sent to them first
+	ports    map[uint32]bool
+	rarity   int
+	wait     time.Duration
+	fallback []string
+	matches  []*serviceMatch
+}
+
+// serviceMatch is a match or softmatch directive of a probe
+type serviceMatch struct {
+	service string
+	soft    bool
+	pattern *regexp.Regexp
+	// Version templates keyed by their field letter: p, v, i, h, o and d
+	templates map[byte]string
+	cpes      []string
+}
+
+// serviceProbes is a parsed nmap-service-probes file
+type serviceProbes struct {
+	probes []*serviceProbe
+	byName map[string]*serviceProbe
+	// Ports excluded from service detection, keyed by protocol
+	excluded map[string]map[uint32]bool
+	// Matches that could not be compiled, e.g. because they use backreferences
+	skippedMatches int
+}
+
+// serviceMatchResult is what a match extracted from a response
+type serviceMatchResult struct {
+	probe      string
+	service    string
+	soft       bool
+	product    string
+	version    string
+	info       string
+	hostname   string
+	os         string
+	devicetype string
+	cpes       []string
+}
+
+// parseServiceProbes parses the nmap-service-probes format. Regular expressions
+// are translated to Go's syntax where possible, matches using PCRE features
+// without equivalent like backreferences or lookarounds are skipped
+func parseServiceProbes(content string) (*serviceProbes, error) {
+	probes := &serviceProbes{
+		byName:   make(map[string]*serviceProbe),
+		excluded: map[string]map[uint32]bool{"tcp": {}, "udp": {}},
+	}
+	var probe *serviceProbe
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		directive, rest, _ := strings.Cut(text, " ")
+		rest = strings.TrimSpace(rest)
+		var err error
+		switch {
+		case directive == "Exclude":
+			err = probes.parseExclude(rest)
+		case directive == "Probe":
+			if probe, err = parseProbeDirective(rest); err == nil {
+				if probes.byName[probe.protocol+"/"+probe.name] != nil {
+					err = fmt.Errorf("duplicate probe %s", probe.name)
+				}
+				probes.probes = append(probes.probes, probe)
+				probes.byName[probe.protocol+"/"+probe.name] = probe
+			}
+		case probe == nil:
+			err = fmt.Errorf("%s outside of a probe", directive)
+		case directive == "match" || directive == "softmatch":
+			var match *serviceMatch
+			match, err = parseMatchDirective(rest, directive == "softmatch")
+			if match != nil {
+				probe.matches = append(probe.matches, match)
+			} else if err == nil {
+				probes.skippedMatches++
+			}
+		case directive == "ports":
+			err = addPorts(probe.ports, rest)
+		case directive == "sslports":
+			// TLS is not spoken, so these ports are treated like all others
+		case directive == "rarity":
+			if probe.rarity, err = strconv.Atoi(rest); err == nil && (probe.rarity < 1 || probe.rarity > 9) {
+				err = fmt.Errorf("rarity %d is not between 1 and 9", probe.rarity)
+			}
+		case directive == "totalwaitms":
+			var wait int
+			wait, err = strconv.Atoi(rest)
+			probe.wait = time.Duration(wait) * time.Millisecond
+		case directive == "tcpwrappedms":
+			// Closed connections are not reported as tcpwrapped
+		case directive == "fallback":
+			probe.fallback = strings.Split(rest, ",")
+		default:
+			err = fmt.Errorf("unknown directive %s", directive)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for _, probe := range probes.probes {
+		for _, fallback := range probe.fallback {
+			if probes.byName[probe.protocol+"/"+strings.TrimSpace(fallback)] == nil {
+				return nil, fmt.Errorf("probe %s falls back to unknown probe %s", probe.name, fallback)
+			}
+		}
+	}
+	return probes, nil
+}
+
+// parseExclude reads ports like T:9100-9107,U:30000-40000 or 80. Ports without
+// protocol are excluded for TCP and UDP
+func (probes *serviceProbes) parseExclude(list string) error {
+	protocols := []string{"tcp", "udp"}
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		switch {
+		case strings.HasPrefix(item, "T:"):
+			protocols, item = []string{"tcp"}, item[2:]
+		case strings.HasPrefix(item, "U:"):
+			protocols, item = []string{"udp"}, item[2:]
+		}
+		for _, protocol := range protocols {
+			if err := addPorts(probes.excluded[protocol], item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addPorts adds a list of ports and port ranges to a set
+func addPorts(set map[uint32]bool, list string) error {
+	ports, err := parsePortList(list)
+	if err != nil {
+		return err
+	}
+	for _, port := range ports {
+		set[port] = true
+	}
+	return nil
+}
+
+// parseProbeDirective reads a probe like TCP GetRequest q|GET / HTTP/1.0\r\n\r\n|
+func parseProbeDirective(rest string) (*serviceProbe, error) {
+	fields := strings.SplitN(rest, " ", 3)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("expected Probe <protocol> <name> q|payload|")
+	}
+	probe := &serviceProbe{
+		protocol: strings.ToLower(fields[0]),
+		name:     fields[1],
+		ports:    make(map[uint32]bool),
+		rarity:   defaultServiceProbeRarity,
+		wait:     defaultServiceProbeWait,
+	}
+	if probe.protocol != "tcp" && probe.protocol != "udp" {
+		return nil, fmt.Errorf("unknown protocol %s", fields[0])
+	}
+	if !strings.HasPrefix(fields[2], "q") {
+		return nil, fmt.Errorf("probe %s has no payload", probe.name)
+	}
+	quoted, _, err := delimited(fields[2][1:])
+	if err != nil {
+		return nil, fmt.Errorf("probe %s: %v", probe.name, err)
+	}
+	for i := 0; i < len(quoted); i++ {
+		if quoted[i] != '\\' {
+			probe.payload = append(probe.payload, quoted[i])
+			continue
+		}
+		decoded, end, err := nmapEscape(quoted, i+1)
+		if err != nil {
+			return nil, fmt.Errorf("probe %s: %v", probe.name, err)
+		}
+		probe.payload = append(probe.payload, decoded)
+		i = end
+	}
+	return probe, nil
+}
+
+// parseMatchDirective reads a match like ssh m|^SSH-([\d.]+)-OpenSSH_(\S+)|i p/OpenSSH/ v/$2/.
+// It returns nil without error if the pattern can't be used with Go's regular expressions
+func parseMatchDirective(rest string, soft bool) (*serviceMatch, error) {
+	service, rest, _ := strings.Cut(rest, " ")
+	if !strings.HasPrefix(rest, "m") {
+		return nil, fmt.Errorf("match for %s has no pattern", service)
+	}
+	pattern, rest, err := delimited(rest[1:])
+	if err != nil {
+		return nil, fmt.Errorf("match for %s: %v", service, err)
+	}
+	flags := ""
+	for len(rest) > 0 && rest[0] != ' ' {
+		switch rest[0] {
+		case 'i', 's':
+			flags += rest[:1]
+		default:
+			return nil, fmt.Errorf("match for %s has unknown flag %c", service, rest[0])
+		}
+		rest = rest[1:]
+	}
+	match := &serviceMatch{service: service, soft: soft, templates: make(map[byte]string)}
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+		var value string
+		if strings.HasPrefix(rest, "cpe:") {
+			if value, rest, err = delimited(rest[4:]); err != nil {
+				return nil, fmt.Errorf("match for %s: %v", service, err)
+			}
+			// The a flag marks application CPEs, nmap only uses it for sorting
+			rest = strings.TrimPrefix(rest, "a")
+			match.cpes = append(match.cpes, "cpe:/"+value)
+			continue
+		}
+		key := rest[0]
+		if !strings.ContainsRune("pvihod", rune(key)) || len(rest) < 2 {
+			return nil, fmt.Errorf("match for %s has unknown version field %s", service, rest)
+		}
+		if value, rest, err = delimited(rest[1:]); err != nil {
+			return nil, fmt.Errorf("match for %s: %v", service, err)
+		}
+		match.templates[key] = value
+	}
+	if match.pattern, err = compileNmapPattern(pattern, flags); err != nil {
+		return nil, nil
+	}
+	return match, nil
+}
+
+// delimited returns the text between the first character of s and its next
+// occurrence, and what follows
+func delimited(s string) (string, string, error) {
+	if s == "" {
+		return "", "", fmt.Errorf("missing delimiter")
+	}
+	end := strings.IndexByte(s[1:], s[0])
+	if end < 0 {
+		return "", "", fmt.Errorf("unterminated %c", s[0])
+	}
+	return s[1 : end+1], s[end+2:], nil
+}
+
+// compileNmapPattern translates a PCRE pattern as used by nmap. Responses are
+// matched as Latin-1 strings, so each byte is a single character and escapes
+// like \xff match the byte, not the UTF-8 sequence of ÿ
+func compileNmapPattern(pattern string, flags string) (*regexp.Regexp, error) {
+	var translated strings.Builder
+	if flags != "" {
+		translated.WriteString("(?" + flags + ")")
+	}
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			switch pattern[i] {
+			case 'Z':
+				translated.WriteString(`\n?\z`)
+			case 'e':
+				translated.WriteString(`\x1b`)
+			case 'h':
+				if inClass {
+					translated.WriteString(`\t `)
+				} else {
+					translated.WriteString(`[\t ]`)
+				}
+			default:
+				translated.WriteByte('\\')
+				translated.WriteString(latin1(pattern[i : i+1]))
+			}
+		case c == '[' && !inClass:
+			inClass = true
+			translated.WriteByte(c)
+			// A ] right after [ or [^ is a literal
+			if strings.HasPrefix(pattern[i+1:], "^]") {
+				translated.WriteString(`^\]`)
+				i += 2
+			} else if strings.HasPrefix(pattern[i+1:], "]") {
+				translated.WriteString(`\]`)
+				i++
+			}
+		case c == ']' && inClass:
+			inClass = false
+			translated.WriteByte(c)
+		case c == '$' && !inClass:
+			// Like in PCRE, $ also matches before a final newline
+			translated.WriteString(`(?:\n?\z)`)
+		case c == '+' && !inClass && i > 0 && strings.IndexByte("*+?}", pattern[i-1]) >= 0 && (i < 2 || pattern[i-2] != '\\'):
+			// Possessive quantifiers match the same as greedy ones here
+		default:
+			translated.WriteString(latin1(pattern[i : i+1]))
+		}
+	}
+	return regexp.Compile(translated.String())
+}
+
+// latin1 converts bytes to a string with one character per byte
+func latin1(data string) string {
+	runes := make([]rune, len(data))
+	for i := 0; i < len(data); i++ {
+		runes[i] = rune(data[i])
+	}
+	return string(runes)
+}
+
+// fromLatin1 reverses latin1
+func fromLatin1(s string) []byte {
+	data := make([]byte, 0, len(s))
+	for _, r := range s {
+		data = append(data, byte(r))
+	}
+	return data
+}
+
+// match checks a response against the matches of the probe, its fallbacks and, for
+// TCP, the NULL probe, because services may send their banner after the probe
+func (probes *serviceProbes) match(probe *serviceProbe, response []byte) *serviceMatchResult {
+	candidates := []*serviceProbe{probe}
+	for _, fallback := range probe.fallback {
+		candidates = append(candidates, probes.byName[probe.protocol+"/"+strings.TrimSpace(fallback)])
+	}
+	if null := probes.byName["tcp/NULL"]; probe.protocol == "tcp" && null != nil && null != probe {
+		candidates = append(candidates, null)
+	}
+	text := latin1(string(response))
+	var soft *serviceMatchResult
+	for _, candidate := range candidates {
+		for _, match := range candidate.matches {
+			groups := match.pattern.FindStringSubmatch(text)
+			if groups == nil {
+				continue
+			}
+			result := match.result(groups)
+			result.probe = probe.name
+			if !match.soft {
+				return result
+			}
+			if soft == nil {
+				soft = result
+			}
+		}
+	}
+	return soft
+}
+
+// canMatch checks if a probe or its fallbacks may identify the version of service
+func (probes *serviceProbes) canMatch(probe *serviceProbe, service string) bool {
+	candidates := []*serviceProbe{probe}
+	for _, fallback := range probe.fallback {
+		candidates = append(candidates, probes.byName[probe.protocol+"/"+strings.TrimSpace(fallback)])
+	}
+	for _, candidate := range candidates {
+		for _, match := range candidate.matches {
+			if match.service == service && !match.soft {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// forPort returns the probes to send to a port in the order they are sent.
+// For TCP, the NULL probe that waits for a banner comes first. Then follow the
+// probes listing the port, which are sent regardless of their rarity, and all
+// others with a rarity up to intensity
+func (probes *serviceProbes) forPort(protocol string, port uint32, intensity int) []*serviceProbe {
+	if probes.excluded[protocol][port] {
+		return nil
+	}
+	selected := make([]*serviceProbe, 0)
+	if null := probes.byName[protocol+"/NULL"]; null != nil {
+		selected = append(selected, null)
+	}
+	for _, probe := range probes.probes {
+		if probe.protocol == protocol && probe.name != "NULL" && probe.ports[port] {
+			selected = append(selected, probe)
+		}
+	}
+	for _, probe := range probes.probes {
+		if probe.protocol == protocol && probe.name != "NULL" && !probe.ports[port] && probe.rarity <= intensity {
+			selected = append(selected, probe)
+		}
+	}
+	return selected
+}
+
+// templateVariable matches $1, $P(1), $SUBST(1,"_",".") and $I(1,">")
+var templateVariable = regexp.MustCompile(`\$(?:(\d)|P\((\d)\)|SUBST\((\d),"([^"]*)","([^"]*)"\)|I\((\d),"([<>])"\))`)
+
+// result fills the version templates of the match with the groups of its pattern
+func (match *serviceMatch) result(groups []string) *serviceMatchResult {
+	fill := func(template string) string {
+		return templateVariable.ReplaceAllStringFunc(template, func(variable string) string {
+			parts := templateVariable.FindStringSubmatch(variable)
+			group := func(index string) []byte {
+				n, _ := strconv.Atoi(index)
+				if n >= len(groups) {
+					return nil
+				}
+				return fromLatin1(groups[n])
+			}
+			switch {
+			case parts[1] != "":
+				return printableUTF8(group(parts[1]), false)
+			case parts[2] != "":
+				return printableUTF8(group(parts[2]), true)
+			case parts[3] != "":
+				return strings.ReplaceAll(printableUTF8(group(parts[3]), false), parts[4], parts[5])
+			default:
+				value := group(parts[6])
+				if len(value) == 0 || len(value) > 8 {
+					return ""
+				}
+				padded := make([]byte, 8)
+				if parts[7] == ">" {
+					copy(padded[8-len(value):], value)
+					return strconv.FormatUint(binary.BigEndian.Uint64(padded), 10)
+				}
+				copy(padded, value)
+				return strconv.FormatUint(binary.LittleEndian.Uint64(padded), 10)
+			}
+		})
+	}
+	result := &serviceMatchResult{
+		service:    match.service,
+		soft:       match.soft,
+		product:    fill(match.templates['p']),
+		version:    fill(match.templates['v']),
+		info:       fill(match.templates['i']),
+		hostname:   fill(match.templates['h']),
+		os:         fill(match.templates['o']),
+		devicetype: fill(match.templates['d']),
+	}
+	for _, cpe := range match.cpes {
+		result.cpes = append(result.cpes, fill(cpe))
+	}
+	return result
+}
+
+// printableUTF8 returns data as string. Data that isn't valid UTF-8 is read as
+// Latin-1. With printableOnly, everything but printable ASCII is dropped like $P does
+func printableUTF8(data []byte, printableOnly bool) string {
+	if printableOnly {
+		printable := make([]byte, 0, len(data))
+		for _, b := range data {
+			if b >= 0x20 && b < 0x7f {
+				printable = append(printable, b)
+			}
+		}
+		return string(printable)
+	}
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	return latin1(string(data))
+}
+
+// LoadServiceProbeFile reads the nmap-service-probes file set as servicedetection.probeFile
+// of a scanner configuration into servicedetection.probes. This way, the probes are
+// shipped to nodes as part of the configuration and they don't need the file.
+// Without a file, probes given inline as servicedetection.probes are checked instead
+func LoadServiceProbeFile(scannerConfig *viper.Viper) error {
+	path := scannerConfig.GetString("servicedetection.probeFile")
+	if path == "" {
+		if content := scannerConfig.GetString("servicedetection.probes"); content != "" {
+			if _, err := parseServiceProbes(content); err != nil {
+				return fmt.Errorf("invalid servicedetection.probes: %v", err)
+			}
+		}
+		return nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("loading service probe file %s failed: %v", path, err)
+	}
+	if _, err := parseServiceProbes(string(content)); err != nil {
+		return fmt.Errorf("loading service probe file %s failed: %v", path, err)
+	}
+	return scannerConfig.MergeConfigMap(map[string]interface{}{
+		"servicedetection": map[string]interface{}{"probes": string(content), "probefile": ""},
+	})
+}
+
+// builtinServiceProbes are used if no nmap-service-probes file is configured.
+// They identify only a few common services, nmap's file knows thousands
+const builtinServiceProbes = `# Built-in probes of nray in nmap-service-probes format
+Probe TCP NULL q||
+totalwaitms 6000
+
+match ssh m|^SSH-([\d.]+)-OpenSSH_([\w._-]+)[ -]?([^\r\n]*)\r?\n| p/OpenSSH/ v/$2/ i/$3, protocol $1/ cpe:/a:openbsd:openssh:$2/
+match ssh m|^SSH-([\d.]+)-dropbear_([\w.]+)\r?\n| p/Dropbear sshd/ v/$2/ i/protocol $1/ cpe:/a:matt_johnston:dropbear_ssh_server:$2/
+softmatch ssh m|^SSH-([\d.]+)-|
+match ftp m|^220[- ][^\r\n]*\(vsFTPd ([\d.]+)\)| p/vsftpd/ v/$1/ cpe:/a:beasts:vsftpd:$1/
+match ftp m|^220[- ]ProFTPD ([\d.]+\w*) Server| p/ProFTPD/ v/$1/ cpe:/a:proftpd:proftpd:$1/
+softmatch ftp m|^220[- ][^\r\n]*FTP|i
+match smtp m|^220 ([\w.-]+) ESMTP Postfix| p/Postfix smtpd/ h/$1/ cpe:/a:postfix:postfix/a
+match smtp m|^220 ([\w.-]+) ESMTP Exim ([\d.]+)| p/Exim smtpd/ v/$2/ h/$1/ cpe:/a:exim:exim:$2/
+softmatch smtp m|^220[ -][^\r\n]*SMTP|i
+match pop3 m|^\+OK Dovecot| p/Dovecot pop3d/ cpe:/a:dovecot:dovecot/
+softmatch pop3 m|^\+OK|
+match imap m|^\* OK [^\r\n]*Dovecot| p/Dovecot imapd/ cpe:/a:dovecot:dovecot/
+softmatch imap m|^\* OK|
+match mysql m|^.\0\0\0\x0a(5\.5\.5-)?([\d.]+)-MariaDB|s p/MariaDB/ v/$2/ cpe:/a:mariadb:mariadb:$2/
+match mysql m|^.\0\0\0\x0a([\d.]+)[^\0]*\0|s p/MySQL/ v/$1/ cpe:/a:mysql:mysql:$1/
+match redis m|^-NOAUTH Authentication required| p/Redis key-value store/ cpe:/a:redislabs:redis/
+match vnc m|^RFB (\d\d\d)\.(\d\d\d)\n| p/VNC/ i/protocol $1.$2/
+
+Probe TCP GetRequest q|GET / HTTP/1.0\r\n\r\n|
+rarity 1
+ports 80,81,591,3000,5000,8000,8008,8080,8081,8088,8888,9000
+match http m|^HTTP/1\.[01] \d\d\d .*?\r\nServer: nginx/([\d.]+)|s p/nginx/ v/$1/ cpe:/a:igor_sysoev:nginx:$1/
+match http m|^HTTP/1\.[01] \d\d\d .*?\r\nServer: nginx\r\n|s p/nginx/ cpe:/a:igor_sysoev:nginx/
+match http m|^HTTP/1\.[01] \d\d\d .*?\r\nServer: Apache/([\d.]+) \(([^)]+)\)|s p/Apache httpd/ v/$1/ i/($2)/ cpe:/a:apache:http_server:$1/
+match http m|^HTTP/1\.[01] \d\d\d .*?\r\nServer: Apache/([\d.]+)|s p/Apache httpd/ v/$1/ cpe:/a:apache:http_server:$1/
+match http m|^HTTP/1\.[01] \d\d\d .*?\r\nServer: Microsoft-IIS/([\d.]+)|s p/Microsoft IIS httpd/ v/$1/ o/Windows/ cpe:/a:microsoft:internet_information_services:$1/ cpe:/o:microsoft:windows/a
+match http m|^HTTP/1\.[01] \d\d\d .*?\r\nServer: lighttpd/([\d.]+)|s p/lighttpd/ v/$1/ cpe:/a:lighttpd:lighttpd:$1/
+softmatch http m|^HTTP/1\.[01] \d\d\d|
+
+Probe TCP RTSPRequest q|OPTIONS / RTSP/1.0\r\n\r\n|
+rarity 5
+ports 554,8554
+fallback GetRequest
+softmatch rtsp m|^RTSP/1\.0 \d\d\d|
+
+Probe TCP GenericLines q|\r\n\r\n|
+rarity 1
+ports 21,23,25,110,113,143,220,1433,3306,5432
+softmatch ftp m|^500 [^\r\n]*command|i
+
+Probe TCP RedisPing q|PING\r\n|
+rarity 8
+ports 6379
+match redis m|^\+PONG\r\n| p/Redis key-value store/ cpe:/a:redislabs:redis/
+
+Probe UDP DNSStatusRequest q|\0\0\x10\0\0\0\0\0\0\0\0\0|
+rarity 1
+ports 53,5353
+softmatch domain m|^\0\0\x90[\x00-\x05\x80-\x85]\0\0\0\0\0\0\0\0|
+
+Probe UDP NTPRequest q|\xe3\0\x04\xfa\0\x01\0\0\0\x01\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\0\xc5O#Kq\xb1R\xf3|
+rarity 2
+ports 123
+match ntp m|^[\x1c\x24\xe4].{47}$|s p/NTP/ v/v4/
+`
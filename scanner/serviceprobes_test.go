@@ -0,0 +1,222 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const testServiceProbes = `# Comment
+Exclude T:9100-9101,U:53
+
+Probe TCP NULL q||
+totalwaitms 6000
+match ftp m|^220 Example FTP ([\d.]+)\r\n| p/Example ftpd/ v/$1/ cpe:/a:example:ftpd:$1/a
+softmatch ftp m|^220 |
+match backref m|^(a)\1|
+
+Probe TCP Binary q|\x01\0\xff|
+rarity 8
+ports 7000
+match binary m|^\x01\xff(.)(..)$|s p/$P(1)/ v/$SUBST(1,"_",".")/ i/$I(2,">")/
+
+Probe TCP Help q|HELP\r\n|
+rarity 3
+fallback Binary
+match help m|^Help: (\S+)|i p/Helper/ v/$1/ h/$1/ o/Linux/ d/router/
+
+Probe UDP Status q|status|
+ports 5000-5001
+softmatch status m|^ok|
+`
+
+func TestParseServiceProbes(t *testing.T) {
+	probes, err := parseServiceProbes(testServiceProbes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(probes.probes) != 4 || probes.skippedMatches != 1 {
+		t.Fatalf("Expected 4 probes and a skipped match, got %d and %d", len(probes.probes), probes.skippedMatches)
+	}
+	binary := probes.byName["tcp/Binary"]
+	if string(binary.payload) != "\x01\x00\xff" || binary.rarity != 8 || !binary.ports[7000] {
+		t.Errorf("Binary probe parsed wrong: %+v", binary)
+	}
+	if null := probes.byName["tcp/NULL"]; null.rarity != defaultServiceProbeRarity || null.wait.Milliseconds() != 6000 || len(null.matches) != 2 {
+		t.Errorf("NULL probe parsed wrong: %+v", null)
+	}
+	if !probes.excluded["tcp"][9101] || probes.excluded["udp"][9101] || !probes.excluded["udp"][53] {
+		t.Errorf("Exclusions parsed wrong: %v", probes.excluded)
+	}
+	if udp := probes.byName["udp/Status"]; udp == nil || !udp.ports[5001] {
+		t.Errorf("UDP probe parsed wrong: %+v", udp)
+	}
+
+	for _, invalid := range []string{
+		"match ftp m|^220|",
+		"Probe SCTP NULL q||",
+		"Probe TCP NULL q||\nrarity 10",
+		"Probe TCP NULL q||\nmatch ftp m|^220",
+		"Probe TCP NULL q||\nmatch ftp m|^220|x",
+		"Probe TCP NULL q||\nmatch ftp m|^220| z/unknown/",
+		"Probe TCP NULL q||\nfallback Missing",
+		"Probe TCP NULL q||\nProbe TCP NULL q||",
+		"Probe TCP NULL q||\nunknown",
+	} {
+		if _, err := parseServiceProbes(invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestBuiltinServiceProbes(t *testing.T) {
+	probes, err := parseServiceProbes(builtinServiceProbes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if probes.skippedMatches != 0 {
+		t.Errorf("All built-in matches must compile, %d were skipped", probes.skippedMatches)
+	}
+	for response, expected := range map[string]string{
+		"SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13.5\r\n":                   "OpenSSH 9.6p1",
+		"J\x00\x00\x00\x0a8.0.36\x00\x08\x00\x00\x00":                    "MySQL 8.0.36",
+		"HTTP/1.1 200 OK\r\nDate: today\r\nServer: nginx/1.24.0\r\n\r\n": "nginx 1.24.0",
+	} {
+		result := probes.match(probes.byName["tcp/GetRequest"], []byte(response))
+		if result == nil || result.soft || result.product+" "+result.version != expected {
+			t.Errorf("Expected %s for %q, got %+v", expected, response, result)
+		}
+	}
+	if result := probes.match(probes.byName["tcp/NULL"], []byte("SSH-2.0-Custom\r\n")); result == nil || !result.soft || result.service != "ssh" {
+		t.Errorf("Expected a softmatch for ssh, got %+v", result)
+	}
+}
+
+func TestServiceMatchTemplates(t *testing.T) {
+	probes, err := parseServiceProbes(testServiceProbes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := probes.match(probes.byName["tcp/Binary"], []byte("\x01\xff\x07\x01\x02"))
+	if result == nil || result.product != "" || result.version != "\a" || result.info != "258" {
+		t.Errorf("Binary match failed, got %+v", result)
+	}
+	result = probes.match(probes.byName["tcp/Binary"], []byte("\x01\xffa\x00\x10\n"))
+	if result == nil || result.product != "a" || result.info != "16" {
+		t.Errorf("$ must match before a final newline, got %+v", result)
+	}
+	// Fallbacks and the NULL probe are matched as well
+	result = probes.match(probes.byName["tcp/Help"], []byte("220 Example FTP 1.2\r\n"))
+	if result == nil || result.service != "ftp" || result.version != "1.2" || len(result.cpes) != 1 || result.cpes[0] != "cpe:/a:example:ftpd:1.2" || result.probe != "Help" {
+		t.Errorf("Expected the NULL probe's match, got %+v", result)
+	}
+	result = probes.match(probes.byName["tcp/Help"], []byte("HELP: r1\r\n"))
+	if result == nil || result.hostname != "r1" || result.os != "Linux" || result.devicetype != "router" {
+		t.Errorf("Case insensitive match failed, got %+v", result)
+	}
+	if result := probes.match(probes.byName["tcp/Help"], []byte("220 Other\r\n")); result == nil || !result.soft {
+		t.Errorf("Expected a softmatch, got %+v", result)
+	}
+	if !probes.canMatch(probes.byName["tcp/Help"], "binary") || probes.canMatch(probes.byName["tcp/Help"], "ftp") {
+		t.Errorf("Help may only identify help and, by its fallback, binary")
+	}
+}
+
+func TestCompileNmapPattern(t *testing.T) {
+	for _, test := range []struct {
+		pattern string
+		flags   string
+		input   string
+		matches bool
+	}{
+		{`^\xff\xfe`, "", "\xff\xfe", true},
+		{`^[\x80-\xff]+$`, "", "\x80\xc3\xa9", true},
+		{`^[]a]+$`, "", "]a]", true},
+		{`^[^]a]+$`, "", "bc", true},
+		{`^a.b`, "", "a\nb", false},
+		{`^a.b`, "s", "a\nb", true},
+		{`^ABC`, "i", "abc", true},
+		{`^\d++x\Z`, "", "12x\n", true},
+		{`^\e\h`, "", "\x1b\t", true},
+	} {
+		pattern, err := compileNmapPattern(test.pattern, test.flags)
+		if err != nil {
+			t.Errorf("%s: %v", test.pattern, err)
+			continue
+		}
+		if pattern.MatchString(latin1(test.input)) != test.matches {
+			t.Errorf("%s matching %q should be %v", test.pattern, test.input, test.matches)
+		}
+	}
+	if _, err := compileNmapPattern(`(?=a)`, ""); err == nil {
+		t.Errorf("Lookaheads are not supported")
+	}
+}
+
+func TestServiceProbesForPort(t *testing.T) {
+	probes, err := parseServiceProbes(testServiceProbes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := func(selected []*serviceProbe) []string {
+		result := make([]string, 0)
+		for _, probe := range selected {
+			result = append(result, probe.name)
+		}
+		return result
+	}
+	for _, test := range []struct {
+		protocol  string
+		port      uint32
+		intensity int
+		expected  []string
+	}{
+		{"tcp", 21, 7, []string{"NULL", "Help"}},
+		{"tcp", 21, 9, []string{"NULL", "Binary", "Help"}},
+		{"tcp", 21, 0, []string{"NULL"}},
+		{"tcp", 7000, 0, []string{"NULL", "Binary"}},
+		{"tcp", 9100, 9, []string{}},
+		{"udp", 5000, 0, []string{"Status"}},
+		{"udp", 53, 9, []string{}},
+	} {
+		if selected := names(probes.forPort(test.protocol, test.port, test.intensity)); fmt.Sprint(selected) != fmt.Sprint(test.expected) {
+			t.Errorf("Expected %v for %s/%d at intensity %d, got %v", test.expected, test.protocol, test.port, test.intensity, selected)
+		}
+	}
+}
+
+func TestLoadServiceProbeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nmap-service-probes")
+	if err := os.WriteFile(path, []byte(testServiceProbes), 0600); err != nil {
+		t.Fatal(err)
+	}
+	scannerConfig := viper.New()
+	scannerConfig.MergeConfigMap(map[string]interface{}{"servicedetection": map[string]interface{}{
+		"probeFile": path,
+		"intensity": 3,
+	}})
+	if err := LoadServiceProbeFile(scannerConfig); err != nil {
+		t.Fatal(err)
+	}
+	section := scannerConfig.Sub("servicedetection")
+	if section.GetString("probes") != testServiceProbes || section.GetString("probeFile") != "" || section.GetInt("intensity") != 3 {
+		t.Errorf("Probes must be part of the configuration, got %v", section.AllSettings())
+	}
+
+	if err := os.WriteFile(path, []byte("Probe TCP NULL"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	scannerConfig.MergeConfigMap(map[string]interface{}{"servicedetection": map[string]interface{}{"probeFile": path}})
+	if err := LoadServiceProbeFile(scannerConfig); err == nil {
+		t.Errorf("Expected an error for an invalid probe file")
+	}
+
+	inline := viper.New()
+	inline.Set("servicedetection.probes", "Probe TCP NULL")
+	if err := LoadServiceProbeFile(inline); err == nil {
+		t.Errorf("Expected an error for invalid inline probes")
+	}
+}
@@ -0,0 +1,178 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/nray-scanner/nray/utils"
+	"github.com/spf13/viper"
+)
+
+// How many ports of the source port range are tried if they are in use
+const maxSourcePortAttempts = 8
+
+// sourceError is returned if a connection could not be opened from the source,
+// e.g. because binding to its address, interface or port failed. The target
+// was not contacted then
+type sourceError struct {
+	source string
+	err    error
+}
+
+func (e *sourceError) Error() string {
+	return fmt.Sprintf("sending from source %s failed: %v", e.source, e.err)
+}
+
+func (e *sourceError) Unwrap() error {
+	return e.err
+}
+
+// ValidateSourceBinding checks a source IP, interface and port range like
+// they are given on the command line. Empty values are not checked
+func ValidateSourceBinding(ip string, iface string, ports string) error {
+	config := viper.New()
+	config.Set("ip", ip)
+	config.Set("interface", iface)
+	config.Set("ports", ports)
+	_, err := newSourceBinding(config)
+	return err
+}
+
+// sourceBinding binds connections of the scanners to a source address, interface
+// or port range. A nil binding dials like net.DialTimeout
+type sourceBinding struct {
+	ip        net.IP
+	iface     string
+	firstPort int
+	lastPort  int
+	nextPort  uint32
+}
+
+// newSourceBinding parses the source configuration. It returns nil if nothing is configured
+func newSourceBinding(config *viper.Viper) (*sourceBinding, error) {
+	config = utils.ApplyDefaultScannerSourceConfig(config)
+	source := &sourceBinding{iface: config.GetString("interface")}
+	if ip := config.GetString("ip"); ip != "" {
+		if source.ip = net.ParseIP(ip); source.ip == nil {
+			return nil, fmt.Errorf("invalid source IP %s", ip)
+		}
+	}
+	if ports := config.GetString("ports"); ports != "" {
+		bounds := strings.SplitN(ports, "-", 2)
+		first, err := strconv.ParseUint(strings.TrimSpace(bounds[0]), 10, 16)
+		if err != nil || first == 0 {
+			return nil, fmt.Errorf("invalid source port %s", bounds[0])
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.ParseUint(strings.TrimSpace(bounds[1]), 10, 16); err != nil || last < first {
+				return nil, fmt.Errorf("invalid source port range %s", ports)
+			}
+		}
+		source.firstPort, source.lastPort = int(first), int(last)
+	}
+	if source.iface != "" {
+		if _, err := net.InterfaceByName(source.iface); err != nil {
+			return nil, fmt.Errorf("invalid source interface %s: %v", source.iface, err)
+		}
+		if err := source.resolveInterface(); err != nil {
+			return nil, err
+		}
+	}
+	if source.ip == nil && source.iface == "" && source.firstPort == 0 {
+		return nil, nil
+	}
+	return source, nil
+}
+
+// port returns the next port of the source port range, 0 lets the kernel choose
+func (source *sourceBinding) port() int {
+	if source.firstPort == 0 {
+		return 0
+	}
+	next := atomic.AddUint32(&source.nextPort, 1) - 1
+	return source.firstPort + int(next%uint32(source.lastPort-source.firstPort+1))
+}
+
+// dialer returns a dialer bound to the source for the given network
+func (source *sourceBinding) dialer(network string, timeout time.Duration) *net.Dialer {
+	dialer := &net.Dialer{Timeout: timeout, Control: source.control}
+	if source.ip == nil && source.firstPort == 0 {
+		return dialer
+	}
+	if strings.HasPrefix(network, "udp") {
+		dialer.LocalAddr = &net.UDPAddr{IP: source.ip, Port: source.port()}
+	} else {
+		dialer.LocalAddr = &net.TCPAddr{IP: source.ip, Port: source.port()}
+	}
+	return dialer
+}
+
+// dial connects from the source to address. If a port of the source port range
+// is in use, the next ones are tried
+func (source *sourceBinding) dial(network string, address string, timeout time.Duration) (net.Conn, error) {
+	if source == nil {
+		return net.DialTimeout(network, address, timeout)
+	}
+	attempts := 1
+	if source.firstPort != 0 {
+		attempts = min(maxSourcePortAttempts, source.lastPort-source.firstPort+1)
+	}
+	var conn net.Conn
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		conn, err = source.dialer(network, timeout).Dial(network, address)
+		if !errors.Is(err, syscall.EADDRINUSE) && !errors.Is(err, syscall.EADDRNOTAVAIL) {
+			break
+		}
+	}
+	if isBindError(err) {
+		return nil, &sourceError{source: source.String(), err: err}
+	}
+	return conn, err
+}
+
+// isBindError checks if a connection failed while binding the socket to the
+// source, e.g. because the source port is in use, binding to the interface is
+// not permitted or the source IP is of another address family than the target
+func isBindError(err error) bool {
+	var syscallErr *os.SyscallError
+	if errors.As(err, &syscallErr) && (syscallErr.Syscall == "bind" || syscallErr.Syscall == "setsockopt") {
+		return true
+	}
+	var addrErr *net.AddrError
+	return errors.As(err, &addrErr) || errors.Is(err, syscall.EADDRNOTAVAIL) || errors.Is(err, syscall.EAFNOSUPPORT)
+}
+
+// ipv4 returns the source IP if it is an IPv4 address, otherwise nil
+func (source *sourceBinding) ipv4() net.IP {
+	if source == nil {
+		return nil
+	}
+	return source.ip.To4()
+}
+
+// String describes the source for log messages
+func (source *sourceBinding) String() string {
+	if source == nil {
+		return "default"
+	}
+	parts := make([]string, 0, 3)
+	if source.ip != nil {
+		parts = append(parts, "ip "+source.ip.String())
+	}
+	if source.iface != "" {
+		parts = append(parts, "interface "+source.iface)
+	}
+	if source.firstPort != 0 {
+		parts = append(parts, fmt.Sprintf("ports %d-%d", source.firstPort, source.lastPort))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,36 @@
+//go:build linux
+
+package scanner
+
+import (
+	"os"
+	"syscall"
+)
+
+// control binds sockets to the source interface and allows reusing ports of
+// the source port range that are still in TIME_WAIT
+func (source *sourceBinding) control(network string, address string, conn syscall.RawConn) error {
+	var sockErr error
+	err := conn.Control(func(fd uintptr) {
+		if source.iface != "" {
+			if sockErr = syscall.BindToDevice(int(fd), source.iface); sockErr != nil {
+				return
+			}
+		}
+		if source.firstPort != 0 {
+			sockErr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if sockErr != nil {
+		return os.NewSyscallError("setsockopt", sockErr)
+	}
+	return nil
+}
+
+// resolveInterface does nothing on Linux because sockets are bound to the interface itself
+func (source *sourceBinding) resolveInterface() error {
+	return nil
+}
@@ -0,0 +1,37 @@
+//go:build !linux
+
+package scanner
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// control does nothing, only Linux can bind sockets to an interface
+func (source *sourceBinding) control(network string, address string, conn syscall.RawConn) error {
+	return nil
+}
+
+// resolveInterface uses the first address of the source interface as source IP
+// unless one is configured, because sockets can't be bound to the interface itself
+func (source *sourceBinding) resolveInterface() error {
+	if source.ip != nil {
+		return nil
+	}
+	iface, err := net.InterfaceByName(source.iface)
+	if err != nil {
+		return err
+	}
+	addresses, err := iface.Addrs()
+	if err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		if network, ok := address.(*net.IPNet); ok && network.IP.To4() != nil {
+			source.ip = network.IP
+			return nil
+		}
+	}
+	return fmt.Errorf("interface %s has no IPv4 address", source.iface)
+}
@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestNewSourceBinding(t *testing.T) {
+	if source, err := newSourceBinding(nil); source != nil || err != nil {
+		t.Errorf("Without configuration, no binding is expected, got %v %v", source, err)
+	}
+	for _, invalid := range []map[string]string{
+		{"ip": "300.0.0.1"},
+		{"ports": "0"},
+		{"ports": "41000-40000"},
+		{"interface": "does-not-exist0"},
+	} {
+		config := viper.New()
+		for key, value := range invalid {
+			config.Set(key, value)
+		}
+		if _, err := newSourceBinding(config); err == nil {
+			t.Errorf("Expected an error for %v", invalid)
+		}
+	}
+
+	config := viper.New()
+	config.Set("ports", "40000-40002")
+	source, err := newSourceBinding(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []int{40000, 40001, 40002, 40000} {
+		if port := source.port(); port != expected {
+			t.Errorf("Expected port %d, got %d", expected, port)
+		}
+	}
+}
+
+func TestSourceBindingDial(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	port := uint32(listener.Addr().(*net.TCPAddr).Port)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	config := viper.New()
+	config.Set("ip", "127.0.0.1")
+	config.Set("ports", "45000-45009")
+	source, err := newSourceBinding(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, _, err := tcpConnect("127.0.0.1", port, time.Second, source.dial)
+	if err != nil || result == nil {
+		t.Fatalf("Expected an open port, got %v %v", result, err)
+	}
+	if result.Source != "127.0.0.1:45000" {
+		t.Errorf("Expected source 127.0.0.1:45000, got %s", result.Source)
+	}
+	// The next port of the range is used for the next connection
+	if result, _, _ = tcpConnect("127.0.0.1", port, time.Second, source.dial); result == nil || result.Source != "127.0.0.1:45001" {
+		t.Errorf("Expected source 127.0.0.1:45001, got %v", result)
+	}
+}
+
+func TestSourceBindingErrors(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	// The only source port is in use, so the target is never contacted
+	config := viper.New()
+	config.Set("ip", "127.0.0.1")
+	config.Set("ports", fmt.Sprint(port))
+	source, err := newSourceBinding(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var failed *sourceError
+	if result, _, err := tcpConnect("127.0.0.1", uint32(port), time.Second, source.dial); result != nil || !errors.As(err, &failed) {
+		t.Errorf("Expected a source error, got %v %v", result, err)
+	}
+
+	// An IPv4 source can't reach IPv6 targets
+	config = viper.New()
+	config.Set("ip", "127.0.0.1")
+	if source, err = newSourceBinding(config); err != nil {
+		t.Fatal(err)
+	}
+	if result, _, err := tcpConnect("::1", uint32(port), time.Second, source.dial); result != nil || !errors.As(err, &failed) {
+		t.Errorf("Expected a source error, got %v %v", result, err)
+	}
+
+	if err := ValidateSourceBinding("127.0.0.1", "", "40000-40999"); err != nil {
+		t.Errorf("Valid source rejected: %v", err)
+	}
+	if err := ValidateSourceBinding("", "", "40999-40000"); err == nil {
+		t.Errorf("Invalid source port range accepted")
+	}
+}
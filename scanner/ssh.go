@@ -0,0 +1,362 @@
+package scanner
+
+import (
+	"bufio"
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	nraySchema "github.com/nray-scanner/nray/schemas"
+	"github.com/nray-scanner/nray/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/ssh"
+)
+
+func init() {
+	protocolScanners["ssh"] = func() ProtocolScanner { return &SSHScanner{} }
+}
+
+const (
+	// SSH_MSG_KEXINIT, RFC 4253 section 7.1
+	sshMsgKexInit = 20
+	// Servers may send this many lines before their identification string
+	sshMaxPreBannerLines = 20
+	// Largest packet a KEXINIT is read from, RFC 4253 section 6.1
+	sshMaxPacketLength = 35000
+)
+
+// sshHostKeyFamilies lists the host key algorithms a key of each type is
+// requested with, in order of preference
+var sshHostKeyFamilies = [][]string{
+	{ssh.KeyAlgoED25519},
+	{ssh.KeyAlgoECDSA256},
+	{ssh.KeyAlgoECDSA384},
+	{ssh.KeyAlgoECDSA521},
+	{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA},
+	{ssh.InsecureKeyAlgoDSA},
+}
+
+// errHostKeyCaptured aborts the handshake once the server proved it owns its host key
+var errHostKeyCaptured = errors.New("host key captured")
+
+// SSHScanner reads what SSH servers reveal before authentication: the
+// identification string, the algorithms of the KEXINIT message and the host keys
+type SSHScanner struct {
+	nodeID       string
+	nodeName     string
+	ports        []uint32
+	timeout      time.Duration
+	clientBanner string
+	hostKeys     bool
+	controller   *ScanController
+}
+
+// sshKexInit holds the name-lists of a KEXINIT message
+type sshKexInit struct {
+	kex                     []string
+	hostKeys                []string
+	ciphersClientServer     []string
+	ciphersServerClient     []string
+	macsClientServer        []string
+	macsServerClient        []string
+	compressionClientServer []string
+	compressionServerClient []string
+}
+
+// Configure reads the configuration
+func (sshScanner *SSHScanner) Configure(config *viper.Viper, nodeID string, nodeName string) {
+	config = utils.ApplyDefaultScannerSSHConfig(config)
+	sshScanner.nodeID = nodeID
+	sshScanner.nodeName = nodeName
+	for _, port := range config.GetIntSlice("ports") {
+		sshScanner.ports = append(sshScanner.ports, uint32(port))
+	}
+	sshScanner.timeout = config.GetDuration("timeout")
+	sshScanner.clientBanner = config.GetString("clientBanner")
+	sshScanner.hostKeys = config.GetBool("hostKeys")
+}
+
+// Register subscribes to the configured TCP ports
+func (sshScanner *SSHScanner) Register(controller *ScanController) {
+	sshScanner.controller = controller
+	for _, port := range sshScanner.ports {
+		controller.Subscribe(fmt.Sprintf("tcp/%d", port), sshScanner.scanFunc)
+	}
+}
+
+// scanFunc returns a function scanning an SSH server and reporting it to results
+func (sshScanner *SSHScanner) scanFunc(proto string, host string, port uint, results chan<- *nraySchema.Event) func() {
+	return func() {
+		result, err := sshScanner.scan(net.JoinHostPort(host, fmt.Sprint(port)))
+		if err != nil {
+			log.WithFields(log.Fields{
+				"module": "scanner.ssh",
+				"src":    "scanFunc",
+			}).Debugf("SSH scan of %s:%d failed: %v", host, port, err)
+			return
+		}
+		timestamp, _ := ptypes.TimestampProto(currentTime())
+		results <- &nraySchema.Event{
+			NodeID:   sshScanner.nodeID,
+			NodeName: sshScanner.nodeName,
+			EventData: &nraySchema.Event_Result{
+				Result: &nraySchema.ScanResult{
+					Target:   host,
+					Port:     uint32(port),
+					Hostname: sshScanner.controller.Hostname(host),
+					Result:   &nraySchema.ScanResult_Ssh{Ssh: result},
+				},
+			},
+			Scannername: "ssh",
+			Timestamp:   timestamp,
+		}
+	}
+}
+
+// scan exchanges identification strings, reads the server's KEXINIT and, if enabled,
+// performs a key exchange for each type of host key the server offers. Each of them
+// is a connection of its own and counts against the rate limits
+func (sshScanner *SSHScanner) scan(address string) (*nraySchema.SSHScanResult, error) {
+	conn, err := sshScanner.controller.Dial("tcp", address, sshScanner.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(sshScanner.timeout))
+	if _, err := conn.Write([]byte(sshScanner.clientBanner + "\r\n")); err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(conn)
+	banner, err := readSSHBanner(reader)
+	if err != nil {
+		return nil, err
+	}
+	result := &nraySchema.SSHScanResult{Banner: banner}
+	identification := strings.SplitN(banner, " ", 2)
+	if len(identification) == 2 {
+		result.Comments = identification[1]
+	}
+	if parts := strings.SplitN(identification[0], "-", 3); len(parts) == 3 {
+		result.Protoversion = parts[1]
+		result.Softwareversion = parts[2]
+	}
+
+	kexInit, err := readSSHKexInit(reader)
+	if err != nil {
+		// The banner alone is still worth reporting, e.g. for servers rejecting the client
+		log.WithFields(log.Fields{
+			"module": "scanner.ssh",
+			"src":    "scan",
+		}).Debugf("Reading KEXINIT of %s failed: %v", address, err)
+		return result, nil
+	}
+	result.KexAlgorithms = kexInit.kex
+	result.HostKeyAlgorithms = kexInit.hostKeys
+	result.CiphersClientServer = kexInit.ciphersClientServer
+	result.CiphersServerClient = kexInit.ciphersServerClient
+	result.MacsClientServer = kexInit.macsClientServer
+	result.MacsServerClient = kexInit.macsServerClient
+	result.CompressionClientServer = kexInit.compressionClientServer
+	result.CompressionServerClient = kexInit.compressionServerClient
+	result.WeakAlgorithms = weakSSHAlgorithms(kexInit)
+	conn.Close()
+
+	if sshScanner.hostKeys {
+		for _, family := range sshHostKeyFamilies {
+			algorithm := firstOffered(family, kexInit.hostKeys)
+			if algorithm == "" {
+				continue
+			}
+			host, _, _ := net.SplitHostPort(address)
+			sshScanner.controller.waitForProbe(host)
+			hostKey, err := sshScanner.fetchHostKey(address, algorithm)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"module": "scanner.ssh",
+					"src":    "scan",
+				}).Debugf("Fetching the %s host key of %s failed: %v", algorithm, address, err)
+				continue
+			}
+			result.HostKeys = append(result.HostKeys, hostKey)
+		}
+	}
+	return result, nil
+}
+
+// readSSHBanner reads the server's identification string. Lines before it are skipped
+func readSSHBanner(reader *bufio.Reader) (string, error) {
+	for i := 0; i < sshMaxPreBannerLines; i++ {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if strings.HasPrefix(line, "SSH-") {
+			return printableUTF8([]byte(line), false), nil
+		}
+	}
+	return "", fmt.Errorf("no SSH identification string")
+}
+
+// readSSHKexInit reads the first binary packet, which is the server's KEXINIT.
+// It is sent unencrypted and without MAC
+func readSSHKexInit(reader *bufio.Reader) (*sshKexInit, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header)
+	padding := uint32(header[4])
+	if length > sshMaxPacketLength || length < padding+2 {
+		return nil, fmt.Errorf("invalid packet length %d", length)
+	}
+	packet := make([]byte, length-1)
+	if _, err := io.ReadFull(reader, packet); err != nil {
+		return nil, err
+	}
+	payload := packet[:len(packet)-int(padding)]
+	if payload[0] != sshMsgKexInit {
+		return nil, fmt.Errorf("expected KEXINIT, got message %d", payload[0])
+	}
+	if len(payload) < 17 {
+		return nil, fmt.Errorf("short KEXINIT")
+	}
+	// Message type and cookie
+	payload = payload[17:]
+	nameLists := make([][]string, 10)
+	for i := range nameLists {
+		if len(payload) < 4 {
+			return nil, fmt.Errorf("short KEXINIT")
+		}
+		size := binary.BigEndian.Uint32(payload)
+		if uint32(len(payload)-4) < size {
+			return nil, fmt.Errorf("short KEXINIT")
+		}
+		if size > 0 {
+			nameLists[i] = strings.Split(string(payload[4:4+size]), ",")
+		}
+		payload = payload[4+size:]
+	}
+	return &sshKexInit{
+		kex:                     nameLists[0],
+		hostKeys:                nameLists[1],
+		ciphersClientServer:     nameLists[2],
+		ciphersServerClient:     nameLists[3],
+		macsClientServer:        nameLists[4],
+		macsServerClient:        nameLists[5],
+		compressionClientServer: nameLists[6],
+		compressionServerClient: nameLists[7],
+	}, nil
+}
+
+// fetchHostKey performs a key exchange asking for a host key of the given
+// algorithm and hangs up before authenticating
+func (sshScanner *SSHScanner) fetchHostKey(address string, algorithm string) (*nraySchema.SSHHostKey, error) {
+	conn, err := sshScanner.controller.Dial("tcp", address, sshScanner.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(sshScanner.timeout))
+	supported, insecure := ssh.SupportedAlgorithms(), ssh.InsecureAlgorithms()
+	var hostKey ssh.PublicKey
+	config := &ssh.ClientConfig{
+		Config: ssh.Config{
+			KeyExchanges: append(supported.KeyExchanges, insecure.KeyExchanges...),
+			Ciphers:      append(supported.Ciphers, insecure.Ciphers...),
+			MACs:         append(supported.MACs, insecure.MACs...),
+		},
+		User:              "nray",
+		HostKeyAlgorithms: []string{algorithm},
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			hostKey = key
+			return errHostKeyCaptured
+		},
+		ClientVersion: sshScanner.clientBanner,
+		Timeout:       sshScanner.timeout,
+	}
+	if _, _, _, err := ssh.NewClientConn(conn, address, config); hostKey == nil {
+		return nil, err
+	}
+	return &nraySchema.SSHHostKey{
+		Type:      hostKey.Type(),
+		Bits:      uint32(hostKeyBits(hostKey)),
+		Sha256:    ssh.FingerprintSHA256(hostKey),
+		Md5:       ssh.FingerprintLegacyMD5(hostKey),
+		PublicKey: base64.StdEncoding.EncodeToString(hostKey.Marshal()),
+	}, nil
+}
+
+// hostKeyBits returns the size of a host key
+func hostKeyBits(key ssh.PublicKey) int {
+	cryptoKey, ok := key.(ssh.CryptoPublicKey)
+	if !ok {
+		return 0
+	}
+	switch publicKey := cryptoKey.CryptoPublicKey().(type) {
+	case *rsa.PublicKey:
+		return publicKey.N.BitLen()
+	case *ecdsa.PublicKey:
+		return publicKey.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return 256
+	case *dsa.PublicKey:
+		return publicKey.P.BitLen()
+	}
+	return 0
+}
+
+// firstOffered returns the first of the algorithms the server offers
+func firstOffered(algorithms []string, offered []string) string {
+	for _, algorithm := range algorithms {
+		for _, name := range offered {
+			if name == algorithm {
+				return algorithm
+			}
+		}
+	}
+	return ""
+}
+
+// weakSSHAlgorithms returns the offered algorithms that rely on SHA-1 for the key exchange
+// or host key signatures, DSA, CBC mode, RC4, MD5, truncated MACs or no protection at all
+func weakSSHAlgorithms(kexInit *sshKexInit) []string {
+	weak := make([]string, 0)
+	seen := make(map[string]bool)
+	check := func(names []string, isWeak func(string) bool) {
+		for _, name := range names {
+			if isWeak(name) && !seen[name] {
+				seen[name] = true
+				weak = append(weak, name)
+			}
+		}
+	}
+	check(kexInit.kex, func(name string) bool {
+		return strings.HasSuffix(name, "-sha1") || strings.Contains(name, "-sha1-") || strings.Contains(name, "group1-") || name == "rsa1024-sha1"
+	})
+	check(kexInit.hostKeys, func(name string) bool {
+		return name == ssh.KeyAlgoRSA || name == ssh.CertAlgoRSAv01 || strings.HasPrefix(name, "ssh-dss")
+	})
+	cipher := func(name string) bool {
+		return strings.Contains(name, "-cbc") || strings.HasPrefix(name, "arcfour") || name == "none"
+	}
+	check(kexInit.ciphersClientServer, cipher)
+	check(kexInit.ciphersServerClient, cipher)
+	mac := func(name string) bool {
+		return strings.Contains(name, "md5") || strings.Contains(name, "-96") || strings.HasPrefix(name, "umac-64") || name == "none"
+	}
+	check(kexInit.macsClientServer, mac)
+	check(kexInit.macsServerClient, mac)
+	return weak
+}
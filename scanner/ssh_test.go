@@ -0,0 +1,154 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	nraySchema "github.com/nray-scanner/nray/schemas"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/ssh"
+)
+
+// startSSHServer runs an SSH server with an Ed25519 and an RSA host key that
+// also offers CBC ciphers and SHA-1 key exchange. Nobody can authenticate
+func startSSHServer(t *testing.T) (uint32, []ssh.PublicKey) {
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		Config: ssh.Config{
+			KeyExchanges: []string{ssh.KeyExchangeCurve25519, ssh.InsecureKeyExchangeDH14SHA1},
+			Ciphers:      []string{ssh.CipherAES128CTR, ssh.InsecureCipherAES128CBC},
+		},
+		PasswordCallback: func(ssh.ConnMetadata, []byte) (*ssh.Permissions, error) {
+			return nil, ssh.ErrNoAuth
+		},
+		ServerVersion: "SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13.5",
+	}
+	publicKeys := make([]ssh.PublicKey, 0)
+	for _, key := range []interface{}{ed25519Key, rsaKey} {
+		signer, err := ssh.NewSignerFromKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config.AddHostKey(signer)
+		publicKeys = append(publicKeys, signer.PublicKey())
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				ssh.NewServerConn(conn, config)
+			}(conn)
+		}
+	}()
+	return uint32(listener.Addr().(*net.TCPAddr).Port), publicKeys
+}
+
+func TestSSHScanner(t *testing.T) {
+	port, publicKeys := startSSHServer(t)
+	config := viper.New()
+	config.Set("workers", 10)
+	config.Set("tcp.timeout", "500ms")
+	config.Set("ssh", map[string]interface{}{"enabled": true, "ports": []int{int(port)}, "timeout": "2s"})
+	controller, err := CreateScanController("test", "test", 0, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := controller.ScanBatch(&nraySchema.MoreWorkReply{Batchid: 1, Targets: &nraySchema.ScanTargets{
+		Rhosts:   []string{"127.0.0.1"},
+		Tcpports: []uint32{port},
+	}})
+
+	var result *nraySchema.SSHScanResult
+	for _, event := range results {
+		if ssh := event.GetResult().GetSsh(); ssh != nil {
+			result = ssh
+		}
+	}
+	if result == nil {
+		t.Fatalf("Expected an SSH result, got %v", results)
+	}
+	if result.GetBanner() != "SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13.5" || result.GetProtoversion() != "2.0" ||
+		result.GetSoftwareversion() != "OpenSSH_9.6p1" || result.GetComments() != "Ubuntu-3ubuntu13.5" {
+		t.Errorf("Identification string parsed wrong: %v", result)
+	}
+	if strings.Join(result.GetCiphersClientServer(), ",") != "aes128-ctr,aes128-cbc" || len(result.GetKexAlgorithms()) < 2 || len(result.GetMacsServerClient()) == 0 {
+		t.Errorf("KEXINIT parsed wrong: %v", result)
+	}
+	if weak := strings.Join(result.GetWeakAlgorithms(), ","); !strings.Contains(weak, "diffie-hellman-group14-sha1") || !strings.Contains(weak, "aes128-cbc") || strings.Contains(weak, "aes128-ctr") {
+		t.Errorf("Wrong weak algorithms %s", weak)
+	}
+	if len(result.GetHostKeys()) != 2 {
+		t.Fatalf("Expected both host keys, got %v", result.GetHostKeys())
+	}
+	for i, hostKey := range result.GetHostKeys() {
+		if hostKey.GetType() != publicKeys[i].Type() || hostKey.GetSha256() != ssh.FingerprintSHA256(publicKeys[i]) || hostKey.GetMd5() != ssh.FingerprintLegacyMD5(publicKeys[i]) {
+			t.Errorf("Wrong host key %v", hostKey)
+		}
+	}
+	if result.GetHostKeys()[0].GetBits() != 256 || result.GetHostKeys()[1].GetBits() != 2048 {
+		t.Errorf("Wrong key sizes %v", result.GetHostKeys())
+	}
+}
+
+func TestReadSSHKexInit(t *testing.T) {
+	if banner, err := readSSHBanner(bufio.NewReader(strings.NewReader("Welcome\r\nSSH-2.0-Test\r\n"))); err != nil || banner != "SSH-2.0-Test" {
+		t.Errorf("Lines before the identification string must be skipped, got %s %v", banner, err)
+	}
+	for _, invalid := range [][]byte{
+		{0, 0, 0x90, 0, 4},        // too long
+		{0, 0, 0, 4, 4},           // padding longer than the packet
+		{0, 0, 0, 4, 1, 21, 0, 0}, // not a KEXINIT
+		{0, 0, 0, 4, 1, 20, 1, 0}, // short
+		append([]byte{0, 0, 0, 23, 1, 20}, bytes.Repeat([]byte{1}, 21)...), // name-list longer than the packet
+	} {
+		if _, err := readSSHKexInit(bufio.NewReader(bytes.NewReader(invalid))); err == nil {
+			t.Errorf("Expected an error for %v", invalid)
+		}
+	}
+}
+
+func TestSSHScannerRatelimitsHostKeys(t *testing.T) {
+	port, _ := startSSHServer(t)
+	controller, err := CreateScanController("test", "test", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	controller.Refresh()
+	controller.ratelimiter.SetLimit(5)
+	controller.ratelimiter.Wait(context.TODO())
+	sshScanner := &SSHScanner{timeout: 2 * time.Second, clientBanner: "SSH-2.0-nray", hostKeys: true, controller: controller}
+	start := time.Now()
+	result, err := sshScanner.scan(net.JoinHostPort("127.0.0.1", fmt.Sprint(port)))
+	if err != nil || len(result.GetHostKeys()) != 2 {
+		t.Fatalf("Expected both host keys, got %v %v", result, err)
+	}
+	// The first connection is covered by the worker, each handshake takes a token
+	if elapsed := time.Since(start); elapsed < 350*time.Millisecond {
+		t.Errorf("Expected the handshakes to wait for the rate limit, took %s", elapsed)
+	}
+}
@@ -0,0 +1,324 @@
+//go:build linux
+
+package scanner
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/maphash"
+	"math/rand"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	nraySchema "github.com/nray-scanner/nray/schemas"
+	"github.com/nray-scanner/nray/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const (
+	tcpFlagSYN = 0x02
+	tcpFlagRST = 0x04
+	tcpFlagACK = 0x10
+)
+
+// synReceivePollInterval is how often the receive goroutine checks if the scanner was closed
+const synReceivePollInterval = 200 * time.Millisecond
+
+// SYNScanner sends TCP SYN packets over a raw socket and never completes a handshake.
+// Replies are read by a separate goroutine and validated statelessly: the sequence
+// number of each probe is a keyed hash of the target, so a SYN-ACK is only accepted
+// if it acknowledges exactly that number. Targets that are no IPv4 addresses are
+// scanned by the connect scanner
+type SYNScanner struct {
+	timeout    time.Duration
+	sourcePort uint16
+	seed       maphash.Seed
+	fd         int
+	connect    *TCPScanner
+	// State of the batch that is currently scanned, nil between batches
+	batch *synBatch
+	lock  sync.Mutex
+	// Closed to stop the receive goroutine, which closes the socket once it stopped
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// synBatch routes the replies to the results of the current batch
+type synBatch struct {
+	results   chan<- *PortscanResult
+	hostnames map[string]string
+	sourceIPs map[string]net.IP
+	reported  map[string]bool
+	// Results that are being sent, the batch ends after them
+	sending sync.WaitGroup
+}
+
+// newSYNScanner opens the raw socket, configures the scanner and starts the
+// receive goroutine. This fails without CAP_NET_RAW
+func newSYNScanner(connect *TCPScanner, config *viper.Viper) (TCPPortScanner, error) {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_RAW, syscall.IPPROTO_TCP)
+	if err != nil {
+		if err == syscall.EPERM {
+			return nil, fmt.Errorf("opening a raw socket requires CAP_NET_RAW: %v", err)
+		}
+		return nil, err
+	}
+	if source := connect.source; source != nil && source.iface != "" {
+		if err := syscall.BindToDevice(fd, source.iface); err != nil {
+			syscall.Close(fd)
+			return nil, fmt.Errorf("binding the raw socket to %s failed: %v", source.iface, err)
+		}
+	}
+	pollInterval := syscall.NsecToTimeval(synReceivePollInterval.Nanoseconds())
+	if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &pollInterval); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	synscan := &SYNScanner{
+		fd:      fd,
+		seed:    maphash.MakeSeed(),
+		connect: connect,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	synscan.Configure(config)
+	go synscan.receive()
+	return synscan, nil
+}
+
+// Configure sets the time to wait for replies after the last probe and the source port.
+// It is called by newSYNScanner, the receive goroutine relies on the source port not changing
+func (synscan *SYNScanner) Configure(config *viper.Viper) {
+	config = utils.ApplyDefaultScannerTCPConfig(config)
+	synscan.timeout = config.GetDuration("timeout")
+	synscan.sourcePort = uint16(config.GetInt("synSourcePort"))
+	if synscan.sourcePort == 0 {
+		// Not cryptographically relevant, so seeding with time should be OK
+		synscan.sourcePort = uint16(40000 + rand.New(rand.NewSource(time.Now().UnixNano())).Intn(20000))
+	}
+}
+
+// PrepareScanFuncs returns tasks that send one SYN each. The channel is closed
+// once all probes are sent and the timeout for replies to the last ones expired
+func (synscan *SYNScanner) PrepareScanFuncs(targetMsg *nraySchema.MoreWorkReply, results chan<- *PortscanResult) <-chan *ScanTask {
+	scanFuncs := make(chan *ScanTask, 100)
+	go func() {
+		hostnames := make(map[string]string)
+		for address, hostname := range targetMsg.Targets.GetHostnames() {
+			hostnames[address] = hostname
+		}
+		for _, service := range targetMsg.Targets.GetServices() {
+			if service.GetHostname() != "" {
+				hostnames[service.GetRhost()] = service.GetHostname()
+			}
+		}
+		synscan.lock.Lock()
+		synscan.batch = &synBatch{
+			results:   results,
+			hostnames: hostnames,
+			sourceIPs: make(map[string]net.IP),
+			reported:  make(map[string]bool),
+		}
+		synscan.lock.Unlock()
+
+		var sent sync.WaitGroup
+		queue := func(target string, port uint32) {
+			sent.Add(1)
+			scanFuncs <- &ScanTask{Host: target, Run: func() {
+				defer sent.Done()
+				if ip := net.ParseIP(target).To4(); ip != nil {
+					utils.CheckError(synscan.sendSYN(ip, uint16(port)), false)
+					return
+				}
+				result, err := synscan.connect.scan(target, port)
+				utils.CheckError(err, false)
+				results <- withHostname(result, hostnames[target])
+			}}
+		}
+		for _, target := range targetMsg.Targets.GetRhosts() {
+			for _, targetTCPPort := range targetMsg.Targets.GetTcpports() {
+				queue(target, targetTCPPort)
+			}
+		}
+		for _, service := range targetMsg.Targets.GetServices() {
+			queue(service.GetRhost(), service.GetPort())
+		}
+
+		// Replies to the last probes may still be on their way
+		sent.Wait()
+		time.Sleep(synscan.timeout)
+		synscan.lock.Lock()
+		batch := synscan.batch
+		synscan.batch = nil
+		synscan.lock.Unlock()
+		batch.sending.Wait()
+		close(scanFuncs)
+	}()
+	return scanFuncs
+}
+
+// cookie is the sequence number of the probe sent to ip:port
+func (synscan *SYNScanner) cookie(ip net.IP, port uint16) uint32 {
+	var hash maphash.Hash
+	hash.SetSeed(synscan.seed)
+	hash.Write(ip.To4())
+	binary.Write(&hash, binary.BigEndian, port)
+	binary.Write(&hash, binary.BigEndian, synscan.sourcePort)
+	return uint32(hash.Sum64())
+}
+
+// sourceIP returns the local address packets to ip are sent from. Unless a source
+// IP is configured, a UDP socket is connected. This only asks the kernel for the
+// route, no packet is sent
+func (synscan *SYNScanner) sourceIP(ip net.IP) (net.IP, error) {
+	synscan.lock.Lock()
+	if synscan.batch != nil {
+		if source, exists := synscan.batch.sourceIPs[ip.String()]; exists {
+			synscan.lock.Unlock()
+			return source, nil
+		}
+	}
+	synscan.lock.Unlock()
+	source := synscan.connect.source.ipv4()
+	if source == nil {
+		conn, err := synscan.connect.source.dial("udp4", net.JoinHostPort(ip.String(), "9"), synscan.timeout)
+		if err != nil {
+			return nil, err
+		}
+		source = conn.LocalAddr().(*net.UDPAddr).IP.To4()
+		conn.Close()
+	}
+	synscan.lock.Lock()
+	if synscan.batch != nil {
+		synscan.batch.sourceIPs[ip.String()] = source
+	}
+	synscan.lock.Unlock()
+	return source, nil
+}
+
+// sendSYN sends a single SYN to ip:port
+func (synscan *SYNScanner) sendSYN(ip net.IP, port uint16) error {
+	source, err := synscan.sourceIP(ip)
+	if err != nil {
+		return err
+	}
+	packet := buildSYN(source, ip, synscan.sourcePort, port, synscan.cookie(ip, port))
+	destination := &syscall.SockaddrInet4{}
+	copy(destination.Addr[:], ip)
+	return syscall.Sendto(synscan.fd, packet, 0, destination)
+}
+
+// buildSYN builds the TCP header of a SYN with an MSS option. The IP header is added by the kernel
+func buildSYN(source net.IP, destination net.IP, sourcePort uint16, destinationPort uint16, seq uint32) []byte {
+	header := make([]byte, 24)
+	binary.BigEndian.PutUint16(header[0:2], sourcePort)
+	binary.BigEndian.PutUint16(header[2:4], destinationPort)
+	binary.BigEndian.PutUint32(header[4:8], seq)
+	header[12] = 6 << 4 // data offset in 32 bit words
+	header[13] = tcpFlagSYN
+	binary.BigEndian.PutUint16(header[14:16], 1024)     // window
+	copy(header[20:24], []byte{0x02, 0x04, 0x05, 0xb4}) // MSS 1460
+	binary.BigEndian.PutUint16(header[16:18], tcpChecksum(source, destination, header))
+	return header
+}
+
+// tcpChecksum calculates the checksum over the IPv4 pseudo header and the TCP segment
+func tcpChecksum(source net.IP, destination net.IP, segment []byte) uint16 {
+	pseudoHeader := make([]byte, 0, 12+len(segment))
+	pseudoHeader = append(pseudoHeader, source.To4()...)
+	pseudoHeader = append(pseudoHeader, destination.To4()...)
+	pseudoHeader = append(pseudoHeader, 0, syscall.IPPROTO_TCP)
+	pseudoHeader = binary.BigEndian.AppendUint16(pseudoHeader, uint16(len(segment)))
+	pseudoHeader = append(pseudoHeader, segment...)
+	var sum uint32
+	for i := 0; i+1 < len(pseudoHeader); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(pseudoHeader[i : i+2]))
+	}
+	if len(pseudoHeader)%2 == 1 {
+		sum += uint32(pseudoHeader[len(pseudoHeader)-1]) << 8
+	}
+	for sum > 0xffff {
+		sum = (sum >> 16) + (sum & 0xffff)
+	}
+	return ^uint16(sum)
+}
+
+// parseSYNACK returns source address and port of an IPv4 packet if it is
+// a SYN-ACK to the given local port
+func parseSYNACK(packet []byte, localPort uint16) (net.IP, uint16, uint32, bool) {
+	if len(packet) < 20 || packet[0]>>4 != 4 || packet[9] != syscall.IPPROTO_TCP {
+		return nil, 0, 0, false
+	}
+	headerLength := int(packet[0]&0x0f) * 4
+	if len(packet) < headerLength+20 {
+		return nil, 0, 0, false
+	}
+	segment := packet[headerLength:]
+	if binary.BigEndian.Uint16(segment[2:4]) != localPort || segment[13]&(tcpFlagSYN|tcpFlagACK|tcpFlagRST) != tcpFlagSYN|tcpFlagACK {
+		return nil, 0, 0, false
+	}
+	return net.IP(packet[12:16]), binary.BigEndian.Uint16(segment[0:2]), binary.BigEndian.Uint32(segment[8:12]), true
+}
+
+// Close stops the receive goroutine and closes the raw socket. The scanner can't be used afterwards
+func (synscan *SYNScanner) Close() error {
+	close(synscan.done)
+	<-synscan.stopped
+	return nil
+}
+
+// receive reads all incoming TCP packets and reports the ports of valid SYN-ACKs as open.
+// The kernel answers them with a RST because there is no socket for them
+func (synscan *SYNScanner) receive() {
+	defer close(synscan.stopped)
+	defer syscall.Close(synscan.fd)
+	buf := make([]byte, 65536)
+	for {
+		select {
+		case <-synscan.done:
+			return
+		default:
+		}
+		n, _, err := syscall.Recvfrom(synscan.fd, buf, 0)
+		if err == syscall.EINTR || err == syscall.EAGAIN {
+			continue
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"module": "scanner.syn",
+				"src":    "receive",
+			}).Errorf("Receiving from raw socket failed, stopping: %v", err)
+			return
+		}
+		ip, port, ack, ok := parseSYNACK(buf[:n], synscan.sourcePort)
+		if !ok || ack-1 != synscan.cookie(ip, port) {
+			continue
+		}
+		target := ip.String()
+		key := fmt.Sprintf("%s:%d", target, port)
+		// The result is sent without holding the lock, sending SYNs needs it as well
+		synscan.lock.Lock()
+		batch := synscan.batch
+		if batch == nil || batch.reported[key] {
+			synscan.lock.Unlock()
+			continue
+		}
+		batch.reported[key] = true
+		batch.sending.Add(1)
+		result := withHostname(&PortscanResult{
+			Target:   target,
+			Port:     uint32(port),
+			Open:     true,
+			State:    "open",
+			Scantype: "tcpsyn",
+			Timeout:  synscan.timeout,
+			Source:   fmt.Sprintf("%s:%d", batch.sourceIPs[target], synscan.sourcePort),
+		}, batch.hostnames[target])
+		synscan.lock.Unlock()
+		batch.results <- result
+		batch.sending.Done()
+	}
+}
@@ -0,0 +1,87 @@
+package scanner
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	nraySchema "github.com/nray-scanner/nray/schemas"
+	"github.com/spf13/viper"
+)
+
+func TestBuildSYN(t *testing.T) {
+	source, destination := net.IPv4(192, 0, 2, 1), net.IPv4(198, 51, 100, 1)
+	segment := buildSYN(source, destination, 40000, 443, 0xdeadbeef)
+	if segment[13] != tcpFlagSYN || binary.BigEndian.Uint32(segment[4:8]) != 0xdeadbeef || binary.BigEndian.Uint16(segment[2:4]) != 443 {
+		t.Errorf("Wrong SYN: %x", segment)
+	}
+	// The checksum over a segment including its checksum is 0
+	if checksum := tcpChecksum(source, destination, segment); checksum != 0 {
+		t.Errorf("Invalid checksum, got %#x", checksum)
+	}
+}
+
+func TestParseSYNACK(t *testing.T) {
+	reply := func(flags byte) []byte {
+		packet := make([]byte, 40)
+		packet[0] = 0x45
+		packet[9] = 6
+		copy(packet[12:16], net.IPv4(192, 0, 2, 1).To4())
+		binary.BigEndian.PutUint16(packet[20:22], 22)
+		binary.BigEndian.PutUint16(packet[22:24], 40000)
+		binary.BigEndian.PutUint32(packet[28:32], 1234)
+		packet[33] = flags
+		return packet
+	}
+	ip, port, ack, ok := parseSYNACK(reply(tcpFlagSYN|tcpFlagACK), 40000)
+	if !ok || ip.String() != "192.0.2.1" || port != 22 || ack != 1234 {
+		t.Errorf("Wrong SYN-ACK: %s %d %d %t", ip, port, ack, ok)
+	}
+	if _, _, _, ok := parseSYNACK(reply(tcpFlagRST|tcpFlagACK), 40000); ok {
+		t.Errorf("RST must not be accepted")
+	}
+	if _, _, _, ok := parseSYNACK(reply(tcpFlagSYN|tcpFlagACK), 40001); ok {
+		t.Errorf("Replies to other ports must not be accepted")
+	}
+}
+
+func TestSYNScannerLoopback(t *testing.T) {
+	config := viper.New()
+	config.Set("timeout", "300ms")
+	synScanner, err := newSYNScanner(&TCPScanner{timeout: time.Second}, config)
+	if err != nil {
+		t.Skipf("SYN scanning not possible here: %v", err)
+	}
+	defer synScanner.(*SYNScanner).Close()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := uint32(closed.Addr().(*net.TCPAddr).Port)
+	closed.Close()
+	openPort := uint32(listener.Addr().(*net.TCPAddr).Port)
+
+	results := make(chan *PortscanResult, 10)
+	targets := &nraySchema.MoreWorkReply{Targets: &nraySchema.ScanTargets{
+		Rhosts:    []string{"127.0.0.1"},
+		Tcpports:  []uint32{openPort, closedPort},
+		Hostnames: map[string]string{"127.0.0.1": "localhost"},
+	}}
+	for task := range synScanner.PrepareScanFuncs(targets, results) {
+		go task.Run()
+	}
+	close(results)
+	found := make([]*PortscanResult, 0)
+	for result := range results {
+		found = append(found, result)
+	}
+	if len(found) != 1 || found[0].Port != openPort || found[0].Scantype != "tcpsyn" || found[0].Hostname != "localhost" {
+		t.Errorf("Expected only port %d to be open, got %v", openPort, found)
+	}
+}
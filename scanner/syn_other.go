@@ -0,0 +1,14 @@
+//go:build !linux
+
+package scanner
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// newSYNScanner is only implemented on Linux
+func newSYNScanner(connect *TCPScanner, config *viper.Viper) (TCPPortScanner, error) {
+	return nil, fmt.Errorf("SYN scanning is only supported on Linux")
+}
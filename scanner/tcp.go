@@ -1,11 +1,13 @@
 package scanner
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"strings"
 	"time"
 
+	nraySchema "github.com/nray-scanner/nray/schemas"
 	"github.com/nray-scanner/nray/utils"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -18,6 +20,16 @@ type PortscanResult struct {
 	Open     bool          `json:"Open"`
 	Scantype string        `json:"Scantype"`
 	Timeout  time.Duration `json:"Timeout"`
+	Hostname string        `json:"Hostname"`
+	State    string        `json:"State"`
+	// Only set for UDP ports that answered
+	UDPResponse *UDPResponse `json:"UDPResponse"`
+	// Local address the scan was sent from
+	Source string `json:"Source"`
+	// Proxy the scan was sent through
+	Proxy string `json:"Proxy"`
+	// IP address the target was scanned on, names are resolved to it
+	Address string `json:"Address"`
 }
 
 // TCPConnectIsOpen uses the operating system's mechanism to open a
@@ -25,10 +37,24 @@ type PortscanResult struct {
 // Timeout specifies how long to wait before aborting the connection
 // attempt
 func TCPConnectIsOpen(target string, port uint32, timeout time.Duration) (*PortscanResult, error) {
+	result, _, err := tcpConnect(target, port, timeout, nil)
+	return result, err
+}
+
+// tcpConnect works like TCPConnectIsOpen but connects with dial, which defaults to
+// net.DialTimeout, and additionally returns the round trip time if the target
+// answered, either by accepting or by refusing the connection. If the connection
+// failed because of a proxy or the source binding, an error is returned
+func tcpConnect(target string, port uint32, timeout time.Duration, dial dialFunc) (*PortscanResult, time.Duration, error) {
 	if target == "" {
-		return nil, fmt.Errorf("target is nil")
+		return nil, 0, fmt.Errorf("target is nil")
+	}
+	if dial == nil {
+		dial = net.DialTimeout
 	}
-	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", target, port), timeout)
+	start := time.Now()
+	conn, err := dial("tcp", fmt.Sprintf("%s:%d", target, port), timeout)
+	rtt := time.Since(start)
 	if err != nil {
 		if strings.Contains(err.Error(), "too many open files") {
 			log.WithFields(log.Fields{
@@ -36,24 +62,46 @@ func TCPConnectIsOpen(target string, port uint32, timeout time.Duration) (*Ports
 				"src":    "tcpConnectIsOpen",
 			}).Warning("Too many open files. You are running too many scan workers and the OS is limiting file descriptors. YOU ARE MISSING SCAN RESULTS. Scan with less workers")
 		}
-		return nil, nil // port is closed
+		if isRefused(err) {
+			return nil, rtt, nil // port is closed, but the host answered
+		}
+		if notScanned(err) {
+			return nil, 0, err // the port's state is unknown
+		}
+		return nil, 0, nil // port is closed
 	}
 	defer conn.Close()
 	result := PortscanResult{
 		Target:   target,
 		Port:     port,
 		Open:     true,
+		State:    "open",
 		Scantype: "tcpconnect",
 		Timeout:  timeout,
+		Source:   conn.LocalAddr().String(),
+		Proxy:    ProxyOf(conn),
+		Address:  remoteIP(conn),
 	}
-	return &result, nil
+	return &result, rtt, nil
+}
+
+// notScanned checks if a connection failed before it reached the target,
+// because of a proxy or the source binding
+func notScanned(err error) bool {
+	var failedProxy *proxyError
+	var failedSource *sourceError
+	return errors.As(err, &failedProxy) || errors.As(err, &failedSource)
 }
 
 // TCPScanner represents the built-in TCP scanning functionality of nray
 // If using other existing scanners or different scanning approaches are
 // required, it should not be hard to replace this
 type TCPScanner struct {
-	timeout time.Duration
+	timeout  time.Duration
+	rtt      *rttEstimator
+	source   *sourceBinding
+	proxies  *proxyList
+	resolver *nodeResolver
 }
 
 // Configure loads a viper configuration and sets the appropriate values
@@ -61,3 +109,48 @@ func (tcpscan *TCPScanner) Configure(config *viper.Viper) {
 	config = utils.ApplyDefaultScannerTCPConfig(config)
 	tcpscan.timeout = config.GetDuration("timeout")
 }
+
+// scan connects to target, using the adaptive timeout of the target's network if enabled
+func (tcpscan *TCPScanner) scan(target string, port uint32) (*PortscanResult, error) {
+	result, rtt, err := tcpConnect(target, port, tcpscan.rtt.timeout(target, tcpscan.timeout), tcpscan.dial)
+	tcpscan.rtt.observe(target, rtt)
+	return result, err
+}
+
+// dial connects through the proxies if configured, otherwise directly from the source.
+// Names are resolved by the node's resolver
+func (tcpscan *TCPScanner) dial(network string, address string, timeout time.Duration) (net.Conn, error) {
+	if tcpscan.proxies != nil {
+		return tcpscan.proxies.dial(network, address, timeout)
+	}
+	return tcpscan.resolver.dial(address, func(address string) (net.Conn, error) {
+		return tcpscan.source.dial(network, address, timeout)
+	})
+}
+
+// PrepareScanFuncs returns connect scans of all TCP targets, ordered host by host
+func (tcpscan *TCPScanner) PrepareScanFuncs(targetMsg *nraySchema.MoreWorkReply, results chan<- *PortscanResult) <-chan *ScanTask {
+	scanFuncs := make(chan *ScanTask, 100)
+	go func() {
+		hostnames := targetMsg.Targets.GetHostnames()
+		for _, target := range targetMsg.Targets.GetRhosts() {
+			for _, targetTCPPort := range targetMsg.Targets.GetTcpports() {
+				scanFuncs <- tcpscan.connectTask(target, targetTCPPort, hostnames[target], results)
+			}
+		}
+		for _, service := range targetMsg.Targets.GetServices() {
+			scanFuncs <- tcpscan.connectTask(service.GetRhost(), service.GetPort(), service.GetHostname(), results)
+		}
+		close(scanFuncs)
+	}()
+	return scanFuncs
+}
+
+// connectTask prepares the connect scan of a single port
+func (tcpscan *TCPScanner) connectTask(target string, port uint32, hostname string, results chan<- *PortscanResult) *ScanTask {
+	return &ScanTask{Host: target, Run: func() {
+		result, err := tcpscan.scan(target, port)
+		utils.CheckError(err, false)
+		results <- withHostname(result, hostname)
+	}}
+}
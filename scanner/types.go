@@ -2,6 +2,8 @@ package scanner
 
 import (
 	"fmt"
+	"io"
+	"net"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -11,6 +13,7 @@ import (
 
 	nraySchema "github.com/nray-scanner/nray/schemas"
 	"github.com/nray-scanner/nray/utils"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
@@ -92,18 +95,32 @@ type ScanController struct {
 	Subscriptions       map[string][]func(proto string, host string, port uint, results chan<- *nraySchema.Event) func()
 	subscriptionLock    sync.RWMutex
 	Pause               *PauseIndicator
-	scanQueue           chan func()
+	scanQueue           chan *ScanTask
 	eventQueue          chan *nraySchema.Event
 	portscanResultQueue chan *PortscanResult
 	results             []*nraySchema.Event
 	resultsLock         sync.Mutex
 	workersDone         bool
+	tcpScanner          *TCPScanner
+	tcpPortScanner      TCPPortScanner
+	udpScanner          *UDPScanner
 	ratelimiter         *rate.Limiter
 	scansRunning        int64
+	// Maps addresses of the current batch to the DNS names they were resolved from
+	hostnames map[string]string
+	scope     *NodeScope
+	// The node's share of the server's global rate limit, 0 if unlimited
+	serverRatelimit float64
+	ratelimitLock   sync.Mutex
+	// Per host and per network limits, reset for each batch
+	politeness *politeness
+	// Probes hosts before their ports are scanned
+	discovery *hostDiscovery
 }
 
-// CreateScanController initialises a new ScanController
-func CreateScanController(nodeID string, nodeName string, timeOffset time.Duration, scannerConfig *viper.Viper) *ScanController {
+// CreateScanController initialises a new ScanController. Errors in the
+// scanner configuration are returned
+func CreateScanController(nodeID string, nodeName string, timeOffset time.Duration, scannerConfig *viper.Viper) (*ScanController, error) {
 	if nodeName == "" {
 		nodeName = nodeID
 	}
@@ -114,7 +131,7 @@ func CreateScanController(nodeID string, nodeName string, timeOffset time.Durati
 		timeOffset:          timeOffset,
 		scannerConfig:       scannerConfig,
 		Subscriptions:       make(map[string][]func(string, string, uint, chan<- *nraySchema.Event) func()),
-		scanQueue:           make(chan func(), 1000),
+		scanQueue:           make(chan *ScanTask, 1000),
 		eventQueue:          make(chan *nraySchema.Event, 1000),
 		portscanResultQueue: make(chan *PortscanResult, 1000),
 		results:             make([]*nraySchema.Event, 0),
@@ -122,8 +139,103 @@ func CreateScanController(nodeID string, nodeName string, timeOffset time.Durati
 		Pause:               &PauseIndicator{scannerShouldPause: false},
 		ratelimiter:         rate.NewLimiter(rate.Inf, 1),
 		scansRunning:        0,
+		tcpScanner:          &TCPScanner{},
+		udpScanner:          &UDPScanner{},
+	}
+	sc.politeness = newPoliteness(scannerConfig.Sub("politeness"))
+	sc.discovery = newHostDiscovery(scannerConfig.Sub("discovery"))
+	sc.tcpScanner.Configure(scannerConfig.Sub("tcp"))
+	sc.udpScanner.Configure(scannerConfig.Sub("udp"))
+	// Round trip times are kept across batches, TCP and UDP share them
+	rtt := newRTTEstimator(scannerConfig.Sub("adaptiveTimeout"))
+	sc.tcpScanner.rtt = rtt
+	sc.udpScanner.rtt = rtt
+	// Scanning from the wrong address may violate allow lists of the target's firewall
+	source, err := newSourceBinding(scannerConfig.Sub("source"))
+	if err != nil {
+		return nil, err
+	}
+	if source != nil {
+		log.WithFields(log.Fields{
+			"module": "scanner.types",
+			"src":    "CreateScanController",
+		}).Infof("Binding scans to source %s", source)
+	}
+	sc.tcpScanner.source = source
+	sc.udpScanner.source = source
+	sc.discovery.source = source
+	resolver, err := newNodeResolver(scannerConfig.Sub("resolver"))
+	if err != nil {
+		return nil, err
+	}
+	sc.tcpScanner.resolver = resolver
+	sc.udpScanner.resolver = resolver
+	sc.udpScanner.waitForProbe = sc.waitForProbe
+	sc.discovery.resolver = resolver
+	proxies, err := newProxyList(scannerConfig.Sub("proxy"), source, resolver)
+	if err != nil {
+		return nil, err
+	}
+	sc.tcpScanner.proxies = proxies
+	sc.discovery.dial = sc.tcpScanner.dial
+	if proxies != nil {
+		log.WithFields(log.Fields{
+			"module": "scanner.types",
+			"src":    "CreateScanController",
+		}).Infof("Scanning TCP through proxies %s, UDP is scanned directly", proxies)
+		if sc.discovery.enabled && (sc.discovery.icmp || len(sc.discovery.udpPorts) > 0) {
+			log.WithFields(log.Fields{
+				"module": "scanner.types",
+				"src":    "CreateScanController",
+			}).Info("ICMP and UDP pings can't be proxied, only TCP pings are used for host discovery")
+			sc.discovery.icmp = false
+			sc.discovery.udpPorts = nil
+		}
+		// ACK pings would bypass the proxies
+		sc.discovery.tcpPorts = append(sc.discovery.tcpPorts, sc.discovery.ackPorts...)
+		sc.discovery.ackPorts = nil
+	}
+	sc.tcpPortScanner = sc.tcpScanner
+	if method := scannerConfig.GetString("tcp.method"); method == "syn" && proxies != nil {
+		log.WithFields(log.Fields{
+			"module": "scanner.types",
+			"src":    "CreateScanController",
+		}).Warning("SYN scanning is not possible through proxies, using connect scanning")
+	} else if method == "syn" {
+		synScanner, err := newSYNScanner(sc.tcpScanner, scannerConfig.Sub("tcp"))
+		if err != nil {
+			log.WithFields(log.Fields{
+				"module": "scanner.types",
+				"src":    "CreateScanController",
+			}).Warningf("SYN scanning is not possible, falling back to connect scanning: %v", err)
+		} else {
+			sc.tcpPortScanner = synScanner
+		}
+	} else if method != "connect" && method != "" {
+		log.WithFields(log.Fields{
+			"module": "scanner.types",
+			"src":    "CreateScanController",
+		}).Warningf("Unknown TCP scan method %s, using connect scanning", method)
+	}
+	sc.registerProtocolScanners()
+	return sc, nil
+}
+
+// protocolScanners contains constructors for all scanners of higher level protocols,
+// keyed by the name of their section in the scanner configuration
+var protocolScanners = map[string]func() ProtocolScanner{}
+
+// registerProtocolScanners configures all protocol scanners that are enabled
+// in the scanner configuration and lets them subscribe to the ports they are interested in
+func (controller *ScanController) registerProtocolScanners() {
+	for name, newProtocolScanner := range protocolScanners {
+		if !controller.scannerConfig.GetBool(name + ".enabled") {
+			continue
+		}
+		protocolScanner := newProtocolScanner()
+		protocolScanner.Configure(controller.scannerConfig.Sub(name), controller.nodeID, controller.nodeName)
+		protocolScanner.Register(controller)
 	}
-	return sc
 }
 
 // Refresh cleans the state for each workBatch.
@@ -131,21 +243,117 @@ func CreateScanController(nodeID string, nodeName string, timeOffset time.Durati
 // depends heavily on closing internal channels
 func (controller *ScanController) Refresh() {
 	controller.controllerLock.Lock()
-	controller.scanQueue = make(chan func(), 1000)
+	controller.scanQueue = make(chan *ScanTask, 1000)
 	controller.eventQueue = make(chan *nraySchema.Event, 1000)
 	controller.portscanResultQueue = make(chan *PortscanResult, 1000)
 	controller.results = make([]*nraySchema.Event, 0)
-	if controller.scannerConfig.GetString("ratelimit") == "none" {
-		controller.ratelimiter.SetLimit(rate.Inf)
-	} else {
-		controller.ratelimiter.SetLimit(rate.Limit(controller.scannerConfig.GetFloat64("ratelimit")))
-	}
+	controller.hostnames = make(map[string]string)
+	controller.politeness = newPoliteness(controller.scannerConfig.Sub("politeness"))
+	controller.applyRatelimit()
 	controller.controllerLock.Unlock()
 	go controller.processPortScanEvents()
 	go controller.processEventsToResults()
 }
 
-// Subscribe is called by protocol scanners to get notified in case interesting ports are open
+// Close releases what the scanners hold across batches, e.g. the raw socket of
+// the SYN scanner. The controller can't be used afterwards
+func (controller *ScanController) Close() {
+	if closer, ok := controller.tcpPortScanner.(io.Closer); ok {
+		utils.CheckError(closer.Close(), false)
+	}
+}
+
+// SetServerRatelimit sets the node's share of the server's global rate limit in
+// probes per second, 0 removes it. The lower of it and the configured ratelimit applies
+func (controller *ScanController) SetServerRatelimit(limit float64) {
+	controller.ratelimitLock.Lock()
+	changed := limit != controller.serverRatelimit
+	controller.serverRatelimit = limit
+	controller.ratelimitLock.Unlock()
+	if changed {
+		log.WithFields(log.Fields{
+			"module": "scanner.types",
+			"src":    "SetServerRatelimit",
+		}).Infof("Server assigned a rate limit of %.2f probes per second", limit)
+		controller.applyRatelimit()
+	}
+}
+
+// applyRatelimit updates the rate limiter from the configured and the server's rate limit
+func (controller *ScanController) applyRatelimit() {
+	controller.ratelimitLock.Lock()
+	defer controller.ratelimitLock.Unlock()
+	limit := rate.Inf
+	if controller.scannerConfig.GetString("ratelimit") != "none" {
+		limit = rate.Limit(controller.scannerConfig.GetFloat64("ratelimit"))
+	}
+	if controller.serverRatelimit > 0 && rate.Limit(controller.serverRatelimit) < limit {
+		limit = rate.Limit(controller.serverRatelimit)
+	}
+	controller.ratelimiter.SetLimit(limit)
+}
+
+// SetScope sets the scope enforced by the node. Targets outside of it are never scanned.
+// Names are resolved for the scope check by the node's resolver, like they are for
+// scanning. While a scope is enforced, names are never resolved by proxies
+func (controller *ScanController) SetScope(scope *NodeScope) {
+	controller.controllerLock.Lock()
+	defer controller.controllerLock.Unlock()
+	if scope != nil {
+		scope.lookup = controller.tcpScanner.resolver.resolveAll
+	}
+	// Proxies resolving names could scan other addresses than the checked ones
+	if controller.tcpScanner.proxies != nil {
+		controller.tcpScanner.proxies.localDNS.Store(scope.Enabled())
+	}
+	controller.scope = scope
+}
+
+// reportSkippedTarget wraps a target that was not scanned because it is out of
+// scope into an event, so the server learns about it
+func (controller *ScanController) reportSkippedTarget(skipped *nraySchema.SkippedTarget) {
+	timestamp, _ := ptypes.TimestampProto(currentTime())
+	controller.eventQueue <- &nraySchema.Event{
+		NodeID:      controller.nodeID,
+		NodeName:    controller.nodeName,
+		EventData:   &nraySchema.Event_Skipped{Skipped: skipped},
+		Scannername: "scope-enforcement",
+		Timestamp:   timestamp,
+	}
+}
+
+// setHostnames remembers the DNS names of the addresses in the current batch
+func (controller *ScanController) setHostnames(targets *nraySchema.ScanTargets) {
+	controller.controllerLock.Lock()
+	defer controller.controllerLock.Unlock()
+	for address, hostname := range targets.GetHostnames() {
+		controller.hostnames[address] = hostname
+	}
+	for _, service := range targets.GetServices() {
+		if service.GetHostname() != "" {
+			controller.hostnames[service.GetRhost()] = service.GetHostname()
+		}
+	}
+}
+
+// Dial opens a TCP connection like the TCP connect scanner does, from the configured
+// source and through the configured proxies. Protocol scanners should use it to
+// reach the same targets as the port scan. Use ProxyOf to find out the proxy used
+func (controller *ScanController) Dial(network string, address string, timeout time.Duration) (net.Conn, error) {
+	return controller.tcpScanner.dial(network, address, timeout)
+}
+
+// Hostname returns the DNS name an address of the current batch was resolved
+// from by the server. Protocol scanners may use it e.g. for TLS SNI or HTTP
+// Host headers. If the target was not given as DNS name, it is empty
+func (controller *ScanController) Hostname(address string) string {
+	controller.controllerLock.RLock()
+	defer controller.controllerLock.RUnlock()
+	return controller.hostnames[address]
+}
+
+// Subscribe is called by protocol scanners to get notified in case interesting ports are open.
+// Keys are like "tcp/80", "tcp/*" subscribes to all open TCP ports
 func (controller *ScanController) Subscribe(key string, function func(string, string, uint, chan<- *nraySchema.Event) func()) {
 	controller.subscriptionLock.Lock()
 	defer controller.subscriptionLock.Unlock()
@@ -163,9 +371,9 @@ func (controller *ScanController) notify(proto string, host string, port uint) {
 	defer controller.subscriptionLock.RUnlock()
 	key := fmt.Sprintf("%s/%d", proto, port)
 	//log.Debug(key)
-	if functions := controller.Subscriptions[key]; functions != nil {
-		for _, f := range functions {
-			controller.scanQueue <- f(proto, host, port, controller.eventQueue)
+	for _, key := range []string{key, proto + "/*"} {
+		for _, f := range controller.Subscriptions[key] {
+			controller.scanQueue <- &ScanTask{Host: host, Run: f(proto, host, port, controller.eventQueue)}
 		}
 	}
 }
@@ -182,15 +390,21 @@ func (controller *ScanController) processPortScanEvents() {
 		timestamp, _ := ptypes.TimestampProto(currentTime())
 		eventData := &nraySchema.Event_Result{
 			Result: &nraySchema.ScanResult{
-				Target: portscanResult.Target,
-				Port:   portscanResult.Port,
+				Target:   portscanResult.Target,
+				Port:     portscanResult.Port,
+				Hostname: portscanResult.Hostname,
 				Result: &nraySchema.ScanResult_Portscan{
 					Portscan: &nraySchema.PortScanResult{
-						Scantype: portscanResult.Scantype,
-						Target:   portscanResult.Target,
-						Port:     portscanResult.Port,
-						Open:     portscanResult.Open,
-						Timeout:  uint32(portscanResult.Timeout / time.Millisecond),
+						Scantype:    portscanResult.Scantype,
+						Target:      portscanResult.Target,
+						Port:        portscanResult.Port,
+						Open:        portscanResult.Open,
+						Timeout:     uint32(portscanResult.Timeout / time.Millisecond),
+						State:       portscanResult.State,
+						Udpresponse: udpResponseToProto(portscanResult.UDPResponse),
+						Source:      portscanResult.Source,
+						Proxy:       portscanResult.Proxy,
+						Address:     portscanResult.Address,
 					},
 				},
 			},
@@ -203,9 +417,12 @@ func (controller *ScanController) processPortScanEvents() {
 			Timestamp:   timestamp,
 		}
 		controller.eventQueue <- event
+		if portscanResult.UDPResponse != nil && portscanResult.UDPResponse.builtinProbe && controller.udpScanner.decode {
+			controller.decodeUDPService(portscanResult)
+		}
 
 		// Notify others
-		if portscanResult.Scantype == "tcpconnect" && portscanResult.Open {
+		if (portscanResult.Scantype == "tcpconnect" || portscanResult.Scantype == "tcpsyn") && portscanResult.Open {
 			//log.Debug("Notifying: %s:%d", portscanResult.Target, portscanResult.Port)
 			controller.notify("tcp", portscanResult.Target, uint(portscanResult.Port))
 		}
@@ -217,12 +434,58 @@ func (controller *ScanController) processPortScanEvents() {
 	close(controller.eventQueue)
 }
 
+// decodeUDPService creates an event from the decoded response of a UDP service
+func (controller *ScanController) decodeUDPService(portscanResult *PortscanResult) {
+	service, err := decodeUDPResponse(portscanResult.Port, portscanResult.UDPResponse.payload)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"module": "scanner.types",
+			"src":    "decodeUDPService",
+		}).Debugf("Decoding UDP response of %s:%d failed: %v", portscanResult.Target, portscanResult.Port, err)
+		return
+	}
+	if service == nil {
+		return
+	}
+	timestamp, _ := ptypes.TimestampProto(currentTime())
+	controller.eventQueue <- &nraySchema.Event{
+		NodeID:   controller.nodeID,
+		NodeName: controller.nodeName,
+		EventData: &nraySchema.Event_Result{
+			Result: &nraySchema.ScanResult{
+				Target:   portscanResult.Target,
+				Port:     portscanResult.Port,
+				Hostname: portscanResult.Hostname,
+				Result:   &nraySchema.ScanResult_Udpservice{Udpservice: service},
+			},
+		},
+		Scannername: "native-udpdecoder",
+		Timestamp:   timestamp,
+	}
+}
+
+// udpResponseToProto converts a captured UDP response, nil stays nil
+func udpResponseToProto(response *UDPResponse) *nraySchema.UDPResponse {
+	if response == nil {
+		return nil
+	}
+	return &nraySchema.UDPResponse{
+		Length:    uint32(response.Length),
+		Data:      response.Data,
+		Truncated: response.Truncated,
+		Probes:    uint32(response.Probes),
+	}
+}
+
+// processEventsToResults collects the events of a batch. The controller lock
+// is not held while doing so, the batch's hostnames are set meanwhile
 func (controller *ScanController) processEventsToResults() {
 	controller.controllerLock.RLock()
+	eventQueue := controller.eventQueue
+	controller.controllerLock.RUnlock()
 	controller.resultsLock.Lock()
-	defer controller.controllerLock.RUnlock()
 	defer controller.resultsLock.Unlock()
-	for event := range controller.eventQueue {
+	for event := range eventQueue {
 		controller.results = append(controller.results, event)
 	}
 }
@@ -262,7 +525,7 @@ func (controller *ScanController) waitForScanToFinishAndEventsToBeProcessed() {
 // TCPPortScanner is the interface all TCP Port Scanners must adhere to
 type TCPPortScanner interface {
 	Configure(config *viper.Viper)
-	PrepareScanFuncs(targetMsg *nraySchema.MoreWorkReply, results chan<- *PortscanResult) <-chan func()
+	PrepareScanFuncs(targetMsg *nraySchema.MoreWorkReply, results chan<- *PortscanResult) <-chan *ScanTask
 }
 
 // ProtocolScanner is the interface all scanners of higher level protocols must adhere to
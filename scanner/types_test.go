@@ -0,0 +1,51 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/golang/time/rate"
+	"github.com/spf13/viper"
+)
+
+func TestServerRatelimit(t *testing.T) {
+	config := viper.New()
+	config.Set("ratelimit", 100)
+	controller, err := CreateScanController("test", "test", 0, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	controller.Refresh()
+	if controller.ratelimiter.Limit() != 100 {
+		t.Errorf("Configured rate limit not applied: %f", controller.ratelimiter.Limit())
+	}
+	controller.SetServerRatelimit(10)
+	if controller.ratelimiter.Limit() != 10 {
+		t.Errorf("Lower server rate limit not applied: %f", controller.ratelimiter.Limit())
+	}
+	controller.SetServerRatelimit(1000)
+	if controller.ratelimiter.Limit() != 100 {
+		t.Errorf("Higher server rate limit must not raise the configured one: %f", controller.ratelimiter.Limit())
+	}
+
+	controller, err = CreateScanController("test", "test", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	controller.SetServerRatelimit(10)
+	controller.Refresh()
+	if controller.ratelimiter.Limit() != 10 {
+		t.Errorf("Server rate limit lost on refresh: %f", controller.ratelimiter.Limit())
+	}
+	controller.SetServerRatelimit(0)
+	if controller.ratelimiter.Limit() != rate.Inf {
+		t.Errorf("Removing the server rate limit failed: %f", controller.ratelimiter.Limit())
+	}
+}
+
+func TestCreateScanControllerInvalidConfig(t *testing.T) {
+	config := viper.New()
+	config.Set("source.ip", "not an address")
+	if _, err := CreateScanController("test", "test", 0, config); err == nil {
+		t.Errorf("Invalid source IP was accepted")
+	}
+}
@@ -1,17 +1,18 @@
 package scanner
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
 	"net"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"encoding/hex"
 
 	"github.com/nray-scanner/nray/utils"
-	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
@@ -19,23 +20,54 @@ import (
 type UDPScanner struct {
 	fast           bool
 	timeout        time.Duration
-	payloads       *map[uint32][]byte
+	payloads       *map[uint32][][]byte
 	defaultPayload []byte
+	// Built-in payloads are generated for each scan, so transaction IDs differ.
+	// Ports whose payloads were replaced by the user are not contained
+	builtinPayloads map[uint32]func() [][]byte
+	rtt             *rttEstimator
+	retries         int
+	backoff         float64
+	maxResponseSize int
+	decode          bool
+	source          *sourceBinding
+	resolver        *nodeResolver
+	// Called before each datagram but the first one, so retransmissions and
+	// further payloads count against the rate limits. Nil means no limits
+	waitForProbe func(host string)
+}
+
+// UDPResponse is the first response a target sent to a probe
+type UDPResponse struct {
+	Length    int    `json:"Length"`
+	Data      []byte `json:"Data"`
+	Truncated bool   `json:"Truncated"`
+	Probes    int    `json:"Probes"`
+	// The complete response, used for decoding it
+	payload []byte
+	// Only answers to built-in payloads are decoded, others may have asked for something else
+	builtinProbe bool
 }
 
 // UDPProtoScan uses the operating system's mechanism to open a
 // UDP connection to a given target IP address at a given port.
 // Timeout specifies how long to wait before aborting the connection
-// attempt
+// attempt. With adaptive timeouts, the timeout of the target's network is used.
+// Unanswered probes are retransmitted, each time waiting longer by the backoff factor.
+// If the target answers with ICMP port unreachable, the port is reported as closed.
+// If the target could not be probed, e.g. because its name doesn't resolve, an error is returned
 func UDPProtoScan(target string, port uint32, config UDPScanner) (*PortscanResult, error) {
-	// Get proto payload
-	payload, ok := (*config.payloads)[port]
-	if !ok {
+	// Get proto payloads
+	payloads, ok := (*config.payloads)[port]
+	generatePayloads, builtinProbe := config.builtinPayloads[port]
+	if builtinProbe {
+		payloads = generatePayloads()
+	} else if !ok {
 		if config.fast {
 			return nil, fmt.Errorf("Fast UDP scanning enabled and no payload known for UDP port %d", port)
 		}
 		// Load default payload
-		payload = config.defaultPayload
+		payloads = [][]byte{config.defaultPayload}
 	}
 
 	if target == "" {
@@ -43,41 +75,74 @@ func UDPProtoScan(target string, port uint32, config UDPScanner) (*PortscanResul
 	}
 	// UDP is connectionless, so establishing the "connection" has the timeout applied for e.g. DNS resolution
 	// In case of an IP address this should return immediately
-	conn, err := net.DialTimeout("udp", fmt.Sprintf("%s:%d", target, port), config.timeout)
+	timeout := config.rtt.timeout(target, config.timeout)
+	address, err := config.resolver.resolve(target)
+	if err != nil {
+		return nil, fmt.Errorf("UDP port %d of %s not scanned: %v", port, target, err)
+	}
+	conn, err := config.source.dial("udp", net.JoinHostPort(address, fmt.Sprint(port)), timeout)
 	if err != nil && strings.Contains(err.Error(), "socket: too many open files") {
 		return nil, fmt.Errorf("Too many open files. You are running too many scan workers and the OS is limiting file descriptors. YOU ARE MISSING SCAN RESULTS. Scan with less workers")
 	}
 	if err != nil {
-		utils.CheckError(err, false)
-		return nil, nil
+		return nil, fmt.Errorf("UDP port %d of %s not scanned: %v", port, target, err)
 	}
 	defer conn.Close()
-	// This is the real timeout that is applied. We send a packet and wait for a response or receive an error in case of timeout
-	conn.SetDeadline(time.Now().Add(config.timeout))
-	conn.Write(payload)
-	if err != nil {
-		log.WithFields(log.Fields{
-			"module": "scanner.udp",
-			"src":    "udpProtoScan",
-		}).Warning(err.Error())
 
-		utils.CheckError(err, false)
-		return nil, nil
-	}
-	// If reading throws an error, the port is closed (indicated by ICMP Type 3 Code 3 sent by target or by a timeout)
-	buf := make([]byte, 1024)
-	_, err = conn.Read(buf)
-	if err != nil {
-		return nil, nil
-	}
-	result := PortscanResult{
+	result := &PortscanResult{
 		Target:   target,
 		Port:     port,
-		Open:     true,
 		Scantype: "udp",
-		Timeout:  config.timeout,
+		Timeout:  timeout,
+		Source:   conn.LocalAddr().String(),
+		Address:  address,
+	}
+	buf := make([]byte, 65535)
+	probeTimeout := timeout
+	sent := 0
+	for probe := 1; probe <= config.retries+1; probe++ {
+		for _, payload := range payloads {
+			if sent > 0 && config.waitForProbe != nil {
+				config.waitForProbe(target)
+			}
+			sent++
+			if _, err := conn.Write(payload); err != nil {
+				if errors.Is(err, syscall.ECONNREFUSED) { // ICMP port unreachable of an earlier probe
+					result.State = "closed"
+					return result, nil
+				}
+				return nil, fmt.Errorf("UDP port %d of %s not scanned: %v", port, target, err)
+			}
+		}
+		// This is the real timeout that is applied. We send a packet and wait for a response or receive an error in case of timeout
+		start := time.Now()
+		conn.SetReadDeadline(start.Add(probeTimeout))
+		n, err := conn.Read(buf)
+		// Only answers to the first probe are unambiguous round trip times
+		if probe == 1 && (err == nil || errors.Is(err, syscall.ECONNREFUSED)) {
+			config.rtt.observe(target, time.Since(start))
+		}
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			result.State = "closed"
+			return result, nil
+		}
+		if err == nil {
+			result.Open = true
+			result.State = "open"
+			result.UDPResponse = &UDPResponse{
+				Length:       n,
+				Data:         append([]byte{}, buf[:min(n, config.maxResponseSize)]...),
+				Truncated:    n > config.maxResponseSize,
+				Probes:       probe,
+				payload:      append([]byte{}, buf[:n]...),
+				builtinProbe: builtinProbe,
+			}
+			return result, nil
+		}
+		probeTimeout = time.Duration(float64(probeTimeout) * config.backoff)
 	}
-	return &result, nil
+	// No response at all, the port is either filtered or open but ignored the probe
+	return nil, nil
 }
 
 // Configure sets relevant configuration on this scanner
@@ -85,25 +150,73 @@ func (udpscan *UDPScanner) Configure(config *viper.Viper) {
 	config = utils.ApplyDefaultScannerUDPConfig(config)
 	udpscan.timeout = config.GetDuration("timeout")
 	udpscan.fast = config.GetBool("fast")
+	udpscan.retries = config.GetInt("retries")
+	udpscan.backoff = config.GetFloat64("backoff")
+	udpscan.maxResponseSize = config.GetInt("maxResponseSize")
+	udpscan.decode = config.GetBool("decodeResponses")
 	decoded := []byte(config.GetString("defaultHexPayload"))
 	udpscan.defaultPayload = []byte(decoded)
-	p := make(map[uint32][]byte)
+	p := make(map[uint32][][]byte)
 	udpscan.payloads = &p
-	(*udpscan.payloads)[1604] = probePktCitrix()
-	(*udpscan.payloads)[53] = probePktDNS()
-	(*udpscan.payloads)[137] = probePktNetBios()
-	(*udpscan.payloads)[123] = probePktNTP()
-	(*udpscan.payloads)[524] = probePktDB2DISCO()
-	(*udpscan.payloads)[5093] = probePktSentinel()
-	(*udpscan.payloads)[1434] = probePktMSSQL()
-	(*udpscan.payloads)[161] = probePktSNMPv2()
-	(*udpscan.payloads)[111] = probePktPortmap()
+	udpscan.builtinPayloads = map[uint32]func() [][]byte{
+		1604: singlePayload(probePktCitrix),
+		53:   singlePayload(probePktDNS),
+		137:  singlePayload(probePktNetBios),
+		123:  singlePayload(probePktNTP),
+		524:  singlePayload(probePktDB2DISCO),
+		5093: singlePayload(probePktSentinel),
+		1434: singlePayload(probePktMSSQL),
+		111:  singlePayload(probePktPortmap),
+	}
+	// Without any community, port 161 is probed like ports without a known protocol
+	if communities := config.GetStringSlice("snmpCommunities"); len(communities) > 0 {
+		udpscan.builtinPayloads[161] = func() [][]byte {
+			payloads := make([][]byte, 0, len(communities))
+			for _, community := range communities {
+				payloads = append(payloads, probePktSNMPv2(community))
+			}
+			return payloads
+		}
+	}
+
+	// Probes of a probe library replace the built-in ones for their ports
+	probes := make([]UDPProbe, 0)
+	utils.CheckError(config.UnmarshalKey("probes", &probes), false)
+	libraryPorts := make(map[uint32]bool)
+	for _, probe := range probes {
+		ports, err := probe.portList()
+		if err != nil {
+			utils.CheckError(fmt.Errorf("skipping UDP probe: %v", err), false)
+			continue
+		}
+		payload, err := probe.payload()
+		if err != nil {
+			utils.CheckError(fmt.Errorf("skipping UDP probe for ports %s: %v", probe.Ports, err), false)
+			continue
+		}
+		for _, port := range ports {
+			if !libraryPorts[port] {
+				libraryPorts[port] = true
+				(*udpscan.payloads)[port] = nil
+				delete(udpscan.builtinPayloads, port)
+			}
+			(*udpscan.payloads)[port] = append((*udpscan.payloads)[port], payload)
+		}
+	}
 
 	customPayloads := config.GetStringMapString("customHexPayloads")
 	for customPayloadPort, customPayload := range customPayloads {
 		p, err := strconv.ParseUint(customPayloadPort, 10, 32)
 		utils.CheckError(err, false)
-		(*udpscan.payloads)[uint32(p)] = []byte(customPayload)
+		(*udpscan.payloads)[uint32(p)] = [][]byte{[]byte(customPayload)}
+		delete(udpscan.builtinPayloads, uint32(p))
+	}
+}
+
+// singlePayload wraps the generator of a built-in payload
+func singlePayload(generate func() []byte) func() [][]byte {
+	return func() [][]byte {
+		return [][]byte{generate()}
 	}
 }
 
@@ -167,10 +280,23 @@ func probePktMSSQL() []byte {
 	return []byte("\x02")
 }
 
-// https://github.com/rapid7/metasploit-framework/blob/eeed14d2a27759e369d48331b0959008a0b24df8/modules/auxiliary/scanner/discovery/udp_sweep.rb#L451
-func probePktSNMPv2() []byte {
-	// TODO: Go down the ASN.1 rabbit hole. Until then, the payload extracted from a network capture has to suffice
-	return []byte("0)\x02\x01\x01\x04\x06public\xa0\x1c\x02\x04w]l\xb1\x02\x01\x00\x02\x01\x000\x0e0\x0c\x06\x08+\x06\x01\x02\x01\x01\x01\x00\x05\x00")
+// probePktSNMPv2 builds an SNMPv2c GetRequest for sysDescr.0 (1.3.6.1.2.1.1.1.0)
+func probePktSNMPv2(community string) []byte {
+	// Not cryptographically relevant, so seeding with time should be OK
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	requestID := make([]byte, 4)
+	r.Read(requestID)
+	requestID[0] &= 0x7f // keep it positive
+	sysDescr := []byte{0x2b, 0x06, 0x01, 0x02, 0x01, 0x01, 0x01, 0x00}
+	varbind := berEncode(0x30, append(berEncode(0x06, sysDescr), berEncode(0x05, nil)...))
+	pdu := berEncode(0x02, requestID)
+	pdu = append(pdu, berEncode(0x02, []byte{0})...) // error status
+	pdu = append(pdu, berEncode(0x02, []byte{0})...) // error index
+	pdu = append(pdu, berEncode(0x30, varbind)...)
+	message := berEncode(0x02, []byte{1}) // version 2c
+	message = append(message, berEncode(0x04, []byte(community))...)
+	message = append(message, berEncode(0xa0, pdu)...)
+	return berEncode(0x30, message)
 }
 
 // https://github.com/rapid7/metasploit-framework/blob/eeed14d2a27759e369d48331b0959008a0b24df8/modules/auxiliary/scanner/discovery/udp_sweep.rb#L397
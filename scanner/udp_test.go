@@ -0,0 +1,117 @@
+package scanner
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func testUDPScanner(maxResponseSize int) UDPScanner {
+	config := viper.New()
+	config.Set("timeout", "100ms")
+	config.Set("retries", 2)
+	config.Set("maxResponseSize", maxResponseSize)
+	scanner := UDPScanner{}
+	scanner.Configure(config)
+	return scanner
+}
+
+func TestUDPProtoScanRetries(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	response := bytes.Repeat([]byte("A"), 100)
+	go func() {
+		buf := make([]byte, 1024)
+		// Drop the first probe to simulate packet loss
+		server.ReadFrom(buf)
+		_, addr, err := server.ReadFrom(buf)
+		if err == nil {
+			server.WriteTo(response, addr)
+		}
+	}()
+
+	port := uint32(server.LocalAddr().(*net.UDPAddr).Port)
+	result, err := UDPProtoScan("127.0.0.1", port, testUDPScanner(10))
+	if err != nil || result == nil || !result.Open || result.State != "open" {
+		t.Fatalf("Expected open port after retransmission, got %v %v", result, err)
+	}
+	if result.UDPResponse == nil || result.UDPResponse.Length != 100 || !result.UDPResponse.Truncated ||
+		!bytes.Equal(result.UDPResponse.Data, response[:10]) || result.UDPResponse.Probes != 2 {
+		t.Errorf("Wrong response captured: %+v", result.UDPResponse)
+	}
+}
+
+func TestUDPProtoScanClosed(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := uint32(server.LocalAddr().(*net.UDPAddr).Port)
+	server.Close()
+
+	result, err := UDPProtoScan("127.0.0.1", port, testUDPScanner(512))
+	if err != nil || result == nil {
+		t.Fatalf("Expected a result for a closed port, got %v %v", result, err)
+	}
+	if result.Open || result.State != "closed" || result.UDPResponse != nil {
+		t.Errorf("Expected closed port, got %+v", result)
+	}
+}
+
+func TestUDPProtoScanRatelimitsEachDatagram(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	port := uint32(server.LocalAddr().(*net.UDPAddr).Port)
+	scanner := testUDPScanner(512)
+	(*scanner.payloads)[port] = [][]byte{[]byte("a"), []byte("b")}
+	waited := 0
+	scanner.waitForProbe = func(host string) { waited++ }
+
+	// Two payloads sent three times, the first datagram is covered by the worker
+	if result, err := UDPProtoScan("127.0.0.1", port, scanner); result != nil || err != nil {
+		t.Fatalf("Expected no response, got %v %v", result, err)
+	}
+	if waited != 5 {
+		t.Errorf("Expected to wait for 5 datagrams, waited for %d", waited)
+	}
+}
+
+func TestUDPScannerWithoutSNMPCommunities(t *testing.T) {
+	config := viper.New()
+	config.Set("snmpCommunities", []string{})
+	scanner := UDPScanner{}
+	scanner.Configure(config)
+	if _, exists := scanner.builtinPayloads[161]; exists {
+		t.Errorf("Port 161 must fall back to the default payload without communities")
+	}
+}
+
+func TestUDPBuiltinPayloadsPerScan(t *testing.T) {
+	config := viper.New()
+	config.Set("snmpCommunities", []string{"public"})
+	config.Set("customHexPayloads", map[string]string{"123": "A"})
+	scanner := UDPScanner{}
+	scanner.Configure(config)
+	first, second := scanner.builtinPayloads[161](), scanner.builtinPayloads[161]()
+	if len(first) != 1 || bytes.Equal(first[0], second[0]) {
+		t.Errorf("SNMP request IDs must differ between scans: %x %x", first, second)
+	}
+	if _, exists := scanner.builtinPayloads[123]; exists {
+		t.Errorf("Replaced built-in payloads must not be sent or decoded")
+	}
+}
+
+func TestUDPProtoScanReportsUnresolvedTargets(t *testing.T) {
+	// Names under .invalid never resolve
+	if result, err := UDPProtoScan("nray.invalid", 53, testUDPScanner(512)); result != nil || err == nil {
+		t.Errorf("Expected an error for an unresolved target, got %v %v", result, err)
+	}
+}
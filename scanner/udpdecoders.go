@@ -0,0 +1,350 @@
+package scanner
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	nraySchema "github.com/nray-scanner/nray/schemas"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// udpDecoders parse the responses to the built-in probes, keyed by port
+var udpDecoders = map[uint32]func([]byte) (*nraySchema.UDPServiceResult, error){
+	53:   decodeDNSVersion,
+	111:  decodePortmap,
+	123:  decodeNTP,
+	137:  decodeNetBIOS,
+	161:  decodeSNMP,
+	1434: decodeMSSQLBrowser,
+	1604: decodeCitrix,
+}
+
+// decodeUDPResponse decodes the response of a UDP service if there is a decoder for the port
+func decodeUDPResponse(port uint32, response []byte) (*nraySchema.UDPServiceResult, error) {
+	decoder, exists := udpDecoders[port]
+	if !exists {
+		return nil, nil
+	}
+	return decoder(response)
+}
+
+// decodeDNSVersion reads the answer to a version.bind CHAOS TXT query
+func decodeDNSVersion(response []byte) (*nraySchema.UDPServiceResult, error) {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(response)
+	if err != nil {
+		return nil, err
+	}
+	if !header.Response {
+		return nil, fmt.Errorf("DNS message is no response")
+	}
+	if err := parser.SkipAllQuestions(); err != nil {
+		return nil, err
+	}
+	info := &nraySchema.DNSVersionInfo{Rcode: strings.TrimPrefix(header.RCode.String(), "RCode")}
+	for {
+		answerHeader, err := parser.AnswerHeader()
+		if err == dnsmessage.ErrSectionDone {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if answerHeader.Type != dnsmessage.TypeTXT {
+			if err := parser.SkipAnswer(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		txt, err := parser.TXTResource()
+		if err != nil {
+			return nil, err
+		}
+		info.Version = strings.Join(txt.TXT, "")
+		break
+	}
+	return &nraySchema.UDPServiceResult{
+		Service: "dns",
+		Info:    &nraySchema.UDPServiceResult_Dns{Dns: info},
+	}, nil
+}
+
+// decodeNetBIOS reads the name table of a NetBIOS node status response
+func decodeNetBIOS(response []byte) (*nraySchema.UDPServiceResult, error) {
+	// Header (12) + encoded name (34) + type, class, TTL, data length (10)
+	const namesOffset = 56
+	if len(response) < namesOffset+1 || binary.BigEndian.Uint16(response[2:4])&0x8000 == 0 {
+		return nil, fmt.Errorf("no NetBIOS node status response")
+	}
+	count := int(response[namesOffset])
+	if len(response) < namesOffset+1+count*18 {
+		return nil, fmt.Errorf("NetBIOS name table is truncated")
+	}
+	info := &nraySchema.NetBIOSInfo{}
+	for i := 0; i < count; i++ {
+		entry := response[namesOffset+1+i*18 : namesOffset+1+(i+1)*18]
+		name := &nraySchema.NetBIOSName{
+			Name:   strings.TrimRight(string(entry[:15]), " \x00"),
+			Suffix: uint32(entry[15]),
+			Group:  binary.BigEndian.Uint16(entry[16:18])&0x8000 != 0,
+		}
+		info.Names = append(info.Names, name)
+		switch {
+		case name.Suffix == 0x00 && !name.Group && info.ComputerName == "":
+			info.ComputerName = name.Name
+		case name.Suffix == 0x00 && name.Group && info.Domain == "":
+			info.Domain = name.Name
+		case name.Suffix == 0x03 && !name.Group && info.User == "" && name.Name != info.ComputerName:
+			info.User = name.Name
+		}
+	}
+	if macOffset := namesOffset + 1 + count*18; len(response) >= macOffset+6 {
+		info.Mac = net.HardwareAddr(response[macOffset : macOffset+6]).String()
+	}
+	return &nraySchema.UDPServiceResult{
+		Service: "netbios-ns",
+		Info:    &nraySchema.UDPServiceResult_Netbios{Netbios: info},
+	}, nil
+}
+
+// Seconds between the NTP epoch (1900) and the unix epoch
+const ntpEpochOffset = 2208988800
+
+// decodeNTP reads the header of an NTP response
+func decodeNTP(response []byte) (*nraySchema.UDPServiceResult, error) {
+	if len(response) < 48 {
+		return nil, fmt.Errorf("NTP response too short")
+	}
+	info := &nraySchema.NTPInfo{
+		Version: uint32(response[0]>>3) & 0x07,
+		Mode:    uint32(response[0]) & 0x07,
+		Stratum: uint32(response[1]),
+	}
+	// Primary servers use an ASCII identifier of their reference clock, others the address of their upstream server
+	if info.Stratum <= 1 {
+		info.ReferenceID = strings.TrimRight(string(response[12:16]), "\x00")
+	} else {
+		info.ReferenceID = net.IP(response[12:16]).String()
+	}
+	if seconds := binary.BigEndian.Uint32(response[40:44]); seconds != 0 {
+		fraction := binary.BigEndian.Uint32(response[44:48])
+		transmitTime := time.Unix(int64(seconds)-ntpEpochOffset, int64(fraction)*1e9>>32)
+		info.TransmitTime, _ = ptypes.TimestampProto(transmitTime)
+	}
+	return &nraySchema.UDPServiceResult{
+		Service: "ntp",
+		Info:    &nraySchema.UDPServiceResult_Ntp{Ntp: info},
+	}, nil
+}
+
+// decodeSNMP reads sysDescr from the response to a GetRequest
+func decodeSNMP(response []byte) (*nraySchema.UDPServiceResult, error) {
+	message, _, err := berElement(response, 0x30)
+	if err != nil {
+		return nil, err
+	}
+	_, message, err = berElement(message, 0x02) // version
+	if err != nil {
+		return nil, err
+	}
+	community, message, err := berElement(message, 0x04)
+	if err != nil {
+		return nil, err
+	}
+	pdu, _, err := berElement(message, 0xa2) // GetResponse
+	if err != nil {
+		return nil, err
+	}
+	_, pdu, err = berElement(pdu, 0x02) // request ID
+	if err != nil {
+		return nil, err
+	}
+	errorStatus, pdu, err := berElement(pdu, 0x02)
+	if err != nil {
+		return nil, err
+	}
+	info := &nraySchema.SNMPInfo{Community: string(community)}
+	for _, b := range errorStatus {
+		info.ErrorStatus = info.ErrorStatus<<8 | uint32(b)
+	}
+	_, pdu, err = berElement(pdu, 0x02) // error index
+	if err != nil {
+		return nil, err
+	}
+	varbinds, _, err := berElement(pdu, 0x30)
+	if err != nil {
+		return nil, err
+	}
+	if varbind, _, err := berElement(varbinds, 0x30); err == nil {
+		if _, value, err := berElement(varbind, 0x06); err == nil {
+			// Anything else than an octet string is an exception like noSuchObject
+			if sysDescr, _, err := berElement(value, 0x04); err == nil {
+				info.SysDescr = string(sysDescr)
+			}
+		}
+	}
+	return &nraySchema.UDPServiceResult{
+		Service: "snmp",
+		Info:    &nraySchema.UDPServiceResult_Snmp{Snmp: info},
+	}, nil
+}
+
+// berElement reads a BER encoded element with the expected tag and returns its content and the remaining data
+func berElement(data []byte, tag byte) ([]byte, []byte, error) {
+	if len(data) < 2 {
+		return nil, nil, fmt.Errorf("BER element too short")
+	}
+	if data[0] != tag {
+		return nil, nil, fmt.Errorf("expected BER tag %#x, got %#x", tag, data[0])
+	}
+	length := int(data[1])
+	offset := 2
+	if length&0x80 != 0 {
+		lengthBytes := length & 0x7f
+		if lengthBytes == 0 || lengthBytes > 3 || len(data) < 2+lengthBytes {
+			return nil, nil, fmt.Errorf("invalid BER length")
+		}
+		length = 0
+		for _, b := range data[2 : 2+lengthBytes] {
+			length = length<<8 | int(b)
+		}
+		offset += lengthBytes
+	}
+	if len(data) < offset+length {
+		return nil, nil, fmt.Errorf("BER element is truncated")
+	}
+	return data[offset : offset+length], data[offset+length:], nil
+}
+
+// berEncode encodes content as BER element with the given tag
+func berEncode(tag byte, content []byte) []byte {
+	length := len(content)
+	var encoded []byte
+	switch {
+	case length < 0x80:
+		encoded = []byte{tag, byte(length)}
+	case length <= 0xff:
+		encoded = []byte{tag, 0x81, byte(length)}
+	default:
+		encoded = []byte{tag, 0x82, byte(length >> 8), byte(length)}
+	}
+	return append(encoded, content...)
+}
+
+// Well known RPC programs
+var rpcPrograms = map[uint32]string{
+	100000: "portmapper",
+	100001: "rstatd",
+	100002: "rusersd",
+	100003: "nfs",
+	100004: "ypserv",
+	100005: "mountd",
+	100007: "ypbind",
+	100008: "walld",
+	100011: "rquotad",
+	100021: "nlockmgr",
+	100024: "status",
+	100227: "nfs_acl",
+}
+
+// decodePortmap reads the program list of a portmapper DUMP reply
+func decodePortmap(response []byte) (*nraySchema.UDPServiceResult, error) {
+	// XID, message type, reply state, verifier flavor and length
+	if len(response) < 20 {
+		return nil, fmt.Errorf("RPC reply too short")
+	}
+	if binary.BigEndian.Uint32(response[4:8]) != 1 || binary.BigEndian.Uint32(response[8:12]) != 0 {
+		return nil, fmt.Errorf("RPC call was not accepted")
+	}
+	offset := 20 + int(binary.BigEndian.Uint32(response[16:20]))
+	if len(response) < offset+4 || binary.BigEndian.Uint32(response[offset:offset+4]) != 0 {
+		return nil, fmt.Errorf("RPC call failed")
+	}
+	offset += 4
+	info := &nraySchema.PortmapInfo{}
+	// The list is a sequence of entries, each preceded by a flag if another one follows
+	for len(response) >= offset+4 && binary.BigEndian.Uint32(response[offset:offset+4]) == 1 {
+		if len(response) < offset+20 {
+			return nil, fmt.Errorf("portmap program list is truncated")
+		}
+		entry := response[offset+4 : offset+20]
+		program := &nraySchema.PortmapProgram{
+			Program:  binary.BigEndian.Uint32(entry[0:4]),
+			Version:  binary.BigEndian.Uint32(entry[4:8]),
+			Protocol: strconv.Itoa(int(binary.BigEndian.Uint32(entry[8:12]))),
+			Port:     binary.BigEndian.Uint32(entry[12:16]),
+		}
+		program.Name = rpcPrograms[program.Program]
+		switch program.Protocol {
+		case "6":
+			program.Protocol = "tcp"
+		case "17":
+			program.Protocol = "udp"
+		}
+		info.Programs = append(info.Programs, program)
+		offset += 20
+	}
+	return &nraySchema.UDPServiceResult{
+		Service: "rpcbind",
+		Info:    &nraySchema.UDPServiceResult_Portmap{Portmap: info},
+	}, nil
+}
+
+// decodeMSSQLBrowser reads the instance list of a SQL Server Browser response
+func decodeMSSQLBrowser(response []byte) (*nraySchema.UDPServiceResult, error) {
+	if len(response) < 3 || response[0] != 0x05 {
+		return nil, fmt.Errorf("no SQL Server Browser response")
+	}
+	length := int(binary.LittleEndian.Uint16(response[1:3]))
+	if len(response) < 3+length {
+		return nil, fmt.Errorf("SQL Server Browser response is truncated")
+	}
+	info := &nraySchema.MSSQLBrowserInfo{}
+	// Instances are separated by ";;", each is a list of key;value pairs
+	for _, instance := range strings.Split(string(response[3:3+length]), ";;") {
+		fields := strings.Split(instance, ";")
+		if len(fields) < 2 {
+			continue
+		}
+		parsed := &nraySchema.MSSQLInstance{}
+		for i := 0; i+1 < len(fields); i += 2 {
+			value := fields[i+1]
+			switch strings.ToLower(fields[i]) {
+			case "servername":
+				parsed.ServerName = value
+			case "instancename":
+				parsed.InstanceName = value
+			case "isclustered":
+				parsed.Clustered = strings.EqualFold(value, "yes")
+			case "version":
+				parsed.Version = value
+			case "tcp":
+				port, _ := strconv.ParseUint(value, 10, 16)
+				parsed.TcpPort = uint32(port)
+			case "np":
+				parsed.NamedPipe = value
+			}
+		}
+		info.Instances = append(info.Instances, parsed)
+	}
+	return &nraySchema.UDPServiceResult{
+		Service: "ms-sql-m",
+		Info:    &nraySchema.UDPServiceResult_Mssql{Mssql: info},
+	}, nil
+}
+
+// decodeCitrix checks the header of an ICA browser reply
+func decodeCitrix(response []byte) (*nraySchema.UDPServiceResult, error) {
+	if len(response) < 8 || string(response[4:8]) != "\x02\xfd\xa8\xe3" {
+		return nil, fmt.Errorf("no ICA browser reply")
+	}
+	return &nraySchema.UDPServiceResult{
+		Service: "citrix-ica-browser",
+		Info:    &nraySchema.UDPServiceResult_Citrix{Citrix: &nraySchema.CitrixInfo{ReplyType: uint32(response[3])}},
+	}, nil
+}
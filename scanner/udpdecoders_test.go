@@ -0,0 +1,145 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestDecodeDNSVersion(t *testing.T) {
+	name := dnsmessage.MustNewName("version.bind.")
+	message := dnsmessage.Message{
+		Header:    dnsmessage.Header{Response: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: dnsmessage.TypeTXT, Class: dnsmessage.ClassCHAOS}},
+		Answers: []dnsmessage.Resource{{
+			Header: dnsmessage.ResourceHeader{Name: name, Type: dnsmessage.TypeTXT, Class: dnsmessage.ClassCHAOS},
+			Body:   &dnsmessage.TXTResource{TXT: []string{"9.18.19"}},
+		}},
+	}
+	response, err := message.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := decodeDNSVersion(response)
+	if err != nil || result.GetDns().GetVersion() != "9.18.19" || result.GetDns().GetRcode() != "Success" {
+		t.Errorf("Wrong DNS version: %v %v", result, err)
+	}
+	if _, err := decodeDNSVersion(probePktDNS()); err == nil {
+		t.Errorf("Queries must not be decoded as responses")
+	}
+}
+
+func TestDecodeNetBIOS(t *testing.T) {
+	response := make([]byte, 56)
+	response[2] = 0x84 // response flag
+	entry := func(name string, suffix byte, flags uint16) []byte {
+		padded := []byte(name + "               ")[:15]
+		padded = append(padded, suffix)
+		return binary.BigEndian.AppendUint16(padded, flags)
+	}
+	response = append(response, 3)
+	response = append(response, entry("FILESERVER", 0x00, 0x0400)...)
+	response = append(response, entry("WORKGROUP", 0x00, 0x8400)...)
+	response = append(response, entry("ALICE", 0x03, 0x0400)...)
+	response = append(response, 0x00, 0x50, 0x56, 0x01, 0x02, 0x03)
+	result, err := decodeNetBIOS(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := result.GetNetbios()
+	if info.ComputerName != "FILESERVER" || info.Domain != "WORKGROUP" || info.User != "ALICE" || info.Mac != "00:50:56:01:02:03" || len(info.Names) != 3 {
+		t.Errorf("Wrong NetBIOS info: %v", info)
+	}
+	if _, err := decodeNetBIOS(response[:70]); err == nil {
+		t.Errorf("Truncated name table should fail")
+	}
+}
+
+func TestDecodeNTP(t *testing.T) {
+	response := make([]byte, 48)
+	response[0] = 0x24 // version 4, server mode
+	response[1] = 1
+	copy(response[12:16], "GPS\x00")
+	binary.BigEndian.PutUint32(response[40:44], 3913056000) // 2024-01-01
+	result, err := decodeNTP(response)
+	info := result.GetNtp()
+	if err != nil || info.Version != 4 || info.Mode != 4 || info.Stratum != 1 || info.ReferenceID != "GPS" || info.TransmitTime.GetSeconds() != 1704067200 {
+		t.Errorf("Wrong NTP info: %v %v", info, err)
+	}
+	response[1] = 2
+	copy(response[12:16], []byte{192, 0, 2, 1})
+	if result, _ := decodeNTP(response); result.GetNtp().ReferenceID != "192.0.2.1" {
+		t.Errorf("Expected upstream server as reference, got %s", result.GetNtp().ReferenceID)
+	}
+}
+
+func TestDecodeSNMP(t *testing.T) {
+	probe := probePktSNMPv2("private")
+	message, _, err := berElement(probe, 0x30)
+	if err != nil {
+		t.Fatalf("Probe is no valid BER: %v", err)
+	}
+	if !bytes.Contains(message, []byte("private")) {
+		t.Errorf("Community missing in probe")
+	}
+
+	varbind := berEncode(0x30, append(berEncode(0x06, []byte{0x2b, 6, 1, 2, 1, 1, 1, 0}), berEncode(0x04, []byte("Linux router 5.15"))...))
+	pdu := append(berEncode(0x02, []byte{1, 2, 3, 4}), berEncode(0x02, []byte{0})...)
+	pdu = append(pdu, berEncode(0x02, []byte{0})...)
+	pdu = append(pdu, berEncode(0x30, varbind)...)
+	response := append(berEncode(0x02, []byte{1}), berEncode(0x04, []byte("private"))...)
+	response = berEncode(0x30, append(response, berEncode(0xa2, pdu)...))
+	result, err := decodeSNMP(response)
+	if err != nil || result.GetSnmp().Community != "private" || result.GetSnmp().SysDescr != "Linux router 5.15" {
+		t.Errorf("Wrong SNMP info: %v %v", result, err)
+	}
+	if _, err := decodeSNMP(probe); err == nil {
+		t.Errorf("Requests must not be decoded as responses")
+	}
+}
+
+func TestDecodePortmap(t *testing.T) {
+	response := []byte{0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	for _, program := range [][4]uint32{{100000, 2, 6, 111}, {100003, 3, 17, 2049}} {
+		response = binary.BigEndian.AppendUint32(response, 1)
+		for _, value := range program {
+			response = binary.BigEndian.AppendUint32(response, value)
+		}
+	}
+	response = binary.BigEndian.AppendUint32(response, 0)
+	result, err := decodePortmap(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	programs := result.GetPortmap().Programs
+	if len(programs) != 2 || programs[1].Name != "nfs" || programs[1].Protocol != "udp" || programs[1].Port != 2049 || programs[0].Protocol != "tcp" {
+		t.Errorf("Wrong portmap programs: %v", programs)
+	}
+}
+
+func TestDecodeMSSQLBrowser(t *testing.T) {
+	instances := "ServerName;DB01;InstanceName;MSSQLSERVER;IsClustered;No;Version;15.0.2000.5;tcp;1433;;" +
+		"ServerName;DB01;InstanceName;REPORTING;IsClustered;Yes;Version;15.0.2000.5;np;\\\\DB01\\pipe\\sql\\query;;"
+	response := binary.LittleEndian.AppendUint16([]byte{0x05}, uint16(len(instances)))
+	response = append(response, instances...)
+	result, err := decodeMSSQLBrowser(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed := result.GetMssql().Instances
+	if len(parsed) != 2 || parsed[0].TcpPort != 1433 || parsed[0].InstanceName != "MSSQLSERVER" || !parsed[1].Clustered || parsed[1].NamedPipe == "" {
+		t.Errorf("Wrong MSSQL instances: %v", parsed)
+	}
+}
+
+func TestDecodeCitrix(t *testing.T) {
+	result, err := decodeCitrix([]byte("\x30\x00\x02\x31\x02\xfd\xa8\xe3\x02\x00\x06\x44"))
+	if err != nil || result.GetCitrix().ReplyType != 0x31 {
+		t.Errorf("Wrong Citrix info: %v %v", result, err)
+	}
+	if result, err := decodeUDPResponse(9999, []byte("A")); result != nil || err != nil {
+		t.Errorf("Ports without decoder must be ignored")
+	}
+}
@@ -0,0 +1,239 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// UDPProbe is a payload of a probe library that is sent to all of its ports.
+// Ports is a comma separated list of ports and port ranges like 161-162,
+// the payload is given either hex or base64 encoded
+type UDPProbe struct {
+	Ports  string `mapstructure:"ports"`
+	Hex    string `mapstructure:"hex"`
+	Base64 string `mapstructure:"base64"`
+}
+
+// payload decodes the probe's payload
+func (probe UDPProbe) payload() ([]byte, error) {
+	switch {
+	case probe.Hex != "" && probe.Base64 != "":
+		return nil, fmt.Errorf("probe for ports %s has both a hex and a base64 payload", probe.Ports)
+	case probe.Hex != "":
+		return hex.DecodeString(strings.Join(strings.Fields(probe.Hex), ""))
+	default:
+		return base64.StdEncoding.DecodeString(probe.Base64)
+	}
+}
+
+// portList expands the probe's ports
+func (probe UDPProbe) portList() ([]uint32, error) {
+	return parsePortList(probe.Ports)
+}
+
+// parsePortList expands a comma separated list of ports and port ranges like 161-162
+func parsePortList(list string) ([]uint32, error) {
+	ports := make([]uint32, 0)
+	for _, portRange := range strings.Split(list, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(portRange), "-", 2)
+		first, err := strconv.ParseUint(bounds[0], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %s", bounds[0])
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.ParseUint(bounds[1], 10, 16); err != nil || last < first {
+				return nil, fmt.Errorf("invalid port range %s", portRange)
+			}
+		}
+		for port := first; port <= last; port++ {
+			ports = append(ports, uint32(port))
+		}
+	}
+	return ports, nil
+}
+
+// ParseUDPProbeFile reads a probe library. Files ending in .yaml, .yml or .json
+// contain a list of probes under the key probes, everything else is parsed
+// as nmap-payloads file
+func ParseUDPProbeFile(path string) ([]UDPProbe, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		library := viper.New()
+		library.SetConfigFile(path)
+		if err := library.ReadInConfig(); err != nil {
+			return nil, err
+		}
+		probes := make([]UDPProbe, 0)
+		if err := library.UnmarshalKey("probes", &probes); err != nil {
+			return nil, err
+		}
+		for _, probe := range probes {
+			if _, err := probe.portList(); err != nil {
+				return nil, err
+			}
+			if _, err := probe.payload(); err != nil {
+				return nil, fmt.Errorf("invalid payload for ports %s: %v", probe.Ports, err)
+			}
+		}
+		return probes, nil
+	default:
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return parseNmapPayloads(bufio.NewReader(file))
+	}
+}
+
+// parseNmapPayloads parses the nmap-payloads format: "udp", a port list and one
+// or more C style quoted strings that are concatenated, optionally followed by
+// "source <port>" which is ignored. Comments start with #
+func parseNmapPayloads(reader *bufio.Reader) ([]UDPProbe, error) {
+	tokens := make([]string, 0)
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		lineTokens, err := tokenizeNmapPayloads(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		tokens = append(tokens, lineTokens...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	probes := make([]UDPProbe, 0)
+	for i := 0; i < len(tokens); {
+		if tokens[i] != "udp" || i+2 >= len(tokens) || !strings.HasPrefix(tokens[i+2], "\"") {
+			return nil, fmt.Errorf("expected udp <ports> \"payload\", got %s", tokens[i])
+		}
+		probe := UDPProbe{Ports: tokens[i+1]}
+		if _, err := probe.portList(); err != nil {
+			return nil, err
+		}
+		payload := make([]byte, 0)
+		for i += 2; i < len(tokens) && strings.HasPrefix(tokens[i], "\""); i++ {
+			payload = append(payload, tokens[i][1:]...)
+		}
+		if i+1 < len(tokens) && tokens[i] == "source" {
+			i += 2
+		}
+		probe.Base64 = base64.StdEncoding.EncodeToString(payload)
+		probes = append(probes, probe)
+	}
+	return probes, nil
+}
+
+// tokenizeNmapPayloads splits a line into words and unescaped strings.
+// Strings are returned with their opening quote to tell them apart from words
+func tokenizeNmapPayloads(line string) ([]string, error) {
+	tokens := make([]string, 0)
+	for i := 0; i < len(line); {
+		switch c := line[i]; {
+		case c == '#':
+			return tokens, nil
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '"':
+			value := []byte{'"'}
+			i++
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] != '\\' {
+					value = append(value, line[i])
+					continue
+				}
+				decoded, end, err := nmapEscape(line, i+1)
+				if err != nil {
+					return nil, err
+				}
+				value = append(value, decoded)
+				i = end
+			}
+			if i >= len(line) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, string(value))
+			i++
+		default:
+			start := i
+			for i < len(line) && !strings.ContainsRune(" \t\r\"#", rune(line[i])) {
+				i++
+			}
+			tokens = append(tokens, line[start:i])
+		}
+	}
+	return tokens, nil
+}
+
+// nmapEscape decodes the C style escape sequence starting at line[i], right after
+// the backslash. It returns the byte and the index of the last character of the sequence
+func nmapEscape(line string, i int) (byte, int, error) {
+	if i >= len(line) {
+		return 0, i, fmt.Errorf("unterminated escape sequence")
+	}
+	switch line[i] {
+	case 'x':
+		if i+2 >= len(line) {
+			return 0, i, fmt.Errorf("short hex escape")
+		}
+		decoded, err := hex.DecodeString(line[i+1 : i+3])
+		if err != nil {
+			return 0, i, fmt.Errorf("invalid hex escape \\x%s", line[i+1:i+3])
+		}
+		return decoded[0], i + 2, nil
+	case '0':
+		return 0, i, nil
+	case 'a':
+		return '\a', i, nil
+	case 'b':
+		return '\b', i, nil
+	case 'f':
+		return '\f', i, nil
+	case 'n':
+		return '\n', i, nil
+	case 'r':
+		return '\r', i, nil
+	case 't':
+		return '\t', i, nil
+	case 'v':
+		return '\v', i, nil
+	default:
+		return line[i], i, nil
+	}
+}
+
+// LoadUDPProbeFile reads the probe library set as udp.probeFile of a scanner
+// configuration and adds its probes to udp.probes. This way, the library is
+// shipped to nodes as part of the configuration and they don't need the file
+func LoadUDPProbeFile(scannerConfig *viper.Viper) error {
+	path := scannerConfig.GetString("udp.probeFile")
+	if path == "" {
+		return nil
+	}
+	probes, err := ParseUDPProbeFile(path)
+	if err != nil {
+		return fmt.Errorf("loading UDP probe file %s failed: %v", path, err)
+	}
+	configured := make([]UDPProbe, 0)
+	if err := scannerConfig.UnmarshalKey("udp.probes", &configured); err != nil {
+		return err
+	}
+	serialized := make([]interface{}, 0, len(configured)+len(probes))
+	for _, probe := range append(configured, probes...) {
+		serialized = append(serialized, map[string]interface{}{"ports": probe.Ports, "hex": probe.Hex, "base64": probe.Base64})
+	}
+	return scannerConfig.MergeConfigMap(map[string]interface{}{
+		"udp": map[string]interface{}{"probes": serialized, "probefile": ""},
+	})
+}
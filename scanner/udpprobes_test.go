@@ -0,0 +1,95 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const testNmapPayloads = `# Comment
+udp 7 "echo\n"
+udp 53,5353 "\x00\x00\x10\x00" # DNS status request
+  "\x00\x00"
+  source 53
+udp 161-162 "\x30\x82"
+`
+
+func TestParseNmapPayloads(t *testing.T) {
+	probes, err := parseNmapPayloads(bufio.NewReader(strings.NewReader(testNmapPayloads)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(probes) != 3 {
+		t.Fatalf("Expected 3 probes, got %v", probes)
+	}
+	expected := [][]byte{[]byte("echo\n"), {0, 0, 0x10, 0, 0, 0}, {0x30, 0x82}}
+	for i, probe := range probes {
+		if payload, err := probe.payload(); err != nil || !bytes.Equal(payload, expected[i]) {
+			t.Errorf("Wrong payload for ports %s: %x %v", probe.Ports, payload, err)
+		}
+	}
+	if ports, err := probes[2].portList(); err != nil || len(ports) != 2 || ports[0] != 161 || ports[1] != 162 {
+		t.Errorf("Wrong ports: %v %v", ports, err)
+	}
+	for _, invalid := range []string{`udp 53`, `tcp 80 "GET"`, `udp 53 "\x0"`, `udp 53 "unterminated`, `udp 70000 "A"`} {
+		if _, err := parseNmapPayloads(bufio.NewReader(strings.NewReader(invalid))); err == nil {
+			t.Errorf("Parsing %q should fail", invalid)
+		}
+	}
+}
+
+func TestLoadUDPProbeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "probes.yaml")
+	library := "probes:\n  - ports: 53\n    hex: \"00 01\"\n  - ports: \"53,1000-1001\"\n    base64: \"AgM=\"\n"
+	if err := os.WriteFile(path, []byte(library), 0600); err != nil {
+		t.Fatal(err)
+	}
+	scannerConfig := viper.New()
+	scannerConfig.MergeConfigMap(map[string]interface{}{"udp": map[string]interface{}{
+		"probeFile": path,
+		"probes":    []interface{}{map[string]interface{}{"ports": "7", "hex": "41"}},
+	}})
+	if err := LoadUDPProbeFile(scannerConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nodes receive the configuration as JSON
+	serialized, err := json.Marshal(scannerConfig.AllSettings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodeConfig := viper.New()
+	nodeConfig.SetConfigType("json")
+	if err := nodeConfig.ReadConfig(bytes.NewBuffer(serialized)); err != nil {
+		t.Fatal(err)
+	}
+	if nodeConfig.GetString("udp.probeFile") != "" {
+		t.Errorf("Probe file should not be sent to nodes")
+	}
+	udpScanner := UDPScanner{}
+	udpScanner.Configure(nodeConfig.Sub("udp"))
+	payloads := *udpScanner.payloads
+	if len(payloads[53]) != 2 || !bytes.Equal(payloads[53][0], []byte{0, 1}) || !bytes.Equal(payloads[53][1], []byte{2, 3}) {
+		t.Errorf("Library probes should replace the built-in DNS probe: %v", payloads[53])
+	}
+	if len(payloads[1001]) != 1 || len(payloads[7]) != 1 {
+		t.Errorf("Wrong payloads: %v %v", payloads[1001], payloads[7])
+	}
+	if _, exists := udpScanner.builtinPayloads[53]; exists {
+		t.Errorf("Library probes should not be decoded as built-in DNS probes")
+	}
+	if _, exists := udpScanner.builtinPayloads[123]; !exists {
+		t.Errorf("Built-in NTP probe should be kept")
+	}
+
+	scannerConfig.MergeConfigMap(map[string]interface{}{"udp": map[string]interface{}{"probeFile": filepath.Join(t.TempDir(), "missing.json")}})
+	if err := LoadUDPProbeFile(scannerConfig); err == nil {
+		t.Errorf("Missing probe file should fail")
+	}
+}
@@ -23,7 +23,7 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 // Event is a container for everything that happens
-//at a node and should later on be handled by EventHandlers
+// at a node and should later on be handled by EventHandlers
 type Event struct {
 	NodeID      string               `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
 	NodeName    string               `protobuf:"bytes,2,opt,name=nodeName,proto3" json:"nodeName,omitempty"`
@@ -32,6 +32,8 @@ type Event struct {
 	// Types that are valid to be assigned to EventData:
 	//	*Event_Environment
 	//	*Event_Result
+	//	*Event_Skipped
+	//	*Event_Host
 	EventData            isEvent_EventData `protobuf_oneof:"EventData"`
 	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
 	XXX_unrecognized     []byte            `json:"-"`
@@ -103,10 +105,22 @@ type Event_Result struct {
 	Result *ScanResult `protobuf:"bytes,8,opt,name=result,proto3,oneof"`
 }
 
+type Event_Skipped struct {
+	Skipped *SkippedTarget `protobuf:"bytes,9,opt,name=skipped,proto3,oneof"`
+}
+
+type Event_Host struct {
+	Host *HostDiscoveryResult `protobuf:"bytes,10,opt,name=host,proto3,oneof"`
+}
+
 func (*Event_Environment) isEvent_EventData() {}
 
 func (*Event_Result) isEvent_EventData() {}
 
+func (*Event_Skipped) isEvent_EventData() {}
+
+func (*Event_Host) isEvent_EventData() {}
+
 func (m *Event) GetEventData() isEvent_EventData {
 	if m != nil {
 		return m.EventData
@@ -128,24 +142,46 @@ func (m *Event) GetResult() *ScanResult {
 	return nil
 }
 
+func (m *Event) GetSkipped() *SkippedTarget {
+	if x, ok := m.GetEventData().(*Event_Skipped); ok {
+		return x.Skipped
+	}
+	return nil
+}
+
+func (m *Event) GetHost() *HostDiscoveryResult {
+	if x, ok := m.GetEventData().(*Event_Host); ok {
+		return x.Host
+	}
+	return nil
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*Event) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
 		(*Event_Environment)(nil),
 		(*Event_Result)(nil),
+		(*Event_Skipped)(nil),
+		(*Event_Host)(nil),
 	}
 }
 
 type ScanResult struct {
-	Target string `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`
-	Port   uint32 `protobuf:"varint,5,opt,name=port,proto3" json:"port,omitempty"`
+	Target   string `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`
+	Port     uint32 `protobuf:"varint,5,opt,name=port,proto3" json:"port,omitempty"`
+	Hostname string `protobuf:"bytes,6,opt,name=hostname,proto3" json:"hostname,omitempty"`
 	// Types that are valid to be assigned to Result:
 	//	*ScanResult_Portscan
 	//	*ScanResult_Zgrabscan
-	Result               isScanResult_Result `protobuf_oneof:"result"`
-	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
-	XXX_unrecognized     []byte              `json:"-"`
-	XXX_sizecache        int32               `json:"-"`
+	//	*ScanResult_Udpservice
+	//	*ScanResult_Service
+	//	*ScanResult_Ssh
+	Result isScanResult_Result `protobuf_oneof:"result"`
+	// Added by the server if enrichment is enabled
+	Enrichment           *Enrichment `protobuf:"bytes,11,opt,name=enrichment,proto3" json:"enrichment,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
 }
 
 func (m *ScanResult) Reset()         { *m = ScanResult{} }
@@ -187,6 +223,13 @@ func (m *ScanResult) GetPort() uint32 {
 	return 0
 }
 
+func (m *ScanResult) GetHostname() string {
+	if m != nil {
+		return m.Hostname
+	}
+	return ""
+}
+
 type isScanResult_Result interface {
 	isScanResult_Result()
 }
@@ -199,10 +242,28 @@ type ScanResult_Zgrabscan struct {
 	Zgrabscan *ZGrab2ScanResult `protobuf:"bytes,9,opt,name=zgrabscan,proto3,oneof"`
 }
 
+type ScanResult_Udpservice struct {
+	Udpservice *UDPServiceResult `protobuf:"bytes,10,opt,name=udpservice,proto3,oneof"`
+}
+
+type ScanResult_Service struct {
+	Service *ServiceDetectionResult `protobuf:"bytes,12,opt,name=service,proto3,oneof"`
+}
+
+type ScanResult_Ssh struct {
+	Ssh *SSHScanResult `protobuf:"bytes,13,opt,name=ssh,proto3,oneof"`
+}
+
 func (*ScanResult_Portscan) isScanResult_Result() {}
 
 func (*ScanResult_Zgrabscan) isScanResult_Result() {}
 
+func (*ScanResult_Udpservice) isScanResult_Result() {}
+
+func (*ScanResult_Service) isScanResult_Result() {}
+
+func (*ScanResult_Ssh) isScanResult_Result() {}
+
 func (m *ScanResult) GetResult() isScanResult_Result {
 	if m != nil {
 		return m.Result
@@ -224,16 +285,130 @@ func (m *ScanResult) GetZgrabscan() *ZGrab2ScanResult {
 	return nil
 }
 
+func (m *ScanResult) GetUdpservice() *UDPServiceResult {
+	if x, ok := m.GetResult().(*ScanResult_Udpservice); ok {
+		return x.Udpservice
+	}
+	return nil
+}
+
+func (m *ScanResult) GetService() *ServiceDetectionResult {
+	if x, ok := m.GetResult().(*ScanResult_Service); ok {
+		return x.Service
+	}
+	return nil
+}
+
+func (m *ScanResult) GetSsh() *SSHScanResult {
+	if x, ok := m.GetResult().(*ScanResult_Ssh); ok {
+		return x.Ssh
+	}
+	return nil
+}
+
+func (m *ScanResult) GetEnrichment() *Enrichment {
+	if m != nil {
+		return m.Enrichment
+	}
+	return nil
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*ScanResult) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
 		(*ScanResult_Portscan)(nil),
 		(*ScanResult_Zgrabscan)(nil),
+		(*ScanResult_Udpservice)(nil),
+		(*ScanResult_Service)(nil),
+		(*ScanResult_Ssh)(nil),
+	}
+}
+
+// Enrichment is what the server knows about the
+// IP address of a result from DNS and MMDB files
+type Enrichment struct {
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// Names from PTR records
+	Ptr []string `protobuf:"bytes,2,rep,name=ptr,proto3" json:"ptr,omitempty"`
+	// ISO 3166 country code
+	Country              string   `protobuf:"bytes,3,opt,name=country,proto3" json:"country,omitempty"`
+	CountryName          string   `protobuf:"bytes,4,opt,name=countryName,proto3" json:"countryName,omitempty"`
+	Asn                  uint32   `protobuf:"varint,5,opt,name=asn,proto3" json:"asn,omitempty"`
+	Organization         string   `protobuf:"bytes,6,opt,name=organization,proto3" json:"organization,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Enrichment) Reset()         { *m = Enrichment{} }
+func (m *Enrichment) String() string { return proto.CompactTextString(m) }
+func (*Enrichment) ProtoMessage()    {}
+func (*Enrichment) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ab30010df94cd8f, []int{2}
+}
+
+func (m *Enrichment) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Enrichment.Unmarshal(m, b)
+}
+func (m *Enrichment) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Enrichment.Marshal(b, m, deterministic)
+}
+func (m *Enrichment) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Enrichment.Merge(m, src)
+}
+func (m *Enrichment) XXX_Size() int {
+	return xxx_messageInfo_Enrichment.Size(m)
+}
+func (m *Enrichment) XXX_DiscardUnknown() {
+	xxx_messageInfo_Enrichment.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Enrichment proto.InternalMessageInfo
+
+func (m *Enrichment) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *Enrichment) GetPtr() []string {
+	if m != nil {
+		return m.Ptr
+	}
+	return nil
+}
+
+func (m *Enrichment) GetCountry() string {
+	if m != nil {
+		return m.Country
+	}
+	return ""
+}
+
+func (m *Enrichment) GetCountryName() string {
+	if m != nil {
+		return m.CountryName
 	}
+	return ""
+}
+
+func (m *Enrichment) GetAsn() uint32 {
+	if m != nil {
+		return m.Asn
+	}
+	return 0
+}
+
+func (m *Enrichment) GetOrganization() string {
+	if m != nil {
+		return m.Organization
+	}
+	return ""
 }
 
 // EnvironmentInformation tells the server
-//under which circumstances nodes are running
+// under which circumstances nodes are running
 type EnvironmentInformation struct {
 	Hostname             string   `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
 	Os                   string   `protobuf:"bytes,2,opt,name=os,proto3" json:"os,omitempty"`
@@ -250,7 +425,7 @@ func (m *EnvironmentInformation) Reset()         { *m = EnvironmentInformation{}
 func (m *EnvironmentInformation) String() string { return proto.CompactTextString(m) }
 func (*EnvironmentInformation) ProtoMessage()    {}
 func (*EnvironmentInformation) Descriptor() ([]byte, []int) {
-	return fileDescriptor_3ab30010df94cd8f, []int{2}
+	return fileDescriptor_3ab30010df94cd8f, []int{3}
 }
 
 func (m *EnvironmentInformation) XXX_Unmarshal(b []byte) error {
@@ -314,13 +489,23 @@ func (m *EnvironmentInformation) GetCpumodelname() string {
 }
 
 // TCPScanResult contains the outcome of
-//a TCP scan against a single port on a single host
+// a TCP scan against a single port on a single host
 type PortScanResult struct {
-	Target               string   `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
-	Port                 uint32   `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
-	Open                 bool     `protobuf:"varint,3,opt,name=open,proto3" json:"open,omitempty"`
-	Scantype             string   `protobuf:"bytes,4,opt,name=scantype,proto3" json:"scantype,omitempty"`
-	Timeout              uint32   `protobuf:"varint,5,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Target   string `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	Port     uint32 `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	Open     bool   `protobuf:"varint,3,opt,name=open,proto3" json:"open,omitempty"`
+	Scantype string `protobuf:"bytes,4,opt,name=scantype,proto3" json:"scantype,omitempty"`
+	Timeout  uint32 `protobuf:"varint,5,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	// open or closed, UDP ports are closed if the target
+	//answered with ICMP port unreachable
+	State       string       `protobuf:"bytes,6,opt,name=state,proto3" json:"state,omitempty"`
+	Udpresponse *UDPResponse `protobuf:"bytes,7,opt,name=udpresponse,proto3" json:"udpresponse,omitempty"`
+	// Local address the scan was sent from, if known
+	Source string `protobuf:"bytes,8,opt,name=source,proto3" json:"source,omitempty"`
+	// Proxy the scan was sent through, without credentials
+	Proxy string `protobuf:"bytes,9,opt,name=proxy,proto3" json:"proxy,omitempty"`
+	// IP address the target was scanned on. Names are resolved to it
+	Address              string   `protobuf:"bytes,10,opt,name=address,proto3" json:"address,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -330,7 +515,7 @@ func (m *PortScanResult) Reset()         { *m = PortScanResult{} }
 func (m *PortScanResult) String() string { return proto.CompactTextString(m) }
 func (*PortScanResult) ProtoMessage()    {}
 func (*PortScanResult) Descriptor() ([]byte, []int) {
-	return fileDescriptor_3ab30010df94cd8f, []int{3}
+	return fileDescriptor_3ab30010df94cd8f, []int{4}
 }
 
 func (m *PortScanResult) XXX_Unmarshal(b []byte) error {
@@ -386,18 +571,1363 @@ func (m *PortScanResult) GetTimeout() uint32 {
 	return 0
 }
 
-type ZGrab2ScanResult struct {
-	JsonResult           *_struct.Value `protobuf:"bytes,1,opt,name=jsonResult,proto3" json:"jsonResult,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
-	XXX_unrecognized     []byte         `json:"-"`
-	XXX_sizecache        int32          `json:"-"`
+func (m *PortScanResult) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
 }
 
-func (m *ZGrab2ScanResult) Reset()         { *m = ZGrab2ScanResult{} }
-func (m *ZGrab2ScanResult) String() string { return proto.CompactTextString(m) }
-func (*ZGrab2ScanResult) ProtoMessage()    {}
-func (*ZGrab2ScanResult) Descriptor() ([]byte, []int) {
-	return fileDescriptor_3ab30010df94cd8f, []int{4}
+func (m *PortScanResult) GetUdpresponse() *UDPResponse {
+	if m != nil {
+		return m.Udpresponse
+	}
+	return nil
+}
+
+func (m *PortScanResult) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *PortScanResult) GetProxy() string {
+	if m != nil {
+		return m.Proxy
+	}
+	return ""
+}
+
+func (m *PortScanResult) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+// UDPResponse is the first response a target sent
+// to a UDP probe
+type UDPResponse struct {
+	Length               uint32   `protobuf:"varint,1,opt,name=length,proto3" json:"length,omitempty"`
+	Data                 []byte   `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Truncated            bool     `protobuf:"varint,3,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	Probes               uint32   `protobuf:"varint,4,opt,name=probes,proto3" json:"probes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UDPResponse) Reset()         { *m = UDPResponse{} }
+func (m *UDPResponse) String() string { return proto.CompactTextString(m) }
+func (*UDPResponse) ProtoMessage()    {}
+func (*UDPResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ab30010df94cd8f, []int{5}
+}
+
+func (m *UDPResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UDPResponse.Unmarshal(m, b)
+}
+func (m *UDPResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UDPResponse.Marshal(b, m, deterministic)
+}
+func (m *UDPResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UDPResponse.Merge(m, src)
+}
+func (m *UDPResponse) XXX_Size() int {
+	return xxx_messageInfo_UDPResponse.Size(m)
+}
+func (m *UDPResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_UDPResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UDPResponse proto.InternalMessageInfo
+
+func (m *UDPResponse) GetLength() uint32 {
+	if m != nil {
+		return m.Length
+	}
+	return 0
+}
+
+func (m *UDPResponse) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *UDPResponse) GetTruncated() bool {
+	if m != nil {
+		return m.Truncated
+	}
+	return false
+}
+
+func (m *UDPResponse) GetProbes() uint32 {
+	if m != nil {
+		return m.Probes
+	}
+	return 0
+}
+
+// HostDiscoveryResult tells if a host answered any probe of
+// the discovery stage. Ports of hosts that are down are not scanned
+type HostDiscoveryResult struct {
+	Target   string `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	Hostname string `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Up       bool   `protobuf:"varint,3,opt,name=up,proto3" json:"up,omitempty"`
+	// The probe the host answered, e.g. icmp-echo or tcp/443
+	Method string `protobuf:"bytes,4,opt,name=method,proto3" json:"method,omitempty"`
+	// Round trip time in milliseconds
+	Rtt uint32 `protobuf:"varint,5,opt,name=rtt,proto3" json:"rtt,omitempty"`
+	// IP address the target was probed on. Names are resolved to it
+	Address              string   `protobuf:"bytes,6,opt,name=address,proto3" json:"address,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HostDiscoveryResult) Reset()         { *m = HostDiscoveryResult{} }
+func (m *HostDiscoveryResult) String() string { return proto.CompactTextString(m) }
+func (*HostDiscoveryResult) ProtoMessage()    {}
+func (*HostDiscoveryResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ab30010df94cd8f, []int{6}
+}
+
+func (m *HostDiscoveryResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HostDiscoveryResult.Unmarshal(m, b)
+}
+func (m *HostDiscoveryResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HostDiscoveryResult.Marshal(b, m, deterministic)
+}
+func (m *HostDiscoveryResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HostDiscoveryResult.Merge(m, src)
+}
+func (m *HostDiscoveryResult) XXX_Size() int {
+	return xxx_messageInfo_HostDiscoveryResult.Size(m)
+}
+func (m *HostDiscoveryResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_HostDiscoveryResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HostDiscoveryResult proto.InternalMessageInfo
+
+func (m *HostDiscoveryResult) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
+func (m *HostDiscoveryResult) GetHostname() string {
+	if m != nil {
+		return m.Hostname
+	}
+	return ""
+}
+
+func (m *HostDiscoveryResult) GetUp() bool {
+	if m != nil {
+		return m.Up
+	}
+	return false
+}
+
+func (m *HostDiscoveryResult) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *HostDiscoveryResult) GetRtt() uint32 {
+	if m != nil {
+		return m.Rtt
+	}
+	return 0
+}
+
+func (m *HostDiscoveryResult) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+// SkippedTarget is reported if a node refuses to scan a
+// target because it is outside of the node's own scope
+type SkippedTarget struct {
+	Target   string   `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	Hostname string   `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Reason   string   `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	Tcpports []uint32 `protobuf:"varint,4,rep,packed,name=tcpports,proto3" json:"tcpports,omitempty"`
+	Udpports []uint32 `protobuf:"varint,5,rep,packed,name=udpports,proto3" json:"udpports,omitempty"`
+	// True if the whole batch was not scanned because the node runs in strict mode
+	BatchAborted         bool     `protobuf:"varint,6,opt,name=batchAborted,proto3" json:"batchAborted,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SkippedTarget) Reset()         { *m = SkippedTarget{} }
+func (m *SkippedTarget) String() string { return proto.CompactTextString(m) }
+func (*SkippedTarget) ProtoMessage()    {}
+func (*SkippedTarget) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ab30010df94cd8f, []int{7}
+}
+
+func (m *SkippedTarget) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SkippedTarget.Unmarshal(m, b)
+}
+func (m *SkippedTarget) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SkippedTarget.Marshal(b, m, deterministic)
+}
+func (m *SkippedTarget) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SkippedTarget.Merge(m, src)
+}
+func (m *SkippedTarget) XXX_Size() int {
+	return xxx_messageInfo_SkippedTarget.Size(m)
+}
+func (m *SkippedTarget) XXX_DiscardUnknown() {
+	xxx_messageInfo_SkippedTarget.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SkippedTarget proto.InternalMessageInfo
+
+func (m *SkippedTarget) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
+func (m *SkippedTarget) GetHostname() string {
+	if m != nil {
+		return m.Hostname
+	}
+	return ""
+}
+
+func (m *SkippedTarget) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *SkippedTarget) GetTcpports() []uint32 {
+	if m != nil {
+		return m.Tcpports
+	}
+	return nil
+}
+
+func (m *SkippedTarget) GetUdpports() []uint32 {
+	if m != nil {
+		return m.Udpports
+	}
+	return nil
+}
+
+func (m *SkippedTarget) GetBatchAborted() bool {
+	if m != nil {
+		return m.BatchAborted
+	}
+	return false
+}
+
+// ServiceDetectionResult is the service identified on an open port
+// by probes and matches in nmap-service-probes format
+type ServiceDetectionResult struct {
+	// tcp or udp
+	Protocol   string   `protobuf:"bytes,1,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Service    string   `protobuf:"bytes,2,opt,name=service,proto3" json:"service,omitempty"`
+	Product    string   `protobuf:"bytes,3,opt,name=product,proto3" json:"product,omitempty"`
+	Version    string   `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	Info       string   `protobuf:"bytes,5,opt,name=info,proto3" json:"info,omitempty"`
+	Hostname   string   `protobuf:"bytes,6,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Os         string   `protobuf:"bytes,7,opt,name=os,proto3" json:"os,omitempty"`
+	Devicetype string   `protobuf:"bytes,8,opt,name=devicetype,proto3" json:"devicetype,omitempty"`
+	Cpe        []string `protobuf:"bytes,9,rep,name=cpe,proto3" json:"cpe,omitempty"`
+	// The probe the matching response was sent to
+	Probe string `protobuf:"bytes,10,opt,name=probe,proto3" json:"probe,omitempty"`
+	// Softmatches only identify the service, not its version
+	Softmatch            bool     `protobuf:"varint,11,opt,name=softmatch,proto3" json:"softmatch,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ServiceDetectionResult) Reset()         { *m = ServiceDetectionResult{} }
+func (m *ServiceDetectionResult) String() string { return proto.CompactTextString(m) }
+func (*ServiceDetectionResult) ProtoMessage()    {}
+func (*ServiceDetectionResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ab30010df94cd8f, []int{8}
+}
+
+func (m *ServiceDetectionResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServiceDetectionResult.Unmarshal(m, b)
+}
+func (m *ServiceDetectionResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServiceDetectionResult.Marshal(b, m, deterministic)
+}
+func (m *ServiceDetectionResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServiceDetectionResult.Merge(m, src)
+}
+func (m *ServiceDetectionResult) XXX_Size() int {
+	return xxx_messageInfo_ServiceDetectionResult.Size(m)
+}
+func (m *ServiceDetectionResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServiceDetectionResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServiceDetectionResult proto.InternalMessageInfo
+
+func (m *ServiceDetectionResult) GetProtocol() string {
+	if m != nil {
+		return m.Protocol
+	}
+	return ""
+}
+
+func (m *ServiceDetectionResult) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *ServiceDetectionResult) GetProduct() string {
+	if m != nil {
+		return m.Product
+	}
+	return ""
+}
+
+func (m *ServiceDetectionResult) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *ServiceDetectionResult) GetInfo() string {
+	if m != nil {
+		return m.Info
+	}
+	return ""
+}
+
+func (m *ServiceDetectionResult) GetHostname() string {
+	if m != nil {
+		return m.Hostname
+	}
+	return ""
+}
+
+func (m *ServiceDetectionResult) GetOs() string {
+	if m != nil {
+		return m.Os
+	}
+	return ""
+}
+
+func (m *ServiceDetectionResult) GetDevicetype() string {
+	if m != nil {
+		return m.Devicetype
+	}
+	return ""
+}
+
+func (m *ServiceDetectionResult) GetCpe() []string {
+	if m != nil {
+		return m.Cpe
+	}
+	return nil
+}
+
+func (m *ServiceDetectionResult) GetProbe() string {
+	if m != nil {
+		return m.Probe
+	}
+	return ""
+}
+
+func (m *ServiceDetectionResult) GetSoftmatch() bool {
+	if m != nil {
+		return m.Softmatch
+	}
+	return false
+}
+
+// SSHScanResult is what an SSH server reveals
+// before authentication
+type SSHScanResult struct {
+	// Identification string, e.g. SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13.5
+	Banner          string `protobuf:"bytes,1,opt,name=banner,proto3" json:"banner,omitempty"`
+	Protoversion    string `protobuf:"bytes,2,opt,name=protoversion,proto3" json:"protoversion,omitempty"`
+	Softwareversion string `protobuf:"bytes,3,opt,name=softwareversion,proto3" json:"softwareversion,omitempty"`
+	Comments        string `protobuf:"bytes,4,opt,name=comments,proto3" json:"comments,omitempty"`
+	// Algorithms offered in the server's KEXINIT
+	KexAlgorithms           []string `protobuf:"bytes,5,rep,name=kexAlgorithms,proto3" json:"kexAlgorithms,omitempty"`
+	HostKeyAlgorithms       []string `protobuf:"bytes,6,rep,name=hostKeyAlgorithms,proto3" json:"hostKeyAlgorithms,omitempty"`
+	CiphersClientServer     []string `protobuf:"bytes,7,rep,name=ciphersClientServer,proto3" json:"ciphersClientServer,omitempty"`
+	CiphersServerClient     []string `protobuf:"bytes,8,rep,name=ciphersServerClient,proto3" json:"ciphersServerClient,omitempty"`
+	MacsClientServer        []string `protobuf:"bytes,9,rep,name=macsClientServer,proto3" json:"macsClientServer,omitempty"`
+	MacsServerClient        []string `protobuf:"bytes,10,rep,name=macsServerClient,proto3" json:"macsServerClient,omitempty"`
+	CompressionClientServer []string `protobuf:"bytes,11,rep,name=compressionClientServer,proto3" json:"compressionClientServer,omitempty"`
+	CompressionServerClient []string `protobuf:"bytes,12,rep,name=compressionServerClient,proto3" json:"compressionServerClient,omitempty"`
+	// One key per type the server offers
+	HostKeys []*SSHHostKey `protobuf:"bytes,13,rep,name=hostKeys,proto3" json:"hostKeys,omitempty"`
+	// Offered algorithms that are considered weak
+	WeakAlgorithms       []string `protobuf:"bytes,14,rep,name=weakAlgorithms,proto3" json:"weakAlgorithms,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SSHScanResult) Reset()         { *m = SSHScanResult{} }
+func (m *SSHScanResult) String() string { return proto.CompactTextString(m) }
+func (*SSHScanResult) ProtoMessage()    {}
+func (*SSHScanResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ab30010df94cd8f, []int{9}
+}
+
+func (m *SSHScanResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SSHScanResult.Unmarshal(m, b)
+}
+func (m *SSHScanResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SSHScanResult.Marshal(b, m, deterministic)
+}
+func (m *SSHScanResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SSHScanResult.Merge(m, src)
+}
+func (m *SSHScanResult) XXX_Size() int {
+	return xxx_messageInfo_SSHScanResult.Size(m)
+}
+func (m *SSHScanResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_SSHScanResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SSHScanResult proto.InternalMessageInfo
+
+func (m *SSHScanResult) GetBanner() string {
+	if m != nil {
+		return m.Banner
+	}
+	return ""
+}
+
+func (m *SSHScanResult) GetProtoversion() string {
+	if m != nil {
+		return m.Protoversion
+	}
+	return ""
+}
+
+func (m *SSHScanResult) GetSoftwareversion() string {
+	if m != nil {
+		return m.Softwareversion
+	}
+	return ""
+}
+
+func (m *SSHScanResult) GetComments() string {
+	if m != nil {
+		return m.Comments
+	}
+	return ""
+}
+
+func (m *SSHScanResult) GetKexAlgorithms() []string {
+	if m != nil {
+		return m.KexAlgorithms
+	}
+	return nil
+}
+
+func (m *SSHScanResult) GetHostKeyAlgorithms() []string {
+	if m != nil {
+		return m.HostKeyAlgorithms
+	}
+	return nil
+}
+
+func (m *SSHScanResult) GetCiphersClientServer() []string {
+	if m != nil {
+		return m.CiphersClientServer
+	}
+	return nil
+}
+
+func (m *SSHScanResult) GetCiphersServerClient() []string {
+	if m != nil {
+		return m.CiphersServerClient
+	}
+	return nil
+}
+
+func (m *SSHScanResult) GetMacsClientServer() []string {
+	if m != nil {
+		return m.MacsClientServer
+	}
+	return nil
+}
+
+func (m *SSHScanResult) GetMacsServerClient() []string {
+	if m != nil {
+		return m.MacsServerClient
+	}
+	return nil
+}
+
+func (m *SSHScanResult) GetCompressionClientServer() []string {
+	if m != nil {
+		return m.CompressionClientServer
+	}
+	return nil
+}
+
+func (m *SSHScanResult) GetCompressionServerClient() []string {
+	if m != nil {
+		return m.CompressionServerClient
+	}
+	return nil
+}
+
+func (m *SSHScanResult) GetHostKeys() []*SSHHostKey {
+	if m != nil {
+		return m.HostKeys
+	}
+	return nil
+}
+
+func (m *SSHScanResult) GetWeakAlgorithms() []string {
+	if m != nil {
+		return m.WeakAlgorithms
+	}
+	return nil
+}
+
+type SSHHostKey struct {
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Bits uint32 `protobuf:"varint,2,opt,name=bits,proto3" json:"bits,omitempty"`
+	// Fingerprints as shown by ssh-keygen -l
+	Sha256 string `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	Md5    string `protobuf:"bytes,4,opt,name=md5,proto3" json:"md5,omitempty"`
+	// Base64 encoded like in known_hosts
+	PublicKey            string   `protobuf:"bytes,5,opt,name=publicKey,proto3" json:"publicKey,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SSHHostKey) Reset()         { *m = SSHHostKey{} }
+func (m *SSHHostKey) String() string { return proto.CompactTextString(m) }
+func (*SSHHostKey) ProtoMessage()    {}
+func (*SSHHostKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ab30010df94cd8f, []int{10}
+}
+
+func (m *SSHHostKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SSHHostKey.Unmarshal(m, b)
+}
+func (m *SSHHostKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SSHHostKey.Marshal(b, m, deterministic)
+}
+func (m *SSHHostKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SSHHostKey.Merge(m, src)
+}
+func (m *SSHHostKey) XXX_Size() int {
+	return xxx_messageInfo_SSHHostKey.Size(m)
+}
+func (m *SSHHostKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_SSHHostKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SSHHostKey proto.InternalMessageInfo
+
+func (m *SSHHostKey) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *SSHHostKey) GetBits() uint32 {
+	if m != nil {
+		return m.Bits
+	}
+	return 0
+}
+
+func (m *SSHHostKey) GetSha256() string {
+	if m != nil {
+		return m.Sha256
+	}
+	return ""
+}
+
+func (m *SSHHostKey) GetMd5() string {
+	if m != nil {
+		return m.Md5
+	}
+	return ""
+}
+
+func (m *SSHHostKey) GetPublicKey() string {
+	if m != nil {
+		return m.PublicKey
+	}
+	return ""
+}
+
+// UDPServiceResult contains what was decoded from the
+// response of a UDP service to one of the built-in probes
+type UDPServiceResult struct {
+	Service string `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	// Types that are valid to be assigned to Info:
+	//	*UDPServiceResult_Dns
+	//	*UDPServiceResult_Netbios
+	//	*UDPServiceResult_Ntp
+	//	*UDPServiceResult_Snmp
+	//	*UDPServiceResult_Portmap
+	//	*UDPServiceResult_Mssql
+	//	*UDPServiceResult_Citrix
+	Info                 isUDPServiceResult_Info `protobuf_oneof:"info"`
+	XXX_NoUnkeyedLiteral struct{}                `json:"-"`
+	XXX_unrecognized     []byte                  `json:"-"`
+	XXX_sizecache        int32                   `json:"-"`
+}
+
+func (m *UDPServiceResult) Reset()         { *m = UDPServiceResult{} }
+func (m *UDPServiceResult) String() string { return proto.CompactTextString(m) }
+func (*UDPServiceResult) ProtoMessage()    {}
+func (*UDPServiceResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ab30010df94cd8f, []int{11}
+}
+
+func (m *UDPServiceResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UDPServiceResult.Unmarshal(m, b)
+}
+func (m *UDPServiceResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UDPServiceResult.Marshal(b, m, deterministic)
+}
+func (m *UDPServiceResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UDPServiceResult.Merge(m, src)
+}
+func (m *UDPServiceResult) XXX_Size() int {
+	return xxx_messageInfo_UDPServiceResult.Size(m)
+}
+func (m *UDPServiceResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_UDPServiceResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UDPServiceResult proto.InternalMessageInfo
+
+func (m *UDPServiceResult) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+type isUDPServiceResult_Info interface {
+	isUDPServiceResult_Info()
+}
+
+type UDPServiceResult_Dns struct {
+	Dns *DNSVersionInfo `protobuf:"bytes,2,opt,name=dns,proto3,oneof"`
+}
+
+type UDPServiceResult_Netbios struct {
+	Netbios *NetBIOSInfo `protobuf:"bytes,3,opt,name=netbios,proto3,oneof"`
+}
+
+type UDPServiceResult_Ntp struct {
+	Ntp *NTPInfo `protobuf:"bytes,4,opt,name=ntp,proto3,oneof"`
+}
+
+type UDPServiceResult_Snmp struct {
+	Snmp *SNMPInfo `protobuf:"bytes,5,opt,name=snmp,proto3,oneof"`
+}
+
+type UDPServiceResult_Portmap struct {
+	Portmap *PortmapInfo `protobuf:"bytes,6,opt,name=portmap,proto3,oneof"`
+}
+
+type UDPServiceResult_Mssql struct {
+	Mssql *MSSQLBrowserInfo `protobuf:"bytes,7,opt,name=mssql,proto3,oneof"`
+}
+
+type UDPServiceResult_Citrix struct {
+	Citrix *CitrixInfo `protobuf:"bytes,8,opt,name=citrix,proto3,oneof"`
+}
+
+func (*UDPServiceResult_Dns) isUDPServiceResult_Info() {}
+
+func (*UDPServiceResult_Netbios) isUDPServiceResult_Info() {}
+
+func (*UDPServiceResult_Ntp) isUDPServiceResult_Info() {}
+
+func (*UDPServiceResult_Snmp) isUDPServiceResult_Info() {}
+
+func (*UDPServiceResult_Portmap) isUDPServiceResult_Info() {}
+
+func (*UDPServiceResult_Mssql) isUDPServiceResult_Info() {}
+
+func (*UDPServiceResult_Citrix) isUDPServiceResult_Info() {}
+
+func (m *UDPServiceResult) GetInfo() isUDPServiceResult_Info {
+	if m != nil {
+		return m.Info
+	}
+	return nil
+}
+
+func (m *UDPServiceResult) GetDns() *DNSVersionInfo {
+	if x, ok := m.GetInfo().(*UDPServiceResult_Dns); ok {
+		return x.Dns
+	}
+	return nil
+}
+
+func (m *UDPServiceResult) GetNetbios() *NetBIOSInfo {
+	if x, ok := m.GetInfo().(*UDPServiceResult_Netbios); ok {
+		return x.Netbios
+	}
+	return nil
+}
+
+func (m *UDPServiceResult) GetNtp() *NTPInfo {
+	if x, ok := m.GetInfo().(*UDPServiceResult_Ntp); ok {
+		return x.Ntp
+	}
+	return nil
+}
+
+func (m *UDPServiceResult) GetSnmp() *SNMPInfo {
+	if x, ok := m.GetInfo().(*UDPServiceResult_Snmp); ok {
+		return x.Snmp
+	}
+	return nil
+}
+
+func (m *UDPServiceResult) GetPortmap() *PortmapInfo {
+	if x, ok := m.GetInfo().(*UDPServiceResult_Portmap); ok {
+		return x.Portmap
+	}
+	return nil
+}
+
+func (m *UDPServiceResult) GetMssql() *MSSQLBrowserInfo {
+	if x, ok := m.GetInfo().(*UDPServiceResult_Mssql); ok {
+		return x.Mssql
+	}
+	return nil
+}
+
+func (m *UDPServiceResult) GetCitrix() *CitrixInfo {
+	if x, ok := m.GetInfo().(*UDPServiceResult_Citrix); ok {
+		return x.Citrix
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*UDPServiceResult) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*UDPServiceResult_Dns)(nil),
+		(*UDPServiceResult_Netbios)(nil),
+		(*UDPServiceResult_Ntp)(nil),
+		(*UDPServiceResult_Snmp)(nil),
+		(*UDPServiceResult_Portmap)(nil),
+		(*UDPServiceResult_Mssql)(nil),
+		(*UDPServiceResult_Citrix)(nil),
+	}
+}
+
+type DNSVersionInfo struct {
+	Rcode                string   `protobuf:"bytes,1,opt,name=rcode,proto3" json:"rcode,omitempty"`
+	Version              string   `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DNSVersionInfo) Reset()         { *m = DNSVersionInfo{} }
+func (m *DNSVersionInfo) String() string { return proto.CompactTextString(m) }
+func (*DNSVersionInfo) ProtoMessage()    {}
+func (*DNSVersionInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ab30010df94cd8f, []int{12}
+}
+
+func (m *DNSVersionInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DNSVersionInfo.Unmarshal(m, b)
+}
+func (m *DNSVersionInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DNSVersionInfo.Marshal(b, m, deterministic)
+}
+func (m *DNSVersionInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DNSVersionInfo.Merge(m, src)
+}
+func (m *DNSVersionInfo) XXX_Size() int {
+	return xxx_messageInfo_DNSVersionInfo.Size(m)
+}
+func (m *DNSVersionInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_DNSVersionInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DNSVersionInfo proto.InternalMessageInfo
+
+func (m *DNSVersionInfo) GetRcode() string {
+	if m != nil {
+		return m.Rcode
+	}
+	return ""
+}
+
+func (m *DNSVersionInfo) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+type NetBIOSName struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Suffix               uint32   `protobuf:"varint,2,opt,name=suffix,proto3" json:"suffix,omitempty"`
+	Group                bool     `protobuf:"varint,3,opt,name=group,proto3" json:"group,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NetBIOSName) Reset()         { *m = NetBIOSName{} }
+func (m *NetBIOSName) String() string { return proto.CompactTextString(m) }
+func (*NetBIOSName) ProtoMessage()    {}
+func (*NetBIOSName) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ab30010df94cd8f, []int{13}
+}
+
+func (m *NetBIOSName) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NetBIOSName.Unmarshal(m, b)
+}
+func (m *NetBIOSName) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NetBIOSName.Marshal(b, m, deterministic)
+}
+func (m *NetBIOSName) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NetBIOSName.Merge(m, src)
+}
+func (m *NetBIOSName) XXX_Size() int {
+	return xxx_messageInfo_NetBIOSName.Size(m)
+}
+func (m *NetBIOSName) XXX_DiscardUnknown() {
+	xxx_messageInfo_NetBIOSName.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NetBIOSName proto.InternalMessageInfo
+
+func (m *NetBIOSName) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *NetBIOSName) GetSuffix() uint32 {
+	if m != nil {
+		return m.Suffix
+	}
+	return 0
+}
+
+func (m *NetBIOSName) GetGroup() bool {
+	if m != nil {
+		return m.Group
+	}
+	return false
+}
+
+type NetBIOSInfo struct {
+	ComputerName         string         `protobuf:"bytes,1,opt,name=computerName,proto3" json:"computerName,omitempty"`
+	Domain               string         `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	User                 string         `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+	Mac                  string         `protobuf:"bytes,4,opt,name=mac,proto3" json:"mac,omitempty"`
+	Names                []*NetBIOSName `protobuf:"bytes,5,rep,name=names,proto3" json:"names,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *NetBIOSInfo) Reset()         { *m = NetBIOSInfo{} }
+func (m *NetBIOSInfo) String() string { return proto.CompactTextString(m) }
+func (*NetBIOSInfo) ProtoMessage()    {}
+func (*NetBIOSInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ab30010df94cd8f, []int{14}
+}
+
+func (m *NetBIOSInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NetBIOSInfo.Unmarshal(m, b)
+}
+func (m *NetBIOSInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NetBIOSInfo.Marshal(b, m, deterministic)
+}
+func (m *NetBIOSInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NetBIOSInfo.Merge(m, src)
+}
+func (m *NetBIOSInfo) XXX_Size() int {
+	return xxx_messageInfo_NetBIOSInfo.Size(m)
+}
+func (m *NetBIOSInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_NetBIOSInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NetBIOSInfo proto.InternalMessageInfo
+
+func (m *NetBIOSInfo) GetComputerName() string {
+	if m != nil {
+		return m.ComputerName
+	}
+	return ""
+}
+
+func (m *NetBIOSInfo) GetDomain() string {
+	if m != nil {
+		return m.Domain
+	}
+	return ""
+}
+
+func (m *NetBIOSInfo) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *NetBIOSInfo) GetMac() string {
+	if m != nil {
+		return m.Mac
+	}
+	return ""
+}
+
+func (m *NetBIOSInfo) GetNames() []*NetBIOSName {
+	if m != nil {
+		return m.Names
+	}
+	return nil
+}
+
+type NTPInfo struct {
+	Version              uint32               `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Mode                 uint32               `protobuf:"varint,2,opt,name=mode,proto3" json:"mode,omitempty"`
+	Stratum              uint32               `protobuf:"varint,3,opt,name=stratum,proto3" json:"stratum,omitempty"`
+	ReferenceID          string               `protobuf:"bytes,4,opt,name=referenceID,proto3" json:"referenceID,omitempty"`
+	TransmitTime         *timestamp.Timestamp `protobuf:"bytes,5,opt,name=transmitTime,proto3" json:"transmitTime,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *NTPInfo) Reset()         { *m = NTPInfo{} }
+func (m *NTPInfo) String() string { return proto.CompactTextString(m) }
+func (*NTPInfo) ProtoMessage()    {}
+func (*NTPInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ab30010df94cd8f, []int{15}
+}
+
+func (m *NTPInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NTPInfo.Unmarshal(m, b)
+}
+func (m *NTPInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NTPInfo.Marshal(b, m, deterministic)
+}
+func (m *NTPInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NTPInfo.Merge(m, src)
+}
+func (m *NTPInfo) XXX_Size() int {
+	return xxx_messageInfo_NTPInfo.Size(m)
+}
+func (m *NTPInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_NTPInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NTPInfo proto.InternalMessageInfo
+
+func (m *NTPInfo) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *NTPInfo) GetMode() uint32 {
+	if m != nil {
+		return m.Mode
+	}
+	return 0
+}
+
+func (m *NTPInfo) GetStratum() uint32 {
+	if m != nil {
+		return m.Stratum
+	}
+	return 0
+}
+
+func (m *NTPInfo) GetReferenceID() string {
+	if m != nil {
+		return m.ReferenceID
+	}
+	return ""
+}
+
+func (m *NTPInfo) GetTransmitTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.TransmitTime
+	}
+	return nil
+}
+
+type SNMPInfo struct {
+	Community            string   `protobuf:"bytes,1,opt,name=community,proto3" json:"community,omitempty"`
+	SysDescr             string   `protobuf:"bytes,2,opt,name=sysDescr,proto3" json:"sysDescr,omitempty"`
+	ErrorStatus          uint32   `protobuf:"varint,3,opt,name=errorStatus,proto3" json:"errorStatus,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SNMPInfo) Reset()         { *m = SNMPInfo{} }
+func (m *SNMPInfo) String() string { return proto.CompactTextString(m) }
+func (*SNMPInfo) ProtoMessage()    {}
+func (*SNMPInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ab30010df94cd8f, []int{16}
+}
+
+func (m *SNMPInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SNMPInfo.Unmarshal(m, b)
+}
+func (m *SNMPInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SNMPInfo.Marshal(b, m, deterministic)
+}
+func (m *SNMPInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SNMPInfo.Merge(m, src)
+}
+func (m *SNMPInfo) XXX_Size() int {
+	return xxx_messageInfo_SNMPInfo.Size(m)
+}
+func (m *SNMPInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_SNMPInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SNMPInfo proto.InternalMessageInfo
+
+func (m *SNMPInfo) GetCommunity() string {
+	if m != nil {
+		return m.Community
+	}
+	return ""
+}
+
+func (m *SNMPInfo) GetSysDescr() string {
+	if m != nil {
+		return m.SysDescr
+	}
+	return ""
+}
+
+func (m *SNMPInfo) GetErrorStatus() uint32 {
+	if m != nil {
+		return m.ErrorStatus
+	}
+	return 0
+}
+
+type PortmapProgram struct {
+	Program              uint32   `protobuf:"varint,1,opt,name=program,proto3" json:"program,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Version              uint32   `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	Protocol             string   `protobuf:"bytes,4,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Port                 uint32   `protobuf:"varint,5,opt,name=port,proto3" json:"port,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PortmapProgram) Reset()         { *m = PortmapProgram{} }
+func (m *PortmapProgram) String() string { return proto.CompactTextString(m) }
+func (*PortmapProgram) ProtoMessage()    {}
+func (*PortmapProgram) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ab30010df94cd8f, []int{17}
+}
+
+func (m *PortmapProgram) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PortmapProgram.Unmarshal(m, b)
+}
+func (m *PortmapProgram) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PortmapProgram.Marshal(b, m, deterministic)
+}
+func (m *PortmapProgram) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PortmapProgram.Merge(m, src)
+}
+func (m *PortmapProgram) XXX_Size() int {
+	return xxx_messageInfo_PortmapProgram.Size(m)
+}
+func (m *PortmapProgram) XXX_DiscardUnknown() {
+	xxx_messageInfo_PortmapProgram.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PortmapProgram proto.InternalMessageInfo
+
+func (m *PortmapProgram) GetProgram() uint32 {
+	if m != nil {
+		return m.Program
+	}
+	return 0
+}
+
+func (m *PortmapProgram) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *PortmapProgram) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *PortmapProgram) GetProtocol() string {
+	if m != nil {
+		return m.Protocol
+	}
+	return ""
+}
+
+func (m *PortmapProgram) GetPort() uint32 {
+	if m != nil {
+		return m.Port
+	}
+	return 0
+}
+
+type PortmapInfo struct {
+	Programs             []*PortmapProgram `protobuf:"bytes,1,rep,name=programs,proto3" json:"programs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *PortmapInfo) Reset()         { *m = PortmapInfo{} }
+func (m *PortmapInfo) String() string { return proto.CompactTextString(m) }
+func (*PortmapInfo) ProtoMessage()    {}
+func (*PortmapInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ab30010df94cd8f, []int{18}
+}
+
+func (m *PortmapInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PortmapInfo.Unmarshal(m, b)
+}
+func (m *PortmapInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PortmapInfo.Marshal(b, m, deterministic)
+}
+func (m *PortmapInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PortmapInfo.Merge(m, src)
+}
+func (m *PortmapInfo) XXX_Size() int {
+	return xxx_messageInfo_PortmapInfo.Size(m)
+}
+func (m *PortmapInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_PortmapInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PortmapInfo proto.InternalMessageInfo
+
+func (m *PortmapInfo) GetPrograms() []*PortmapProgram {
+	if m != nil {
+		return m.Programs
+	}
+	return nil
+}
+
+type MSSQLInstance struct {
+	ServerName           string   `protobuf:"bytes,1,opt,name=serverName,proto3" json:"serverName,omitempty"`
+	InstanceName         string   `protobuf:"bytes,2,opt,name=instanceName,proto3" json:"instanceName,omitempty"`
+	Clustered            bool     `protobuf:"varint,3,opt,name=clustered,proto3" json:"clustered,omitempty"`
+	Version              string   `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	TcpPort              uint32   `protobuf:"varint,5,opt,name=tcpPort,proto3" json:"tcpPort,omitempty"`
+	NamedPipe            string   `protobuf:"bytes,6,opt,name=namedPipe,proto3" json:"namedPipe,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MSSQLInstance) Reset()         { *m = MSSQLInstance{} }
+func (m *MSSQLInstance) String() string { return proto.CompactTextString(m) }
+func (*MSSQLInstance) ProtoMessage()    {}
+func (*MSSQLInstance) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ab30010df94cd8f, []int{19}
+}
+
+func (m *MSSQLInstance) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MSSQLInstance.Unmarshal(m, b)
+}
+func (m *MSSQLInstance) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MSSQLInstance.Marshal(b, m, deterministic)
+}
+func (m *MSSQLInstance) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MSSQLInstance.Merge(m, src)
+}
+func (m *MSSQLInstance) XXX_Size() int {
+	return xxx_messageInfo_MSSQLInstance.Size(m)
+}
+func (m *MSSQLInstance) XXX_DiscardUnknown() {
+	xxx_messageInfo_MSSQLInstance.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MSSQLInstance proto.InternalMessageInfo
+
+func (m *MSSQLInstance) GetServerName() string {
+	if m != nil {
+		return m.ServerName
+	}
+	return ""
+}
+
+func (m *MSSQLInstance) GetInstanceName() string {
+	if m != nil {
+		return m.InstanceName
+	}
+	return ""
+}
+
+func (m *MSSQLInstance) GetClustered() bool {
+	if m != nil {
+		return m.Clustered
+	}
+	return false
+}
+
+func (m *MSSQLInstance) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *MSSQLInstance) GetTcpPort() uint32 {
+	if m != nil {
+		return m.TcpPort
+	}
+	return 0
+}
+
+func (m *MSSQLInstance) GetNamedPipe() string {
+	if m != nil {
+		return m.NamedPipe
+	}
+	return ""
+}
+
+type MSSQLBrowserInfo struct {
+	Instances            []*MSSQLInstance `protobuf:"bytes,1,rep,name=instances,proto3" json:"instances,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *MSSQLBrowserInfo) Reset()         { *m = MSSQLBrowserInfo{} }
+func (m *MSSQLBrowserInfo) String() string { return proto.CompactTextString(m) }
+func (*MSSQLBrowserInfo) ProtoMessage()    {}
+func (*MSSQLBrowserInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ab30010df94cd8f, []int{20}
+}
+
+func (m *MSSQLBrowserInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MSSQLBrowserInfo.Unmarshal(m, b)
+}
+func (m *MSSQLBrowserInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MSSQLBrowserInfo.Marshal(b, m, deterministic)
+}
+func (m *MSSQLBrowserInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MSSQLBrowserInfo.Merge(m, src)
+}
+func (m *MSSQLBrowserInfo) XXX_Size() int {
+	return xxx_messageInfo_MSSQLBrowserInfo.Size(m)
+}
+func (m *MSSQLBrowserInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_MSSQLBrowserInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MSSQLBrowserInfo proto.InternalMessageInfo
+
+func (m *MSSQLBrowserInfo) GetInstances() []*MSSQLInstance {
+	if m != nil {
+		return m.Instances
+	}
+	return nil
+}
+
+// The ICA browser reply is mostly undocumented,
+// only its type is reported
+type CitrixInfo struct {
+	ReplyType            uint32   `protobuf:"varint,1,opt,name=replyType,proto3" json:"replyType,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CitrixInfo) Reset()         { *m = CitrixInfo{} }
+func (m *CitrixInfo) String() string { return proto.CompactTextString(m) }
+func (*CitrixInfo) ProtoMessage()    {}
+func (*CitrixInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ab30010df94cd8f, []int{21}
+}
+
+func (m *CitrixInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CitrixInfo.Unmarshal(m, b)
+}
+func (m *CitrixInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CitrixInfo.Marshal(b, m, deterministic)
+}
+func (m *CitrixInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CitrixInfo.Merge(m, src)
+}
+func (m *CitrixInfo) XXX_Size() int {
+	return xxx_messageInfo_CitrixInfo.Size(m)
+}
+func (m *CitrixInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_CitrixInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CitrixInfo proto.InternalMessageInfo
+
+func (m *CitrixInfo) GetReplyType() uint32 {
+	if m != nil {
+		return m.ReplyType
+	}
+	return 0
+}
+
+type ZGrab2ScanResult struct {
+	JsonResult           *_struct.Value `protobuf:"bytes,1,opt,name=jsonResult,proto3" json:"jsonResult,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ZGrab2ScanResult) Reset()         { *m = ZGrab2ScanResult{} }
+func (m *ZGrab2ScanResult) String() string { return proto.CompactTextString(m) }
+func (*ZGrab2ScanResult) ProtoMessage()    {}
+func (*ZGrab2ScanResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3ab30010df94cd8f, []int{22}
 }
 
 func (m *ZGrab2ScanResult) XXX_Unmarshal(b []byte) error {
@@ -428,44 +1958,143 @@ func (m *ZGrab2ScanResult) GetJsonResult() *_struct.Value {
 func init() {
 	proto.RegisterType((*Event)(nil), "nraySchema.Event")
 	proto.RegisterType((*ScanResult)(nil), "nraySchema.ScanResult")
+	proto.RegisterType((*Enrichment)(nil), "nraySchema.Enrichment")
 	proto.RegisterType((*EnvironmentInformation)(nil), "nraySchema.EnvironmentInformation")
 	proto.RegisterType((*PortScanResult)(nil), "nraySchema.PortScanResult")
+	proto.RegisterType((*UDPResponse)(nil), "nraySchema.UDPResponse")
+	proto.RegisterType((*HostDiscoveryResult)(nil), "nraySchema.HostDiscoveryResult")
+	proto.RegisterType((*SkippedTarget)(nil), "nraySchema.SkippedTarget")
+	proto.RegisterType((*ServiceDetectionResult)(nil), "nraySchema.ServiceDetectionResult")
+	proto.RegisterType((*SSHScanResult)(nil), "nraySchema.SSHScanResult")
+	proto.RegisterType((*SSHHostKey)(nil), "nraySchema.SSHHostKey")
+	proto.RegisterType((*UDPServiceResult)(nil), "nraySchema.UDPServiceResult")
+	proto.RegisterType((*DNSVersionInfo)(nil), "nraySchema.DNSVersionInfo")
+	proto.RegisterType((*NetBIOSName)(nil), "nraySchema.NetBIOSName")
+	proto.RegisterType((*NetBIOSInfo)(nil), "nraySchema.NetBIOSInfo")
+	proto.RegisterType((*NTPInfo)(nil), "nraySchema.NTPInfo")
+	proto.RegisterType((*SNMPInfo)(nil), "nraySchema.SNMPInfo")
+	proto.RegisterType((*PortmapProgram)(nil), "nraySchema.PortmapProgram")
+	proto.RegisterType((*PortmapInfo)(nil), "nraySchema.PortmapInfo")
+	proto.RegisterType((*MSSQLInstance)(nil), "nraySchema.MSSQLInstance")
+	proto.RegisterType((*MSSQLBrowserInfo)(nil), "nraySchema.MSSQLBrowserInfo")
+	proto.RegisterType((*CitrixInfo)(nil), "nraySchema.CitrixInfo")
 	proto.RegisterType((*ZGrab2ScanResult)(nil), "nraySchema.ZGrab2ScanResult")
 }
 
 func init() { proto.RegisterFile("schemas/events.proto", fileDescriptor_3ab30010df94cd8f) }
 
 var fileDescriptor_3ab30010df94cd8f = []byte{
-	// 489 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x52, 0x4f, 0x6b, 0xdb, 0x4e,
-	0x10, 0x8d, 0x14, 0xdb, 0x91, 0xc6, 0xbf, 0x5f, 0x08, 0x4b, 0x31, 0x42, 0x04, 0x6a, 0x74, 0xf2,
-	0x49, 0x2e, 0x29, 0x94, 0x1c, 0x7a, 0x0a, 0x49, 0x9b, 0xf4, 0x50, 0xca, 0xa6, 0xf4, 0xd0, 0xdb,
-	0x5a, 0xde, 0x38, 0x2e, 0xd6, 0x8e, 0xd8, 0x5d, 0x05, 0xd2, 0x0f, 0xd0, 0x8f, 0xd1, 0xcf, 0x51,
-	0xfa, 0xe9, 0xca, 0x8e, 0xfe, 0xad, 0x4d, 0x4e, 0xda, 0xa7, 0x79, 0xf3, 0x66, 0xf6, 0xbd, 0x85,
-	0x57, 0xa6, 0x78, 0x94, 0xa5, 0x30, 0x4b, 0xf9, 0x24, 0x95, 0x35, 0x79, 0xa5, 0xd1, 0x22, 0x03,
-	0xa5, 0xc5, 0xf3, 0x3d, 0x55, 0xd2, 0xd7, 0x1b, 0xc4, 0xcd, 0x4e, 0x2e, 0xa9, 0xb2, 0xaa, 0x1f,
-	0x96, 0x76, 0x5b, 0x4a, 0x63, 0x45, 0x59, 0x35, 0xe4, 0xf4, 0xfc, 0x90, 0x60, 0xac, 0xae, 0x0b,
-	0xdb, 0x54, 0xb3, 0xdf, 0x21, 0x8c, 0x6f, 0x9c, 0x36, 0x9b, 0xc1, 0x44, 0xe1, 0x5a, 0xde, 0x5d,
-	0x27, 0xc1, 0x3c, 0x58, 0xc4, 0xbc, 0x45, 0x2c, 0x85, 0xc8, 0x9d, 0x3e, 0x8b, 0x52, 0x26, 0x21,
-	0x55, 0x7a, 0xcc, 0x2e, 0x21, 0xee, 0xc7, 0x25, 0xc7, 0xf3, 0x60, 0x31, 0xbd, 0x48, 0xf3, 0x66,
-	0x5e, 0xde, 0xcd, 0xcb, 0xbf, 0x76, 0x0c, 0x3e, 0x90, 0xd9, 0x1c, 0xa6, 0xa6, 0x10, 0x4a, 0x49,
-	0xad, 0x9c, 0xf0, 0x84, 0x84, 0xfd, 0x5f, 0xec, 0x03, 0x4c, 0xa5, 0x7a, 0xda, 0x6a, 0x54, 0xa5,
-	0x54, 0x36, 0x39, 0x21, 0xf5, 0x2c, 0x1f, 0xae, 0x9e, 0xdf, 0x0c, 0xe5, 0x3b, 0xf5, 0x80, 0xba,
-	0x14, 0x76, 0x8b, 0xea, 0xf6, 0x88, 0xfb, 0x8d, 0xec, 0x0d, 0x4c, 0xb4, 0x34, 0xf5, 0xce, 0x26,
-	0x11, 0x49, 0xcc, 0x7c, 0x89, 0xfb, 0x42, 0x28, 0x4e, 0xd5, 0xdb, 0x23, 0xde, 0xf2, 0xae, 0xa6,
-	0x10, 0x93, 0x25, 0xd7, 0xc2, 0x8a, 0xec, 0x6f, 0x00, 0x30, 0xb0, 0x9c, 0x4b, 0x56, 0xe8, 0x8d,
-	0xb4, 0xc9, 0xa8, 0x71, 0xa9, 0x41, 0x8c, 0xc1, 0xa8, 0x42, 0x6d, 0x93, 0xf1, 0x3c, 0x58, 0xfc,
-	0xcf, 0xe9, 0xcc, 0x2e, 0x21, 0x72, 0x5f, 0x77, 0xa9, 0x76, 0x76, 0xea, 0xcf, 0xfe, 0x82, 0xda,
-	0xee, 0xcd, 0xef, 0xd9, 0xec, 0x3d, 0xc4, 0x3f, 0x37, 0x5a, 0xac, 0xa8, 0x35, 0xa6, 0xd6, 0x73,
-	0xbf, 0xf5, 0xfb, 0x47, 0x2d, 0x56, 0x17, 0x7b, 0xcd, 0x43, 0xc3, 0x55, 0xd4, 0xdd, 0x38, 0xfb,
-	0x13, 0xc0, 0xec, 0x65, 0x97, 0x5c, 0xac, 0x8f, 0x68, 0x2c, 0xb9, 0xdf, 0x04, 0xde, 0x63, 0x76,
-	0x0a, 0x21, 0x9a, 0x36, 0xec, 0x10, 0x0d, 0x3b, 0x83, 0xe3, 0x6a, 0xbb, 0xa6, 0x80, 0x63, 0xee,
-	0x8e, 0x2e, 0xbe, 0x4a, 0x63, 0x21, 0x8d, 0x21, 0x81, 0xc6, 0x0b, 0xff, 0x97, 0xd3, 0xaf, 0x4d,
-	0x9b, 0xee, 0xb8, 0xd1, 0xef, 0x30, 0xcb, 0xe0, 0xbf, 0xa2, 0xaa, 0x4b, 0x5c, 0xcb, 0x9d, 0x97,
-	0xfe, 0xde, 0xbf, 0xec, 0x57, 0x00, 0xa7, 0xfb, 0x0e, 0x79, 0xde, 0x07, 0x2f, 0x7a, 0x1f, 0x7a,
-	0xde, 0x33, 0x18, 0x61, 0x25, 0x15, 0xed, 0x1c, 0x71, 0x3a, 0xbb, 0x95, 0x9c, 0x3f, 0xf6, 0xb9,
-	0xea, 0x36, 0xee, 0x31, 0x4b, 0xe0, 0xc4, 0x3d, 0x4e, 0xac, 0xbb, 0x08, 0x3b, 0x98, 0x7d, 0x82,
-	0xb3, 0x43, 0xbb, 0xd9, 0x3b, 0x80, 0x1f, 0x06, 0x5b, 0x44, 0xdb, 0xb8, 0x77, 0x75, 0xf8, 0xf0,
-	0xbf, 0x89, 0x5d, 0x2d, 0xb9, 0xc7, 0x5c, 0x4d, 0xa8, 0xf6, 0xf6, 0x5f, 0x00, 0x00, 0x00, 0xff,
-	0xff, 0x3e, 0x8b, 0x35, 0x95, 0xd7, 0x03, 0x00, 0x00,
+	// 1788 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x58, 0xdd, 0x6e, 0x1c, 0x49,
+	0x15, 0xde, 0xf9, 0xf1, 0xfc, 0x9c, 0xf1, 0x18, 0x53, 0x89, 0xbc, 0xbd, 0x56, 0xc4, 0x5a, 0x2d,
+	0x04, 0x56, 0xc4, 0x3a, 0x2b, 0x2f, 0x09, 0x41, 0x42, 0x11, 0xeb, 0x75, 0xc0, 0x21, 0xac, 0xd7,
+	0xf4, 0x84, 0xbd, 0xe0, 0xae, 0xa6, 0xbb, 0x3c, 0xd3, 0x64, 0xba, 0xaa, 0xa9, 0xaa, 0x76, 0x32,
+	0x2b, 0x1e, 0x00, 0x09, 0x5e, 0x01, 0xb8, 0x45, 0xdc, 0x72, 0x01, 0x37, 0x5c, 0xf1, 0x44, 0xbc,
+	0x01, 0x3a, 0xf5, 0xd3, 0x5d, 0x3d, 0x33, 0x1b, 0xa4, 0xbd, 0xab, 0xf3, 0x5b, 0xa7, 0x4e, 0x7d,
+	0xe7, 0xf4, 0xa9, 0x86, 0xfb, 0x2a, 0x5d, 0xb2, 0x82, 0xaa, 0x47, 0xec, 0x8e, 0x71, 0xad, 0xce,
+	0x4a, 0x29, 0xb4, 0x20, 0xc0, 0x25, 0x5d, 0xcf, 0x8c, 0xe4, 0xf8, 0xc3, 0x85, 0x10, 0x8b, 0x15,
+	0x7b, 0x64, 0x24, 0xf3, 0xea, 0xf6, 0x91, 0xce, 0x0b, 0xa6, 0x34, 0x2d, 0x4a, 0xab, 0x7c, 0xfc,
+	0x60, 0x53, 0x41, 0x69, 0x59, 0xa5, 0xda, 0x4a, 0xe3, 0x3f, 0xf5, 0x60, 0xef, 0x39, 0xfa, 0x26,
+	0x47, 0x30, 0xe0, 0x22, 0x63, 0x2f, 0x2e, 0xa3, 0xce, 0x49, 0xe7, 0x74, 0x9c, 0x38, 0x8a, 0x1c,
+	0xc3, 0x08, 0x57, 0xd7, 0xb4, 0x60, 0x51, 0xd7, 0x48, 0x6a, 0x9a, 0x3c, 0x85, 0x71, 0xbd, 0x5d,
+	0xd4, 0x3b, 0xe9, 0x9c, 0x4e, 0xce, 0x8f, 0xcf, 0xec, 0x7e, 0x67, 0x7e, 0xbf, 0xb3, 0x57, 0x5e,
+	0x23, 0x69, 0x94, 0xc9, 0x09, 0x4c, 0x54, 0x4a, 0x39, 0x67, 0x92, 0xa3, 0xe3, 0x81, 0x71, 0x1c,
+	0xb2, 0xc8, 0xcf, 0x60, 0xc2, 0xf8, 0x5d, 0x2e, 0x05, 0x2f, 0x18, 0xd7, 0xd1, 0xd0, 0x78, 0x8f,
+	0xcf, 0x9a, 0xa3, 0x9f, 0x3d, 0x6f, 0xc4, 0x2f, 0xf8, 0xad, 0x90, 0x05, 0xd5, 0xb9, 0xe0, 0x57,
+	0xef, 0x25, 0xa1, 0x21, 0xf9, 0x18, 0x06, 0x92, 0xa9, 0x6a, 0xa5, 0xa3, 0x91, 0x71, 0x71, 0x14,
+	0xba, 0x98, 0xa5, 0x94, 0x27, 0x46, 0x7a, 0xf5, 0x5e, 0xe2, 0xf4, 0xc8, 0x63, 0x18, 0xaa, 0xd7,
+	0x79, 0x59, 0xb2, 0x2c, 0x1a, 0x1b, 0x93, 0x0f, 0x5a, 0x26, 0x56, 0xf4, 0x8a, 0xca, 0x05, 0x43,
+	0x2b, 0xaf, 0x4b, 0x1e, 0x43, 0x7f, 0x29, 0x94, 0x8e, 0xc0, 0xd8, 0x7c, 0x18, 0xda, 0x5c, 0x09,
+	0xa5, 0x2f, 0x73, 0x95, 0x8a, 0x3b, 0x26, 0xd7, 0xf5, 0x7e, 0x46, 0xfd, 0x62, 0x02, 0x63, 0x73,
+	0x01, 0x97, 0x54, 0xd3, 0xf8, 0xdf, 0x3d, 0x80, 0x26, 0x26, 0xbc, 0x13, 0x6d, 0xf6, 0x89, 0xfa,
+	0xf6, 0x4e, 0x2c, 0x45, 0x08, 0xf4, 0x4b, 0x21, 0x75, 0xb4, 0x77, 0xd2, 0x39, 0x9d, 0x26, 0x66,
+	0x8d, 0xf7, 0x84, 0xfe, 0x82, 0x74, 0xd6, 0x34, 0x79, 0x0a, 0x23, 0xd4, 0xc1, 0xf4, 0xba, 0x2c,
+	0x1c, 0x87, 0xe1, 0xdd, 0x08, 0xa9, 0x5b, 0x99, 0xa8, 0xb5, 0xc9, 0x4f, 0x60, 0xfc, 0xd5, 0x42,
+	0xd2, 0xb9, 0x31, 0xb5, 0xd9, 0x78, 0x10, 0x9a, 0xfe, 0xe6, 0xe7, 0x92, 0xce, 0xcf, 0x5b, 0xc6,
+	0x8d, 0x01, 0x79, 0x06, 0x50, 0x65, 0xa5, 0x62, 0xf2, 0x2e, 0x4f, 0x59, 0x04, 0xdb, 0xe6, 0xbf,
+	0xbe, 0xbc, 0x99, 0x59, 0x69, 0x6d, 0x1e, 0x58, 0x90, 0x67, 0x30, 0xf4, 0xc6, 0xfb, 0xdb, 0xf7,
+	0xef, 0x2c, 0x2f, 0x99, 0x66, 0x29, 0xde, 0x7c, 0xed, 0xc2, 0x1b, 0x91, 0x8f, 0xa0, 0xa7, 0xd4,
+	0x32, 0x9a, 0xee, 0xb8, 0xc5, 0xd9, 0x55, 0x2b, 0x68, 0xd4, 0x23, 0x4f, 0x00, 0x18, 0x97, 0x79,
+	0xba, 0x34, 0x88, 0x9b, 0x6c, 0xc3, 0xe5, 0x79, 0x2d, 0x4d, 0x02, 0xcd, 0x8b, 0x91, 0x87, 0x58,
+	0xfc, 0xf7, 0x0e, 0x40, 0xa3, 0x44, 0x22, 0x18, 0xd2, 0x2c, 0x93, 0x4c, 0x29, 0x57, 0x54, 0x9e,
+	0x24, 0x87, 0xd0, 0x2b, 0xb5, 0x8c, 0xba, 0x27, 0xbd, 0xd3, 0x71, 0x82, 0x4b, 0xd4, 0x4d, 0x45,
+	0xc5, 0xb5, 0x5c, 0x9b, 0x4a, 0x1a, 0x27, 0x9e, 0xc4, 0x5a, 0x71, 0x4b, 0x53, 0x84, 0x16, 0x0a,
+	0x21, 0x0b, 0xbd, 0x51, 0xc5, 0x1d, 0x1c, 0x70, 0x49, 0x62, 0xd8, 0x17, 0x72, 0x41, 0x79, 0xfe,
+	0x95, 0x29, 0x0a, 0x87, 0x88, 0x16, 0x2f, 0xfe, 0x57, 0x07, 0x8e, 0x76, 0xd7, 0x50, 0x0b, 0x4c,
+	0x9d, 0x0d, 0x30, 0x1d, 0x40, 0x57, 0x28, 0xd7, 0x0a, 0xba, 0xc2, 0x1e, 0x25, 0xcf, 0x5c, 0xd0,
+	0xb8, 0xc4, 0x80, 0x4b, 0x29, 0x52, 0xa6, 0x14, 0x0f, 0x02, 0x0e, 0x58, 0xe8, 0xbf, 0x52, 0xae,
+	0xf6, 0xf7, 0xac, 0x7f, 0x4f, 0x63, 0xe8, 0x69, 0x59, 0x15, 0x22, 0x63, 0xab, 0x00, 0xcc, 0x2d,
+	0x5e, 0xfc, 0xd7, 0x2e, 0x1c, 0xb4, 0x51, 0x1b, 0xd4, 0x4a, 0x67, 0x67, 0xad, 0x74, 0x83, 0x5a,
+	0x21, 0xd0, 0x17, 0x25, 0xe3, 0x26, 0xe6, 0x51, 0x62, 0xd6, 0x18, 0x12, 0x62, 0x56, 0xaf, 0x4b,
+	0x1f, 0x71, 0x4d, 0xe3, 0xdd, 0x60, 0xeb, 0x12, 0x95, 0x2f, 0x39, 0x4f, 0x92, 0xfb, 0xb0, 0xa7,
+	0x34, 0xd5, 0x3e, 0x4a, 0x4b, 0x90, 0x1f, 0xc3, 0xa4, 0xca, 0x4a, 0xc9, 0x54, 0x29, 0xb8, 0x62,
+	0xae, 0x77, 0xbd, 0xbf, 0x01, 0xfc, 0xc4, 0x89, 0x93, 0x50, 0x17, 0x8f, 0xa1, 0x44, 0x25, 0x53,
+	0x66, 0x0a, 0x75, 0x9c, 0x38, 0x0a, 0x37, 0x2a, 0xa5, 0x78, 0xbb, 0x36, 0x45, 0x38, 0x4e, 0x2c,
+	0x11, 0x02, 0x0c, 0x5a, 0x00, 0x8b, 0x05, 0x4c, 0x82, 0x3d, 0xd0, 0xed, 0x8a, 0xf1, 0x85, 0x5e,
+	0x9a, 0xec, 0x4c, 0x13, 0x47, 0x61, 0x26, 0x32, 0xaa, 0xa9, 0xc9, 0xce, 0x7e, 0x62, 0xd6, 0xe4,
+	0x01, 0x8c, 0xb5, 0xac, 0x78, 0x4a, 0x35, 0xcb, 0x5c, 0x8a, 0x1a, 0x06, 0x7a, 0x2a, 0xa5, 0x98,
+	0x33, 0x65, 0xb2, 0x34, 0x4d, 0x1c, 0x15, 0xff, 0xb9, 0x03, 0xf7, 0x76, 0xf4, 0xb9, 0xaf, 0xbd,
+	0x97, 0x10, 0x62, 0xdd, 0x6d, 0x88, 0x55, 0xa5, 0xdb, 0xba, 0x5b, 0x95, 0xe8, 0xa3, 0x60, 0x7a,
+	0x29, 0x32, 0xdf, 0x07, 0x2d, 0x85, 0xd0, 0x93, 0xda, 0xdf, 0x09, 0x2e, 0xc3, 0x84, 0x0c, 0xda,
+	0x09, 0xf9, 0x67, 0x07, 0xa6, 0xad, 0xde, 0xfd, 0x8d, 0x22, 0x3b, 0xc2, 0x52, 0xa7, 0x4a, 0x70,
+	0x87, 0x77, 0x47, 0xa1, 0x8d, 0x4e, 0x4b, 0xd3, 0x36, 0xa3, 0xfe, 0x49, 0xef, 0x74, 0x9a, 0xd4,
+	0xb4, 0x01, 0x7b, 0xe6, 0x64, 0x7b, 0x56, 0xe6, 0x69, 0x04, 0xfb, 0x9c, 0xea, 0x74, 0xf9, 0xe9,
+	0x5c, 0x48, 0x4c, 0xf7, 0xc0, 0x9c, 0xb9, 0xc5, 0x8b, 0xff, 0xd6, 0x85, 0xa3, 0xdd, 0xbd, 0x0e,
+	0x5d, 0x9b, 0xef, 0x6c, 0x2a, 0x56, 0xbe, 0x4e, 0x3d, 0x8d, 0xa9, 0xf0, 0xcd, 0xd3, 0x9e, 0xc2,
+	0x93, 0x28, 0x29, 0xa5, 0xc8, 0xaa, 0x54, 0xfb, 0x56, 0xe3, 0x48, 0x94, 0xdc, 0x31, 0xa9, 0xb0,
+	0x63, 0xd8, 0x4c, 0x7b, 0x12, 0x81, 0x92, 0xf3, 0x5b, 0xe1, 0xaa, 0xd5, 0xac, 0xdf, 0xf9, 0xc9,
+	0xb1, 0x5d, 0x62, 0x58, 0x77, 0x89, 0xef, 0x00, 0x64, 0x0c, 0x77, 0x37, 0x05, 0x66, 0xb1, 0x1d,
+	0x70, 0xf0, 0x2a, 0xd3, 0x92, 0x45, 0x63, 0xdb, 0x10, 0xd3, 0xd2, 0x23, 0x7e, 0xce, 0x1c, 0xb2,
+	0x2d, 0x81, 0xe0, 0x54, 0xe2, 0x56, 0x17, 0x98, 0x20, 0xd3, 0xa2, 0x47, 0x49, 0xc3, 0x88, 0xff,
+	0xdb, 0x87, 0x69, 0xab, 0xb5, 0xe3, 0x85, 0xcd, 0xcd, 0x50, 0xe1, 0x2f, 0xd9, 0x52, 0x98, 0x78,
+	0x93, 0x29, 0x7f, 0x5c, 0x9b, 0xa2, 0x16, 0x8f, 0x9c, 0xc2, 0xb7, 0xd0, 0xf5, 0x1b, 0x2a, 0x99,
+	0x57, 0xb3, 0xf9, 0xda, 0x64, 0x63, 0x26, 0x52, 0x51, 0x60, 0x17, 0x55, 0xbe, 0x79, 0x78, 0x9a,
+	0x7c, 0x17, 0xa6, 0xaf, 0xd9, 0xdb, 0x4f, 0x57, 0x0b, 0x21, 0x73, 0xbd, 0x2c, 0x2c, 0x06, 0xc6,
+	0x49, 0x9b, 0x49, 0x7e, 0x00, 0xdf, 0xc6, 0xdc, 0xbd, 0x64, 0xeb, 0x40, 0x73, 0x60, 0x34, 0xb7,
+	0x05, 0xe4, 0x63, 0xb8, 0x97, 0xe6, 0xe5, 0x92, 0x49, 0xf5, 0xd9, 0x2a, 0x67, 0x5c, 0x23, 0x3c,
+	0x98, 0x8c, 0x86, 0x46, 0x7f, 0x97, 0x28, 0xb0, 0xb0, 0x0c, 0x2b, 0x8c, 0x46, 0x2d, 0x8b, 0x50,
+	0x44, 0x1e, 0xc2, 0x61, 0x41, 0xd3, 0xf6, 0x06, 0xf6, 0x7a, 0xb6, 0xf8, 0x5e, 0xb7, 0xe5, 0x1a,
+	0x1a, 0xdd, 0x96, 0xdf, 0xa7, 0xf0, 0x7e, 0x2a, 0x0a, 0xec, 0x78, 0x98, 0xba, 0x96, 0xfb, 0x89,
+	0x31, 0xf9, 0x3a, 0xf1, 0x86, 0x65, 0x6b, 0xb3, 0xfd, 0x2d, 0xcb, 0xd6, 0x9e, 0xe7, 0x16, 0xa9,
+	0x2f, 0xd9, 0x5a, 0x45, 0xd3, 0x93, 0xde, 0xd6, 0x18, 0x38, 0xbb, 0xba, 0xb2, 0xe2, 0xa4, 0xd6,
+	0x23, 0xdf, 0x83, 0x83, 0x37, 0x8c, 0xbe, 0x0e, 0xae, 0xe3, 0xc0, 0x6c, 0xb2, 0xc1, 0x8d, 0x7f,
+	0x0f, 0xd0, 0xd8, 0x63, 0x9d, 0x18, 0x84, 0x5b, 0xb4, 0x99, 0x35, 0xf2, 0xe6, 0xb9, 0x56, 0xfe,
+	0x13, 0x84, 0x6b, 0xd3, 0xe7, 0x97, 0xf4, 0xfc, 0xf1, 0x13, 0xdf, 0x48, 0x2c, 0x85, 0x75, 0x50,
+	0x64, 0x8f, 0x1d, 0x88, 0x70, 0x89, 0x88, 0x2f, 0xab, 0xf9, 0x2a, 0x4f, 0x5f, 0xb2, 0xb5, 0x2b,
+	0xbf, 0x86, 0x11, 0xff, 0xb1, 0x07, 0x87, 0x9b, 0x53, 0x54, 0x58, 0xfa, 0x9d, 0x76, 0xe9, 0x9f,
+	0x41, 0x2f, 0xe3, 0x36, 0x92, 0x8d, 0x21, 0xf0, 0xf2, 0x7a, 0xf6, 0xa5, 0x45, 0x33, 0x0e, 0x02,
+	0x38, 0x12, 0x65, 0x5c, 0x91, 0x4f, 0x60, 0xc8, 0x99, 0x9e, 0xe7, 0x42, 0x45, 0xbd, 0xed, 0xaf,
+	0xd8, 0x35, 0xd3, 0x17, 0x2f, 0xbe, 0x98, 0x39, 0x03, 0xaf, 0x49, 0xbe, 0x0f, 0x3d, 0xae, 0x4b,
+	0x73, 0x86, 0xc9, 0xf9, 0xbd, 0x96, 0xc1, 0xab, 0x1b, 0xef, 0x9d, 0xeb, 0x92, 0x3c, 0x84, 0xbe,
+	0xe2, 0x45, 0x69, 0x4e, 0x35, 0x39, 0xbf, 0xdf, 0xba, 0x92, 0xeb, 0xcf, 0xbd, 0xaa, 0xd1, 0xc1,
+	0x48, 0xb0, 0x65, 0x16, 0xb4, 0x8c, 0x06, 0xdb, 0x91, 0xdc, 0x58, 0x91, 0x8f, 0xc4, 0x69, 0x92,
+	0x1f, 0xc2, 0x5e, 0xa1, 0xd4, 0xef, 0x56, 0xd1, 0x70, 0x7b, 0xf6, 0xfc, 0x7c, 0x36, 0xfb, 0xd5,
+	0x2f, 0x2f, 0xa4, 0x78, 0xa3, 0x98, 0x74, 0x76, 0x56, 0x19, 0x9f, 0x0c, 0x69, 0xae, 0x65, 0xfe,
+	0x76, 0xd7, 0x93, 0xe1, 0x33, 0x23, 0x71, 0x06, 0x4e, 0xef, 0x62, 0x60, 0xbb, 0x63, 0xfc, 0x53,
+	0x38, 0x68, 0xe7, 0x11, 0xbb, 0x98, 0x4c, 0x45, 0xe6, 0x2f, 0xc2, 0x12, 0x61, 0x9f, 0xed, 0xb6,
+	0xfa, 0x6c, 0xfc, 0x05, 0x4c, 0x5c, 0x56, 0xcd, 0x64, 0x47, 0xa0, 0x1f, 0x0c, 0x61, 0x7d, 0xff,
+	0x0d, 0x52, 0xd5, 0xed, 0x6d, 0xfe, 0xd6, 0x01, 0xca, 0x51, 0xb8, 0xd5, 0x42, 0x8a, 0xfa, 0xc3,
+	0x69, 0x89, 0xf8, 0x2f, 0x9d, 0xda, 0xa3, 0x09, 0x08, 0xc7, 0x2b, 0x51, 0x94, 0x95, 0x66, 0xf2,
+	0xba, 0xf1, 0xdc, 0xe2, 0xe1, 0x0e, 0x99, 0x28, 0x68, 0xee, 0xa3, 0x73, 0x14, 0x46, 0x83, 0x63,
+	0x9a, 0x83, 0xac, 0x59, 0x1b, 0xc0, 0xd2, 0xb4, 0x06, 0x2c, 0x4d, 0xc9, 0x47, 0xb0, 0x87, 0x71,
+	0xda, 0x46, 0xb7, 0x1b, 0x31, 0xb8, 0x4b, 0x62, 0xb5, 0xe2, 0x7f, 0x74, 0x60, 0xe8, 0x70, 0x11,
+	0xe6, 0xc5, 0xce, 0x29, 0xe1, 0xf7, 0x07, 0xc7, 0x3f, 0x5f, 0x43, 0x85, 0xcb, 0xa2, 0xd2, 0x92,
+	0xea, 0xaa, 0x30, 0x11, 0x4d, 0x13, 0x4f, 0xe2, 0x04, 0x2a, 0xd9, 0x2d, 0x93, 0x8c, 0xa7, 0xf8,
+	0xa2, 0x75, 0x13, 0x68, 0xc0, 0x22, 0xcf, 0x60, 0x5f, 0x4b, 0xca, 0x55, 0x91, 0x6b, 0x7c, 0xa0,
+	0x46, 0x7b, 0xff, 0xf7, 0xf5, 0xda, 0xd2, 0x8f, 0x6f, 0x61, 0xe4, 0x21, 0x8a, 0x15, 0x8a, 0xdd,
+	0xbe, 0xe2, 0xb9, 0x5e, 0xbb, 0x7c, 0x36, 0x0c, 0x33, 0x58, 0xae, 0xd5, 0x25, 0x53, 0xa9, 0xf4,
+	0xe3, 0x84, 0xa7, 0x31, 0x4e, 0x26, 0xa5, 0x90, 0x33, 0x4d, 0x75, 0xa5, 0xdc, 0x29, 0x42, 0x56,
+	0xfc, 0x87, 0x8e, 0x9d, 0x74, 0x0b, 0x5a, 0xde, 0x48, 0xb1, 0x90, 0xb4, 0x70, 0x9f, 0x6f, 0x5c,
+	0xfa, 0x24, 0x39, 0xb2, 0x46, 0x4b, 0x37, 0x40, 0x4b, 0x90, 0xd2, 0x5e, 0x3b, 0xa5, 0xe1, 0xf0,
+	0xd0, 0xdf, 0x18, 0x1e, 0x76, 0xbc, 0x30, 0xe3, 0xe7, 0x30, 0x09, 0xca, 0x8c, 0x3c, 0x81, 0x91,
+	0xdb, 0x17, 0x5f, 0x37, 0xbd, 0x5d, 0x8f, 0xca, 0x26, 0xe8, 0xa4, 0xd6, 0x8d, 0xff, 0xd3, 0x81,
+	0xa9, 0xa9, 0xbd, 0x17, 0x5c, 0x69, 0xca, 0x53, 0x86, 0xb3, 0x81, 0x32, 0xdd, 0x3a, 0x00, 0x64,
+	0xc0, 0x41, 0xc8, 0xe6, 0x4e, 0x37, 0xf8, 0x0d, 0xd1, 0xe2, 0x99, 0x3b, 0x58, 0x55, 0x4a, 0x33,
+	0xd9, 0x0c, 0xad, 0x35, 0xe3, 0x1d, 0x73, 0x0d, 0x8e, 0xf6, 0x69, 0x79, 0xd3, 0x9c, 0xd5, 0x93,
+	0xe8, 0x11, 0x13, 0x98, 0xdd, 0xe4, 0xa5, 0x1f, 0x6f, 0x1a, 0x46, 0xfc, 0x12, 0x0e, 0x37, 0x1b,
+	0x08, 0xf9, 0x11, 0x8c, 0x7d, 0x4c, 0x3e, 0x25, 0x1f, 0x6c, 0x75, 0x1c, 0x7f, 0xea, 0xa4, 0xd1,
+	0x8d, 0x1f, 0x02, 0x34, 0x6d, 0x05, 0x37, 0x96, 0xac, 0x5c, 0xad, 0x5f, 0xf9, 0xef, 0xc8, 0x34,
+	0x69, 0x18, 0xf1, 0x2f, 0xe0, 0x70, 0xf3, 0xd1, 0x8d, 0x0f, 0xd7, 0xdf, 0x2a, 0x3f, 0x14, 0x46,
+	0x1d, 0xd7, 0xb4, 0x36, 0xa1, 0xfc, 0x25, 0x5d, 0x55, 0x2c, 0x09, 0x34, 0xe7, 0x03, 0x23, 0xfb,
+	0xe4, 0x7f, 0x03, 0x00, 0x0a, 0x17, 0x01, 0xdd, 0x67, 0x12, 0x00, 0x00,
 }
@@ -289,15 +289,19 @@ func (*NrayNodeMessage) XXX_OneofWrappers() []interface{} {
 }
 
 // ScanTargets may be dnsNames/ips and ports.
-//ip is encoded as byte array.
-//dnsName is a FQDN and mustn't contain a protocol specification
+// ip is encoded as byte array.
+// dnsName is a FQDN and mustn't contain a protocol specification.
+// If the server resolved DNS names, hostnames maps the resolved
+// addresses in rhosts to the names they were resolved from
 type ScanTargets struct {
-	Rhosts               []string `protobuf:"bytes,1,rep,name=rhosts,proto3" json:"rhosts,omitempty"`
-	Tcpports             []uint32 `protobuf:"varint,2,rep,packed,name=tcpports,proto3" json:"tcpports,omitempty"`
-	Udpports             []uint32 `protobuf:"varint,3,rep,packed,name=udpports,proto3" json:"udpports,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Rhosts               []string          `protobuf:"bytes,1,rep,name=rhosts,proto3" json:"rhosts,omitempty"`
+	Tcpports             []uint32          `protobuf:"varint,2,rep,packed,name=tcpports,proto3" json:"tcpports,omitempty"`
+	Udpports             []uint32          `protobuf:"varint,3,rep,packed,name=udpports,proto3" json:"udpports,omitempty"`
+	Services             []*ServiceTarget  `protobuf:"bytes,4,rep,name=services,proto3" json:"services,omitempty"`
+	Hostnames            map[string]string `protobuf:"bytes,5,rep,name=hostnames,proto3" json:"hostnames,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *ScanTargets) Reset()         { *m = ScanTargets{} }
@@ -346,9 +350,89 @@ func (m *ScanTargets) GetUdpports() []uint32 {
 	return nil
 }
 
+func (m *ScanTargets) GetServices() []*ServiceTarget {
+	if m != nil {
+		return m.Services
+	}
+	return nil
+}
+
+func (m *ScanTargets) GetHostnames() map[string]string {
+	if m != nil {
+		return m.Hostnames
+	}
+	return nil
+}
+
+// ServiceTarget is a single port on a single host. Other than
+// the cross product of rhosts and ports in ScanTargets, it allows
+// to scan exactly the services that are known to be of interest
+type ServiceTarget struct {
+	Rhost                string   `protobuf:"bytes,1,opt,name=rhost,proto3" json:"rhost,omitempty"`
+	Proto                string   `protobuf:"bytes,2,opt,name=proto,proto3" json:"proto,omitempty"`
+	Port                 uint32   `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
+	Hostname             string   `protobuf:"bytes,4,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ServiceTarget) Reset()         { *m = ServiceTarget{} }
+func (m *ServiceTarget) String() string { return proto.CompactTextString(m) }
+func (*ServiceTarget) ProtoMessage()    {}
+func (*ServiceTarget) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1723a75bcb31ddc3, []int{3}
+}
+
+func (m *ServiceTarget) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServiceTarget.Unmarshal(m, b)
+}
+func (m *ServiceTarget) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServiceTarget.Marshal(b, m, deterministic)
+}
+func (m *ServiceTarget) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServiceTarget.Merge(m, src)
+}
+func (m *ServiceTarget) XXX_Size() int {
+	return xxx_messageInfo_ServiceTarget.Size(m)
+}
+func (m *ServiceTarget) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServiceTarget.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServiceTarget proto.InternalMessageInfo
+
+func (m *ServiceTarget) GetRhost() string {
+	if m != nil {
+		return m.Rhost
+	}
+	return ""
+}
+
+func (m *ServiceTarget) GetProto() string {
+	if m != nil {
+		return m.Proto
+	}
+	return ""
+}
+
+func (m *ServiceTarget) GetPort() uint32 {
+	if m != nil {
+		return m.Port
+	}
+	return 0
+}
+
+func (m *ServiceTarget) GetHostname() string {
+	if m != nil {
+		return m.Hostname
+	}
+	return ""
+}
+
 // This message is sent every time a node registers at
-//the server. It contains a unique node ID so there are
-//not multiple scanner nodes running on the same machine
+// the server. It contains a unique node ID so there are
+// not multiple scanner nodes running on the same machine
 type NodeRegister struct {
 	MachineID            string   `protobuf:"bytes,1,opt,name=machineID,proto3" json:"machineID,omitempty"`
 	PreferredPool        int32    `protobuf:"varint,2,opt,name=preferredPool,proto3" json:"preferredPool,omitempty"`
@@ -363,7 +447,7 @@ func (m *NodeRegister) Reset()         { *m = NodeRegister{} }
 func (m *NodeRegister) String() string { return proto.CompactTextString(m) }
 func (*NodeRegister) ProtoMessage()    {}
 func (*NodeRegister) Descriptor() ([]byte, []int) {
-	return fileDescriptor_1723a75bcb31ddc3, []int{3}
+	return fileDescriptor_1723a75bcb31ddc3, []int{4}
 }
 
 func (m *NodeRegister) XXX_Unmarshal(b []byte) error {
@@ -413,8 +497,8 @@ func (m *NodeRegister) GetEnvinfo() *Event {
 }
 
 // This message is sent by the server and indicates that
-//the server does not know this node and that the node should
-//register again
+// the server does not know this node and that the node should
+// register again
 type Unregistered struct {
 	NodeID               string   `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -426,7 +510,7 @@ func (m *Unregistered) Reset()         { *m = Unregistered{} }
 func (m *Unregistered) String() string { return proto.CompactTextString(m) }
 func (*Unregistered) ProtoMessage()    {}
 func (*Unregistered) Descriptor() ([]byte, []int) {
-	return fileDescriptor_1723a75bcb31ddc3, []int{4}
+	return fileDescriptor_1723a75bcb31ddc3, []int{5}
 }
 
 func (m *Unregistered) XXX_Unmarshal(b []byte) error {
@@ -455,14 +539,16 @@ func (m *Unregistered) GetNodeID() string {
 }
 
 // This message tells the scanner that it is registered at
-//the server and assigns a unique scanner ID as well as it carries
-//the timestamp of the server, so nodes that have no correct clock
-//can still perform somewhat accurate timestamping of events
+// the server and assigns a unique scanner ID as well as it carries
+// the timestamp of the server, so nodes that have no correct clock
+// can still perform somewhat accurate timestamping of events
 type RegisteredNode struct {
 	NodeID      string               `protobuf:"bytes,1,opt,name=NodeID,proto3" json:"NodeID,omitempty"`
 	ServerClock *timestamp.Timestamp `protobuf:"bytes,2,opt,name=ServerClock,proto3" json:"ServerClock,omitempty"`
 	//int32 pool = 3;
-	Scannerconfig        []byte   `protobuf:"bytes,4,opt,name=scannerconfig,proto3" json:"scannerconfig,omitempty"`
+	Scannerconfig []byte `protobuf:"bytes,4,opt,name=scannerconfig,proto3" json:"scannerconfig,omitempty"`
+	// The node's share of the server's global rate limit in probes per second, 0 if unlimited
+	Ratelimit            float64  `protobuf:"fixed64,5,opt,name=ratelimit,proto3" json:"ratelimit,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -472,7 +558,7 @@ func (m *RegisteredNode) Reset()         { *m = RegisteredNode{} }
 func (m *RegisteredNode) String() string { return proto.CompactTextString(m) }
 func (*RegisteredNode) ProtoMessage()    {}
 func (*RegisteredNode) Descriptor() ([]byte, []int) {
-	return fileDescriptor_1723a75bcb31ddc3, []int{5}
+	return fileDescriptor_1723a75bcb31ddc3, []int{6}
 }
 
 func (m *RegisteredNode) XXX_Unmarshal(b []byte) error {
@@ -514,8 +600,15 @@ func (m *RegisteredNode) GetScannerconfig() []byte {
 	return nil
 }
 
+func (m *RegisteredNode) GetRatelimit() float64 {
+	if m != nil {
+		return m.Ratelimit
+	}
+	return 0
+}
+
 // A heartbeat message that is sent regularly from any node
-//to the server to signal that it is still alive
+// to the server to signal that it is still alive
 type Heartbeat struct {
 	NodeID               string               `protobuf:"bytes,1,opt,name=NodeID,proto3" json:"NodeID,omitempty"`
 	BeatTime             *timestamp.Timestamp `protobuf:"bytes,2,opt,name=BeatTime,proto3" json:"BeatTime,omitempty"`
@@ -528,7 +621,7 @@ func (m *Heartbeat) Reset()         { *m = Heartbeat{} }
 func (m *Heartbeat) String() string { return proto.CompactTextString(m) }
 func (*Heartbeat) ProtoMessage()    {}
 func (*Heartbeat) Descriptor() ([]byte, []int) {
-	return fileDescriptor_1723a75bcb31ddc3, []int{6}
+	return fileDescriptor_1723a75bcb31ddc3, []int{7}
 }
 
 func (m *Heartbeat) XXX_Unmarshal(b []byte) error {
@@ -564,11 +657,14 @@ func (m *Heartbeat) GetBeatTime() *timestamp.Timestamp {
 }
 
 // Acknowledgement of a heartbeat message. A server
-//may indicate to stop scanning and if the scanner should
-//exit
+// may indicate to stop scanning and if the scanner should
+// exit
 type HeartbeatAck struct {
-	Scanning             bool     `protobuf:"varint,1,opt,name=Scanning,proto3" json:"Scanning,omitempty"`
-	Running              bool     `protobuf:"varint,2,opt,name=Running,proto3" json:"Running,omitempty"`
+	Scanning bool `protobuf:"varint,1,opt,name=Scanning,proto3" json:"Scanning,omitempty"`
+	Running  bool `protobuf:"varint,2,opt,name=Running,proto3" json:"Running,omitempty"`
+	// The node's current share of the server's global rate limit in
+	// probes per second, 0 if unlimited. Changes as nodes join or leave
+	Ratelimit            float64  `protobuf:"fixed64,3,opt,name=Ratelimit,proto3" json:"Ratelimit,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -578,7 +674,7 @@ func (m *HeartbeatAck) Reset()         { *m = HeartbeatAck{} }
 func (m *HeartbeatAck) String() string { return proto.CompactTextString(m) }
 func (*HeartbeatAck) ProtoMessage()    {}
 func (*HeartbeatAck) Descriptor() ([]byte, []int) {
-	return fileDescriptor_1723a75bcb31ddc3, []int{7}
+	return fileDescriptor_1723a75bcb31ddc3, []int{8}
 }
 
 func (m *HeartbeatAck) XXX_Unmarshal(b []byte) error {
@@ -613,6 +709,13 @@ func (m *HeartbeatAck) GetRunning() bool {
 	return false
 }
 
+func (m *HeartbeatAck) GetRatelimit() float64 {
+	if m != nil {
+		return m.Ratelimit
+	}
+	return 0
+}
+
 // Sent by a node if it has no work
 type MoreWorkRequest struct {
 	NodeID               string   `protobuf:"bytes,1,opt,name=NodeID,proto3" json:"NodeID,omitempty"`
@@ -625,7 +728,7 @@ func (m *MoreWorkRequest) Reset()         { *m = MoreWorkRequest{} }
 func (m *MoreWorkRequest) String() string { return proto.CompactTextString(m) }
 func (*MoreWorkRequest) ProtoMessage()    {}
 func (*MoreWorkRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_1723a75bcb31ddc3, []int{8}
+	return fileDescriptor_1723a75bcb31ddc3, []int{9}
 }
 
 func (m *MoreWorkRequest) XXX_Unmarshal(b []byte) error {
@@ -666,7 +769,7 @@ func (m *MoreWorkReply) Reset()         { *m = MoreWorkReply{} }
 func (m *MoreWorkReply) String() string { return proto.CompactTextString(m) }
 func (*MoreWorkReply) ProtoMessage()    {}
 func (*MoreWorkReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_1723a75bcb31ddc3, []int{9}
+	return fileDescriptor_1723a75bcb31ddc3, []int{10}
 }
 
 func (m *MoreWorkReply) XXX_Unmarshal(b []byte) error {
@@ -702,7 +805,7 @@ func (m *MoreWorkReply) GetTargets() *ScanTargets {
 }
 
 // Indicates that a node is done with a work batch
-//and contains the results
+// and contains the results
 type WorkDone struct {
 	NodeID               string   `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
 	Batchid              uint64   `protobuf:"varint,2,opt,name=batchid,proto3" json:"batchid,omitempty"`
@@ -716,7 +819,7 @@ func (m *WorkDone) Reset()         { *m = WorkDone{} }
 func (m *WorkDone) String() string { return proto.CompactTextString(m) }
 func (*WorkDone) ProtoMessage()    {}
 func (*WorkDone) Descriptor() ([]byte, []int) {
-	return fileDescriptor_1723a75bcb31ddc3, []int{10}
+	return fileDescriptor_1723a75bcb31ddc3, []int{11}
 }
 
 func (m *WorkDone) XXX_Unmarshal(b []byte) error {
@@ -769,7 +872,7 @@ func (m *WorkDoneAck) Reset()         { *m = WorkDoneAck{} }
 func (m *WorkDoneAck) String() string { return proto.CompactTextString(m) }
 func (*WorkDoneAck) ProtoMessage()    {}
 func (*WorkDoneAck) Descriptor() ([]byte, []int) {
-	return fileDescriptor_1723a75bcb31ddc3, []int{11}
+	return fileDescriptor_1723a75bcb31ddc3, []int{12}
 }
 
 func (m *WorkDoneAck) XXX_Unmarshal(b []byte) error {
@@ -802,7 +905,7 @@ func (m *Goodbye) Reset()         { *m = Goodbye{} }
 func (m *Goodbye) String() string { return proto.CompactTextString(m) }
 func (*Goodbye) ProtoMessage()    {}
 func (*Goodbye) Descriptor() ([]byte, []int) {
-	return fileDescriptor_1723a75bcb31ddc3, []int{12}
+	return fileDescriptor_1723a75bcb31ddc3, []int{13}
 }
 
 func (m *Goodbye) XXX_Unmarshal(b []byte) error {
@@ -831,7 +934,7 @@ func (m *Goodbye) GetNodeID() string {
 }
 
 // Server ACKs the goodbye and signals if
-//the node is allowed to exit
+// the node is allowed to exit
 type GoodbyeAck struct {
 	Ok                   bool     `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -843,7 +946,7 @@ func (m *GoodbyeAck) Reset()         { *m = GoodbyeAck{} }
 func (m *GoodbyeAck) String() string { return proto.CompactTextString(m) }
 func (*GoodbyeAck) ProtoMessage()    {}
 func (*GoodbyeAck) Descriptor() ([]byte, []int) {
-	return fileDescriptor_1723a75bcb31ddc3, []int{13}
+	return fileDescriptor_1723a75bcb31ddc3, []int{14}
 }
 
 func (m *GoodbyeAck) XXX_Unmarshal(b []byte) error {
@@ -875,6 +978,8 @@ func init() {
 	proto.RegisterType((*NrayServerMessage)(nil), "nraySchema.NrayServerMessage")
 	proto.RegisterType((*NrayNodeMessage)(nil), "nraySchema.NrayNodeMessage")
 	proto.RegisterType((*ScanTargets)(nil), "nraySchema.ScanTargets")
+	proto.RegisterMapType((map[string]string)(nil), "nraySchema.ScanTargets.HostnamesEntry")
+	proto.RegisterType((*ServiceTarget)(nil), "nraySchema.ServiceTarget")
 	proto.RegisterType((*NodeRegister)(nil), "nraySchema.NodeRegister")
 	proto.RegisterType((*Unregistered)(nil), "nraySchema.Unregistered")
 	proto.RegisterType((*RegisteredNode)(nil), "nraySchema.RegisteredNode")
@@ -891,52 +996,61 @@ func init() {
 func init() { proto.RegisterFile("schemas/messages.proto", fileDescriptor_1723a75bcb31ddc3) }
 
 var fileDescriptor_1723a75bcb31ddc3 = []byte{
-	// 743 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0xdd, 0x6e, 0x13, 0x3d,
-	0x10, 0xdd, 0x24, 0x6d, 0x7e, 0x26, 0x3f, 0xfd, 0xea, 0xaf, 0x94, 0x25, 0x54, 0xa2, 0xac, 0x10,
-	0x6a, 0x05, 0x4a, 0x44, 0x11, 0x7f, 0x02, 0x55, 0xa2, 0x0d, 0x62, 0x8b, 0xd4, 0x08, 0xb9, 0x45,
-	0xbd, 0x00, 0x2e, 0x36, 0x1b, 0x67, 0x13, 0x92, 0xd8, 0xc1, 0xeb, 0x14, 0xf5, 0x1d, 0x78, 0x0f,
-	0xae, 0x78, 0x01, 0x9e, 0x0e, 0xd9, 0x6b, 0x6f, 0xbc, 0x6d, 0x22, 0x71, 0x39, 0x7b, 0xce, 0xf1,
-	0x38, 0xe7, 0x8c, 0x27, 0xb0, 0x1d, 0x87, 0x43, 0x32, 0x0d, 0xe2, 0xf6, 0x94, 0xc4, 0x71, 0x10,
-	0x91, 0xb8, 0x35, 0xe3, 0x4c, 0x30, 0x04, 0x94, 0x07, 0x57, 0x67, 0x0a, 0x6b, 0xde, 0x8b, 0x18,
-	0x8b, 0x26, 0xa4, 0xad, 0x90, 0xde, 0x7c, 0xd0, 0x16, 0xa3, 0x29, 0x89, 0x45, 0x30, 0x9d, 0x25,
-	0xe4, 0xe6, 0x96, 0x39, 0x84, 0x5c, 0x12, 0x2a, 0xf4, 0x11, 0xde, 0xaf, 0x02, 0x6c, 0x76, 0xe5,
-	0x29, 0x84, 0x5f, 0x12, 0x7e, 0x9a, 0x9c, 0x8f, 0x3a, 0xd0, 0xe0, 0x24, 0x1a, 0xc5, 0x82, 0x70,
-	0xd2, 0xef, 0xb2, 0x3e, 0x71, 0x73, 0xbb, 0xb9, 0xbd, 0xea, 0x41, 0xb3, 0xb5, 0xe8, 0xd8, 0xc2,
-	0x19, 0x86, 0xef, 0xe0, 0x6b, 0x1a, 0xf4, 0x02, 0xca, 0xdf, 0x58, 0xef, 0x28, 0x10, 0xe1, 0xd0,
-	0xcd, 0x2b, 0xfd, 0x1d, 0x5b, 0x7f, 0xca, 0x38, 0xb9, 0x60, 0x7c, 0x8c, 0xc9, 0x6c, 0x72, 0xe5,
-	0x3b, 0x38, 0x25, 0xa3, 0x43, 0xa8, 0x0d, 0x49, 0xc0, 0x45, 0x8f, 0x04, 0xe2, 0x6d, 0x38, 0x76,
-	0x0b, 0x4a, 0xec, 0xda, 0x62, 0xdf, 0xc2, 0x7d, 0x07, 0x67, 0xf8, 0xe8, 0x35, 0x54, 0x7f, 0x30,
-	0x3e, 0xee, 0x30, 0x4a, 0xa4, 0x7c, 0x4d, 0xc9, 0x6f, 0xdb, 0xf2, 0x8b, 0x05, 0xec, 0x3b, 0xd8,
-	0x66, 0xa3, 0x97, 0x00, 0x11, 0x63, 0xfd, 0xde, 0x95, 0xd2, 0xae, 0x2b, 0xed, 0xb6, 0xad, 0x7d,
-	0x9f, 0xa2, 0xbe, 0x83, 0x2d, 0x2e, 0xfa, 0x00, 0x88, 0xb2, 0x3e, 0x39, 0x89, 0x3f, 0xd1, 0x85,
-	0x13, 0x6e, 0xf1, 0xe6, 0xe5, 0x6d, 0xdc, 0x77, 0xf0, 0x12, 0xd5, 0xd1, 0x7f, 0xd0, 0xd0, 0x61,
-	0x1c, 0x33, 0x2a, 0x08, 0x15, 0xde, 0x9f, 0x3c, 0x6c, 0xc8, 0xa4, 0xa4, 0xb5, 0x26, 0xa7, 0x43,
-	0xa8, 0x49, 0xad, 0x49, 0x42, 0xa7, 0x94, 0xe9, 0xd5, 0xb5, 0x70, 0x69, 0x94, 0xcd, 0x47, 0xcf,
-	0xa0, 0x92, 0x1a, 0xa7, 0x23, 0xba, 0xb5, 0xd4, 0x65, 0xdf, 0xc1, 0x0b, 0x26, 0x7a, 0x05, 0xe5,
-	0xa9, 0x0e, 0x4f, 0x67, 0x73, 0x77, 0x79, 0xb0, 0xdf, 0xe7, 0x24, 0x96, 0xda, 0x94, 0x8e, 0x0e,
-	0xa0, 0x6c, 0xcc, 0xd6, 0xb9, 0x6c, 0x2d, 0xcb, 0x45, 0x6a, 0x0c, 0x0f, 0xb5, 0xa1, 0xa4, 0x5d,
-	0xd6, 0x71, 0xfc, 0xbf, 0x24, 0x0e, 0xdf, 0xc1, 0x86, 0xb5, 0xc4, 0xbc, 0xaf, 0x50, 0x3d, 0x0b,
-	0x03, 0x7a, 0x1e, 0xf0, 0x88, 0x88, 0x18, 0x6d, 0x43, 0x91, 0x0f, 0x59, 0x2c, 0x62, 0x37, 0xb7,
-	0x5b, 0xd8, 0xab, 0x60, 0x5d, 0xa1, 0x26, 0x94, 0x45, 0x38, 0x9b, 0x31, 0x2e, 0x62, 0x37, 0xbf,
-	0x5b, 0xd8, 0xab, 0xe3, 0xb4, 0x96, 0xd8, 0xbc, 0xaf, 0xb1, 0x42, 0x82, 0x99, 0xda, 0xfb, 0x9d,
-	0x83, 0x9a, 0x6d, 0x34, 0xda, 0x81, 0xca, 0x34, 0x08, 0x87, 0x23, 0x4a, 0x4e, 0x3a, 0x2a, 0x95,
-	0x0a, 0x5e, 0x7c, 0x40, 0x0f, 0xa0, 0x3e, 0xe3, 0x64, 0x40, 0x38, 0x27, 0xfd, 0x8f, 0x8c, 0x4d,
-	0x94, 0xf5, 0xeb, 0x38, 0xfb, 0x11, 0x3d, 0x86, 0xcd, 0xf4, 0x83, 0x3c, 0xbc, 0x1b, 0x4c, 0x89,
-	0xb2, 0xbb, 0x82, 0x6f, 0x02, 0xe8, 0x11, 0x94, 0x08, 0xbd, 0x1c, 0xd1, 0x01, 0xd3, 0xbe, 0x6e,
-	0xda, 0x26, 0xbd, 0x93, 0x6f, 0x1e, 0x1b, 0x86, 0xf7, 0x10, 0x6a, 0xf6, 0xb4, 0x49, 0x3f, 0xd4,
-	0x0c, 0x9a, 0xbb, 0xea, 0xca, 0xfb, 0x99, 0x83, 0x46, 0xf6, 0x99, 0x4b, 0x6a, 0x37, 0x43, 0x4d,
-	0x2a, 0xf4, 0x06, 0xaa, 0xc9, 0x0e, 0x39, 0x9e, 0xb0, 0x70, 0xac, 0x87, 0xa9, 0xd9, 0x4a, 0xb6,
-	0x52, 0xcb, 0x6c, 0xa5, 0xd6, 0xb9, 0xd9, 0x4a, 0xd8, 0xa6, 0x4b, 0x47, 0xe2, 0x30, 0xa0, 0x94,
-	0xf0, 0x90, 0xd1, 0xc1, 0x28, 0x52, 0xbf, 0xa1, 0x86, 0xb3, 0x1f, 0xbd, 0xcf, 0x50, 0x49, 0x27,
-	0x72, 0xe5, 0x45, 0x9e, 0x43, 0xf9, 0x88, 0x04, 0x42, 0x36, 0xfa, 0x87, 0x5b, 0xa4, 0x5c, 0xaf,
-	0x03, 0x35, 0x7b, 0xa9, 0xc8, 0xbc, 0xe5, 0xc8, 0xd0, 0x11, 0x8d, 0x54, 0x87, 0x32, 0x4e, 0x6b,
-	0xe4, 0x42, 0x09, 0xcf, 0x13, 0x28, 0xaf, 0x20, 0x53, 0x7a, 0xfb, 0xb0, 0x71, 0x6d, 0xfc, 0x57,
-	0x5d, 0xd4, 0xfb, 0x02, 0xf5, 0xcc, 0x0a, 0x94, 0xa7, 0xf6, 0xe4, 0xfe, 0x1b, 0xf5, 0x15, 0x73,
-	0x0d, 0x9b, 0x12, 0x3d, 0x81, 0x92, 0x48, 0x46, 0x57, 0xbf, 0xb7, 0xcc, 0x32, 0xb3, 0x26, 0x1b,
-	0x1b, 0x9e, 0x17, 0x41, 0xd9, 0x3c, 0xa6, 0x55, 0xf1, 0xda, 0x0d, 0xf3, 0xd9, 0x86, 0xfb, 0x50,
-	0x4c, 0xfe, 0x26, 0xd4, 0xa8, 0x2f, 0x1d, 0x26, 0x4d, 0xf0, 0xea, 0x50, 0xb5, 0xb6, 0xa9, 0x77,
-	0x1f, 0x4a, 0xfa, 0x45, 0xae, 0x9c, 0xaa, 0x1d, 0x80, 0xc5, 0x0e, 0x45, 0x0d, 0xc8, 0xb3, 0xb1,
-	0x76, 0x38, 0xcf, 0xc6, 0xbd, 0xa2, 0x4a, 0xe9, 0xe9, 0xdf, 0x00, 0x00, 0x00, 0xff, 0xff, 0xb5,
-	0x46, 0x96, 0x20, 0xf5, 0x06, 0x00, 0x00,
+	// 886 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0xed, 0x6e, 0xe3, 0x44,
+	0x14, 0x4d, 0xec, 0xb6, 0x49, 0x6e, 0x3e, 0x76, 0x3b, 0x94, 0x62, 0xc2, 0x4a, 0x14, 0x0b, 0xad,
+	0xba, 0x02, 0xa5, 0x62, 0xd1, 0xc2, 0x02, 0xab, 0x95, 0xe8, 0x76, 0x85, 0x17, 0x69, 0x23, 0x34,
+	0x5d, 0xd4, 0x1f, 0xf0, 0xc7, 0x71, 0x6e, 0x1d, 0x93, 0x64, 0x26, 0x8c, 0x27, 0x41, 0x79, 0x13,
+	0xde, 0x00, 0x09, 0x89, 0x17, 0xe0, 0xe9, 0xd0, 0x8c, 0x67, 0xec, 0x71, 0x9b, 0x48, 0xfc, 0xf3,
+	0x9d, 0x7b, 0xce, 0xdc, 0xf1, 0x39, 0x77, 0xee, 0xc0, 0x69, 0x9e, 0xcc, 0x70, 0x19, 0xe7, 0x17,
+	0x4b, 0xcc, 0xf3, 0x38, 0xc5, 0x7c, 0xb4, 0x12, 0x5c, 0x72, 0x02, 0x4c, 0xc4, 0xdb, 0x6b, 0x9d,
+	0x1b, 0x7e, 0x9c, 0x72, 0x9e, 0x2e, 0xf0, 0x42, 0x67, 0x26, 0xeb, 0xdb, 0x0b, 0x99, 0x2d, 0x31,
+	0x97, 0xf1, 0x72, 0x55, 0x80, 0x87, 0x27, 0x76, 0x13, 0xdc, 0x20, 0x93, 0x66, 0x8b, 0xf0, 0x2f,
+	0x1f, 0x8e, 0xc7, 0x6a, 0x17, 0x14, 0x1b, 0x14, 0x6f, 0x8b, 0xfd, 0xc9, 0x15, 0x0c, 0x04, 0xa6,
+	0x59, 0x2e, 0x51, 0xe0, 0x74, 0xcc, 0xa7, 0x18, 0x34, 0xcf, 0x9a, 0xe7, 0xdd, 0xa7, 0xc3, 0x51,
+	0x55, 0x71, 0x44, 0x6b, 0x88, 0xa8, 0x41, 0xef, 0x70, 0xc8, 0xd7, 0xd0, 0xfe, 0x8d, 0x4f, 0x2e,
+	0x63, 0x99, 0xcc, 0x02, 0x4f, 0xf3, 0x3f, 0x74, 0xf9, 0x6f, 0xb9, 0xc0, 0x1b, 0x2e, 0xe6, 0x14,
+	0x57, 0x8b, 0x6d, 0xd4, 0xa0, 0x25, 0x98, 0xbc, 0x84, 0xde, 0x0c, 0x63, 0x21, 0x27, 0x18, 0xcb,
+	0xef, 0x93, 0x79, 0xe0, 0x6b, 0x72, 0xe0, 0x92, 0x23, 0x27, 0x1f, 0x35, 0x68, 0x0d, 0x4f, 0xbe,
+	0x83, 0xee, 0x1f, 0x5c, 0xcc, 0xaf, 0x38, 0x43, 0x45, 0x3f, 0xd0, 0xf4, 0x0f, 0x5c, 0xfa, 0x4d,
+	0x95, 0x8e, 0x1a, 0xd4, 0x45, 0x93, 0xe7, 0x00, 0x29, 0xe7, 0xd3, 0xc9, 0x56, 0x73, 0x0f, 0x35,
+	0xf7, 0xd4, 0xe5, 0xfe, 0x50, 0x66, 0xa3, 0x06, 0x75, 0xb0, 0xe4, 0x47, 0x20, 0x8c, 0x4f, 0xf1,
+	0x4d, 0xfe, 0x33, 0xab, 0x94, 0x08, 0x8e, 0xee, 0x1f, 0xde, 0xcd, 0x47, 0x0d, 0xba, 0x83, 0x75,
+	0xf9, 0x10, 0x06, 0xc6, 0x8c, 0x57, 0x9c, 0x49, 0x64, 0x32, 0xfc, 0xd7, 0x83, 0x07, 0xca, 0x29,
+	0x25, 0xad, 0xf5, 0xe9, 0x25, 0xf4, 0x14, 0xd7, 0x3a, 0x11, 0x34, 0xef, 0xd7, 0x1a, 0x3b, 0x79,
+	0x25, 0x94, 0x8b, 0x27, 0xcf, 0xa0, 0x53, 0x0a, 0x67, 0x2c, 0x7a, 0x7f, 0xa7, 0xca, 0x51, 0x83,
+	0x56, 0x48, 0xf2, 0x0d, 0xb4, 0x97, 0xc6, 0x3c, 0xe3, 0xcd, 0x47, 0xbb, 0x8d, 0xfd, 0x7d, 0x8d,
+	0xb9, 0xe2, 0x96, 0x70, 0xf2, 0x14, 0xda, 0x56, 0x6c, 0xe3, 0xcb, 0xc9, 0x2e, 0x5f, 0x14, 0xc7,
+	0xe2, 0xc8, 0x05, 0xb4, 0x8c, 0xca, 0xc6, 0x8e, 0xf7, 0x76, 0xd8, 0x11, 0x35, 0xa8, 0x45, 0xed,
+	0x10, 0xef, 0x4f, 0x0f, 0xba, 0xd7, 0x49, 0xcc, 0xde, 0xc5, 0x22, 0x45, 0x99, 0x93, 0x53, 0x38,
+	0x12, 0x33, 0x9e, 0xcb, 0x3c, 0x68, 0x9e, 0xf9, 0xe7, 0x1d, 0x6a, 0x22, 0x32, 0x84, 0xb6, 0x4c,
+	0x56, 0x2b, 0x2e, 0x64, 0x1e, 0x78, 0x67, 0xfe, 0x79, 0x9f, 0x96, 0xb1, 0xca, 0xad, 0xa7, 0x26,
+	0xe7, 0x17, 0x39, 0x1b, 0x93, 0x67, 0xd0, 0xce, 0x51, 0x6c, 0xb2, 0x04, 0xf3, 0xe0, 0xe0, 0xcc,
+	0xbf, 0xdb, 0xea, 0xd7, 0x45, 0xae, 0xa8, 0x4e, 0x4b, 0x28, 0xb9, 0x82, 0x8e, 0xaa, 0xcb, 0xe2,
+	0x25, 0xe6, 0xc1, 0xa1, 0xe6, 0x3d, 0xae, 0xf1, 0xaa, 0x23, 0x8f, 0x22, 0x0b, 0x7c, 0xcd, 0xa4,
+	0xd8, 0xd2, 0x8a, 0x38, 0x7c, 0x01, 0x83, 0x7a, 0x92, 0x3c, 0x04, 0x7f, 0x8e, 0x5b, 0xdd, 0x0e,
+	0x1d, 0xaa, 0x3e, 0xc9, 0x09, 0x1c, 0x6e, 0xe2, 0xc5, 0x1a, 0xb5, 0xcb, 0x1d, 0x5a, 0x04, 0xdf,
+	0x7a, 0xcf, 0x9b, 0xe1, 0x1c, 0xfa, 0xb5, 0xe3, 0x29, 0xa8, 0x56, 0xc3, 0xd0, 0x8b, 0x40, 0xad,
+	0xea, 0x89, 0x61, 0x37, 0xd0, 0x01, 0x21, 0x70, 0xa0, 0x04, 0xd0, 0x5d, 0xd0, 0xa7, 0xfa, 0x5b,
+	0xe9, 0x64, 0xcf, 0xa6, 0x2d, 0xee, 0xd0, 0x32, 0x0e, 0xff, 0x69, 0x42, 0xcf, 0xed, 0x48, 0xf2,
+	0x08, 0x3a, 0xcb, 0x38, 0x99, 0x65, 0x0c, 0xdf, 0x5c, 0x99, 0x82, 0xd5, 0x02, 0xf9, 0x14, 0xfa,
+	0x2b, 0x81, 0xb7, 0x28, 0x04, 0x4e, 0x7f, 0xe2, 0x7c, 0xa1, 0x8b, 0x1f, 0xd2, 0xfa, 0x22, 0xf9,
+	0x1c, 0x8e, 0xcb, 0x05, 0xb5, 0xf9, 0x58, 0x55, 0xf6, 0xf5, 0x5e, 0xf7, 0x13, 0xe4, 0x33, 0x68,
+	0x21, 0xdb, 0x64, 0xec, 0x96, 0x9b, 0x06, 0x3c, 0x76, 0x15, 0x7f, 0xad, 0x86, 0x23, 0xb5, 0x88,
+	0xf0, 0x31, 0xf4, 0xdc, 0x6b, 0xa9, 0xfa, 0x46, 0x5f, 0x56, 0x7b, 0x56, 0x13, 0x85, 0x7f, 0x37,
+	0x61, 0x50, 0x9f, 0x87, 0x0a, 0x3a, 0xae, 0x41, 0x8b, 0x88, 0xbc, 0x80, 0x6e, 0x31, 0x6c, 0x5f,
+	0x2d, 0x78, 0x32, 0x37, 0xb7, 0x6e, 0x38, 0x2a, 0xc6, 0xf7, 0xc8, 0x8e, 0xef, 0xd1, 0x3b, 0x3b,
+	0xbe, 0xa9, 0x0b, 0x57, 0x8a, 0xe4, 0x49, 0xcc, 0x18, 0x8a, 0x84, 0xb3, 0xdb, 0x2c, 0xd5, 0xff,
+	0xd0, 0xa3, 0xf5, 0x45, 0xa5, 0xaa, 0x88, 0x25, 0x2e, 0xb2, 0x65, 0x26, 0xf5, 0x9d, 0x69, 0xd2,
+	0x6a, 0x21, 0xfc, 0x05, 0x3a, 0xe5, 0xc5, 0xde, 0x7b, 0xcc, 0xaf, 0xa0, 0x7d, 0x89, 0xb1, 0x54,
+	0xc7, 0xf8, 0x1f, 0x67, 0x2c, 0xb1, 0xe1, 0x04, 0x7a, 0xee, 0x6c, 0x56, 0xdd, 0xa0, 0xba, 0x98,
+	0x65, 0x2c, 0xd5, 0x15, 0xda, 0xb4, 0x8c, 0x49, 0x00, 0x2d, 0xba, 0x2e, 0x52, 0x9e, 0x4e, 0xd9,
+	0x50, 0xfd, 0x00, 0x2d, 0x7f, 0xc0, 0x2f, 0x7e, 0xa0, 0x5c, 0x08, 0x9f, 0xc0, 0x83, 0x3b, 0x33,
+	0x66, 0xdf, 0x6f, 0x84, 0xbf, 0x42, 0xbf, 0xf6, 0xce, 0xa8, 0x9a, 0x13, 0xf5, 0xc8, 0x64, 0x53,
+	0x8d, 0x3c, 0xa0, 0x36, 0x24, 0x5f, 0x40, 0x4b, 0x16, 0x77, 0x2d, 0xf0, 0xef, 0xbf, 0x18, 0xce,
+	0x55, 0xa4, 0x16, 0x17, 0xa6, 0xd0, 0xb6, 0x13, 0x6b, 0x5f, 0x6b, 0xb8, 0x05, 0xbd, 0x7a, 0xc1,
+	0x27, 0x70, 0x54, 0xbc, 0xc5, 0x7a, 0x9c, 0xec, 0x6c, 0x44, 0x03, 0x08, 0xfb, 0xd0, 0x75, 0x9e,
+	0xac, 0xf0, 0x13, 0x68, 0x99, 0xb1, 0xb7, 0xb7, 0x23, 0x1f, 0x01, 0x54, 0x0f, 0x15, 0x19, 0x80,
+	0xc7, 0xe7, 0x46, 0x7f, 0x8f, 0xcf, 0x27, 0x47, 0xda, 0xc3, 0x2f, 0xff, 0x1b, 0x00, 0x71, 0xda,
+	0x72, 0x5e, 0x5a, 0x08, 0x00, 0x00,
 }
@@ -21,9 +21,15 @@ func ApplyDefaultConfig(config *viper.Viper) *viper.Viper {
 	defaultConfig.SetDefault("statusPrintInterval", 15*time.Second)
 	defaultConfig.SetDefault("pools", 1)
 	defaultConfig.SetDefault("considerClientPoolPreference", true)
+	defaultConfig.SetDefault("localNodes", 0)
+	defaultConfig.SetDefault("ratelimit.total", "none")
+	defaultConfig.SetDefault("ratelimit.perPool", "none")
+	defaultConfig.SetDefault("schedule.windows", []string{})
+	defaultConfig.SetDefault("schedule.timezone", "Local")
 	defaultConfig.SetDefault("internal.nodeExpiryTime", 30)
 	defaultConfig.SetDefault("internal.nodeExpiryCheckInterval", 10)
 	defaultConfig.SetDefault("targetgenerator.bufferSize", 5)
+	defaultConfig.SetDefault("targetgenerator.topPortsFile", "")
 	if config != nil {
 		defaultConfig.MergeConfigMap(config.AllSettings())
 	}
@@ -38,6 +44,8 @@ func ApplyDefaultTargetgeneratorStandardConfig(config *viper.Viper) *viper.Viper
 	defaultConfig.SetDefault("targetFile", "")
 	defaultConfig.SetDefault("tcpports", []string{"top25"})
 	defaultConfig.SetDefault("udpports", []string{"top25"})
+	defaultConfig.SetDefault("ports", []string{})
+	defaultConfig.SetDefault("excludePorts", []string{})
 	defaultConfig.SetDefault("blacklist", []string{""})
 	defaultConfig.SetDefault("blacklistFile", "")
 	defaultConfig.SetDefault("maxHostsPerBatch", 150)
@@ -49,6 +57,43 @@ func ApplyDefaultTargetgeneratorStandardConfig(config *viper.Viper) *viper.Viper
 	return defaultConfig
 }
 
+// ApplyDefaultTargetgeneratorImportConfig sets default values for the import target generator
+func ApplyDefaultTargetgeneratorImportConfig(config *viper.Viper) *viper.Viper {
+	defaultConfig := viper.New()
+	defaultConfig.SetDefault("enabled", false)
+	defaultConfig.SetDefault("files", []string{})
+	defaultConfig.SetDefault("format", "auto")
+	defaultConfig.SetDefault("useHostPorts", true)
+	defaultConfig.SetDefault("tcpports", []string{"top25"})
+	defaultConfig.SetDefault("udpports", []string{"top25"})
+	defaultConfig.SetDefault("ports", []string{})
+	defaultConfig.SetDefault("excludePorts", []string{})
+	defaultConfig.SetDefault("blacklist", []string{""})
+	defaultConfig.SetDefault("blacklistFile", "")
+	defaultConfig.SetDefault("maxHostsPerBatch", 150)
+	defaultConfig.SetDefault("maxTcpPortsPerBatch", 25)
+	defaultConfig.SetDefault("maxUdpPortsPerBatch", 25)
+	defaultConfig.SetDefault("maxServicesPerBatch", 500)
+	if config != nil {
+		defaultConfig.MergeConfigMap(config.AllSettings())
+	}
+	return defaultConfig
+}
+
+// ApplyDefaultTargetgeneratorResolveConfig sets default values for DNS resolution
+// done by the target generator
+func ApplyDefaultTargetgeneratorResolveConfig(config *viper.Viper) *viper.Viper {
+	defaultConfig := viper.New()
+	defaultConfig.SetDefault("enabled", false)
+	defaultConfig.SetDefault("nameservers", []string{})
+	defaultConfig.SetDefault("timeout", "2s")
+	defaultConfig.SetDefault("maxCacheTime", "5m")
+	if config != nil {
+		defaultConfig.MergeConfigMap(config.AllSettings())
+	}
+	return defaultConfig
+}
+
 // ApplyDefaultScannerConfig is called when the node applies the configuration sent
 // by the server in order to have defaults in place
 func ApplyDefaultScannerConfig(config *viper.Viper) *viper.Viper {
@@ -65,6 +110,8 @@ func ApplyDefaultScannerConfig(config *viper.Viper) *viper.Viper {
 func ApplyDefaultScannerTCPConfig(config *viper.Viper) *viper.Viper {
 	defaultConfig := viper.New()
 	defaultConfig.SetDefault("timeout", "2500ms")
+	defaultConfig.SetDefault("method", "connect")
+	defaultConfig.SetDefault("synSourcePort", 0)
 	if config != nil {
 		defaultConfig.MergeConfigMap(config.AllSettings())
 	}
@@ -77,7 +124,14 @@ func ApplyDefaultScannerUDPConfig(config *viper.Viper) *viper.Viper {
 	defaultConfig.SetDefault("fast", false)
 	defaultConfig.SetDefault("defaultHexPayload", "\x6e\x72\x61\x79") // "nray"
 	defaultConfig.SetDefault("customHexPayloads", map[string]string{})
+	defaultConfig.SetDefault("probeFile", "")
+	defaultConfig.SetDefault("probes", []interface{}{})
 	defaultConfig.SetDefault("timeout", "2500ms")
+	defaultConfig.SetDefault("retries", 0)
+	defaultConfig.SetDefault("backoff", 2.0)
+	defaultConfig.SetDefault("maxResponseSize", 512)
+	defaultConfig.SetDefault("decodeResponses", true)
+	defaultConfig.SetDefault("snmpCommunities", []string{"public"})
 	if config != nil {
 		defaultConfig.MergeConfigMap(config.AllSettings())
 
@@ -85,6 +139,135 @@ func ApplyDefaultScannerUDPConfig(config *viper.Viper) *viper.Viper {
 	return defaultConfig
 }
 
+// ApplyDefaultScannerAdaptiveTimeoutConfig is called when adaptive timeouts are initialized
+func ApplyDefaultScannerAdaptiveTimeoutConfig(config *viper.Viper) *viper.Viper {
+	defaultConfig := viper.New()
+	defaultConfig.SetDefault("enabled", false)
+	defaultConfig.SetDefault("min", "100ms")
+	defaultConfig.SetDefault("max", "5s")
+	defaultConfig.SetDefault("networkPrefix", 24)
+	defaultConfig.SetDefault("networkPrefixIPv6", 64)
+	if config != nil {
+		defaultConfig.MergeConfigMap(config.AllSettings())
+	}
+	return defaultConfig
+}
+
+// ApplyDefaultScannerSourceConfig is called when the source binding is initialized
+func ApplyDefaultScannerSourceConfig(config *viper.Viper) *viper.Viper {
+	defaultConfig := viper.New()
+	defaultConfig.SetDefault("ip", "")
+	defaultConfig.SetDefault("interface", "")
+	defaultConfig.SetDefault("ports", "")
+	if config != nil {
+		defaultConfig.MergeConfigMap(config.AllSettings())
+	}
+	return defaultConfig
+}
+
+// ApplyDefaultScannerResolverConfig is called when the node's resolver is initialized
+func ApplyDefaultScannerResolverConfig(config *viper.Viper) *viper.Viper {
+	defaultConfig := viper.New()
+	defaultConfig.SetDefault("nameservers", []string{})
+	defaultConfig.SetDefault("tcp", false)
+	defaultConfig.SetDefault("timeout", "2s")
+	defaultConfig.SetDefault("maxCacheTime", "5m")
+	defaultConfig.SetDefault("addressFamily", "both")
+	if config != nil {
+		defaultConfig.MergeConfigMap(config.AllSettings())
+	}
+	return defaultConfig
+}
+
+// ApplyDefaultScannerProxyConfig is called when the proxies are initialized
+func ApplyDefaultScannerProxyConfig(config *viper.Viper) *viper.Viper {
+	defaultConfig := viper.New()
+	defaultConfig.SetDefault("urls", []string{})
+	if config != nil {
+		defaultConfig.MergeConfigMap(config.AllSettings())
+	}
+	return defaultConfig
+}
+
+// ApplyDefaultScannerDiscoveryConfig is called when host discovery is initialized
+func ApplyDefaultScannerDiscoveryConfig(config *viper.Viper) *viper.Viper {
+	defaultConfig := viper.New()
+	defaultConfig.SetDefault("enabled", false)
+	defaultConfig.SetDefault("icmp", true)
+	defaultConfig.SetDefault("tcpPorts", []int{80, 443, 22, 445, 3389})
+	defaultConfig.SetDefault("tcpAckPorts", []int{})
+	defaultConfig.SetDefault("udpPorts", []int{40125})
+	defaultConfig.SetDefault("timeout", "1000ms")
+	if config != nil {
+		defaultConfig.MergeConfigMap(config.AllSettings())
+	}
+	return defaultConfig
+}
+
+// ApplyDefaultScannerServiceDetectionConfig is called when service detection is initialized
+func ApplyDefaultScannerServiceDetectionConfig(config *viper.Viper) *viper.Viper {
+	defaultConfig := viper.New()
+	defaultConfig.SetDefault("enabled", false)
+	defaultConfig.SetDefault("probeFile", "")
+	defaultConfig.SetDefault("probes", "")
+	defaultConfig.SetDefault("intensity", 7)
+	defaultConfig.SetDefault("timeout", "5s")
+	defaultConfig.SetDefault("maxResponseSize", 16384)
+	defaultConfig.SetDefault("tcp", true)
+	defaultConfig.SetDefault("udp", true)
+	if config != nil {
+		defaultConfig.MergeConfigMap(config.AllSettings())
+	}
+	return defaultConfig
+}
+
+// ApplyDefaultScannerSSHConfig is called when the SSH scanner is initialized
+func ApplyDefaultScannerSSHConfig(config *viper.Viper) *viper.Viper {
+	defaultConfig := viper.New()
+	defaultConfig.SetDefault("enabled", false)
+	defaultConfig.SetDefault("ports", []int{22})
+	defaultConfig.SetDefault("timeout", "5s")
+	defaultConfig.SetDefault("clientBanner", "SSH-2.0-nray")
+	defaultConfig.SetDefault("hostKeys", true)
+	if config != nil {
+		defaultConfig.MergeConfigMap(config.AllSettings())
+	}
+	return defaultConfig
+}
+
+// ApplyDefaultScannerPolitenessConfig is called when the per host and per network limits are initialized
+func ApplyDefaultScannerPolitenessConfig(config *viper.Viper) *viper.Viper {
+	defaultConfig := viper.New()
+	defaultConfig.SetDefault("hostRatelimit", "none")
+	defaultConfig.SetDefault("maxConnectionsPerHost", 0)
+	defaultConfig.SetDefault("networkRatelimit", "none")
+	defaultConfig.SetDefault("maxConnectionsPerNetwork", 0)
+	defaultConfig.SetDefault("networkPrefix", 24)
+	defaultConfig.SetDefault("networkPrefixIPv6", 64)
+	if config != nil {
+		defaultConfig.MergeConfigMap(config.AllSettings())
+	}
+	return defaultConfig
+}
+
+// ApplyDefaultEnrichmentConfig is called when the server's result enrichment is initialized
+func ApplyDefaultEnrichmentConfig(config *viper.Viper) *viper.Viper {
+	defaultConfig := viper.New()
+	defaultConfig.SetDefault("enabled", false)
+	defaultConfig.SetDefault("ptr.enabled", true)
+	defaultConfig.SetDefault("ptr.nameservers", []string{})
+	defaultConfig.SetDefault("ptr.timeout", "2s")
+	defaultConfig.SetDefault("ptr.maxCacheTime", "1h")
+	defaultConfig.SetDefault("ptr.ratelimit", "none")
+	defaultConfig.SetDefault("mmdbFiles", []string{})
+	defaultConfig.SetDefault("internal.queueSize", 100)
+	defaultConfig.SetDefault("internal.workers", 10)
+	if config != nil {
+		defaultConfig.MergeConfigMap(config.AllSettings())
+	}
+	return defaultConfig
+}
+
 // ApplyDefaultEventTerminalConfig is called when the TerminalEventHandler is initialized
 func ApplyDefaultEventTerminalConfig(config *viper.Viper) *viper.Viper {
 	defaultConfig := viper.New()
@@ -14,10 +14,13 @@ const RegexNetIPv4 = "^(?:(?:25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\\.){3}(?:25[0
 // RegexPortRange matches strings of the form "{number}-{number}" where number are 1 to 5 digits
 const RegexPortRange = "^[0-9]{1,5}-[0-9]{1,5}$"
 
-// RegexTopPorts matches strings like "top25" or "Top2500"
-const RegexTopPorts = "^[tT]op[-]?[0-9]{1,4}$"
+// RegexTopPorts matches strings like "top25", "Top2500" or "top90%".
+// The first submatch is the number, the second is "%" for percentages
+const RegexTopPorts = "^[tT]op[-]?([0-9]{1,5}|[0-9]{1,3}(?:\\.[0-9]+)?)(%?)$"
 
 // RegexThousandNumber matches all numbers between 1000 and 9999 plus 0000
+//
+// Deprecated: RegexTopPorts captures the number of top lists itself
 const RegexThousandNumber = "[0-9]{1,4}"
 
 // Ipv4Regexpr is the above IPv4 regex, already conveniently compiled
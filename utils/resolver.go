@@ -0,0 +1,377 @@
+package utils
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/time/rate"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Resolver resolves DNS names to IPv4 and IPv6 addresses. If nameservers are
+// configured, they are queried directly and the TTL of the answers is
+// respected (bounded by maxCacheTime), otherwise the system resolver is
+// used and answers are cached for maxCacheTime. It is safe for concurrent use
+type Resolver struct {
+	nameservers  []string
+	timeout      time.Duration
+	maxCacheTime time.Duration
+	useTCP       bool
+	// Limits queries that are not answered from the cache, nil if unlimited
+	limiter   *rate.Limiter
+	cache     map[string]resolverCacheEntry
+	cacheLock sync.Mutex
+	// Lookups that are running, concurrent misses for the same key wait for them
+	inflight map[string]*resolverCall
+}
+
+// transientErrorCacheTime is how long errors that may go away, like timeouts,
+// and partial answers are cached
+const transientErrorCacheTime = 5 * time.Second
+
+type resolverCacheEntry struct {
+	addresses []string
+	err       error
+	expires   time.Time
+}
+
+type resolverCall struct {
+	done      chan struct{}
+	addresses []string
+	err       error
+}
+
+// NewResolver returns a resolver using the given nameservers. Nameservers
+// without a port are queried on port 53. An empty list selects the system resolver
+func NewResolver(nameservers []string, timeout time.Duration, maxCacheTime time.Duration) *Resolver {
+	servers := make([]string, 0, len(nameservers))
+	for _, nameserver := range nameservers {
+		nameserver = strings.TrimSpace(nameserver)
+		if nameserver == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(nameserver); err != nil {
+			nameserver = net.JoinHostPort(nameserver, "53")
+		}
+		servers = append(servers, nameserver)
+	}
+	return &Resolver{
+		nameservers:  servers,
+		timeout:      timeout,
+		maxCacheTime: maxCacheTime,
+		cache:        make(map[string]resolverCacheEntry),
+		inflight:     make(map[string]*resolverCall),
+	}
+}
+
+// SetUseTCP makes the resolver query the nameservers over TCP instead of UDP.
+// Truncated answers over UDP are always repeated over TCP
+func (r *Resolver) SetUseTCP(useTCP bool) {
+	r.useTCP = useTCP
+}
+
+// SetRatelimit limits how many queries per second are sent. Answers from the cache are not limited
+func (r *Resolver) SetRatelimit(limiter *rate.Limiter) {
+	r.limiter = limiter
+}
+
+// LookupIPv4 returns the IPv4 addresses of a DNS name. Negative answers
+// are cached as well, so a name that does not resolve is not queried
+// over and over again
+func (r *Resolver) LookupIPv4(name string) ([]string, error) {
+	return r.Lookup(name, "ip4")
+}
+
+// LookupIP returns the IPv4 and IPv6 addresses of a DNS name, IPv4 addresses first
+func (r *Resolver) LookupIP(name string) ([]string, error) {
+	return r.Lookup(name, "ip")
+}
+
+// Lookup returns the addresses of a DNS name. network is "ip4" for A records,
+// "ip6" for AAAA records or "ip" for both
+func (r *Resolver) Lookup(name string, network string) ([]string, error) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	addresses, err := r.cached(network+"/"+name, func() ([]string, time.Duration, error) {
+		if len(r.nameservers) == 0 {
+			return r.lookupSystem(name, network)
+		}
+		return r.lookupNameservers(name, network)
+	})
+	if err == nil && len(addresses) == 0 {
+		err = fmt.Errorf("%s has no %s address", name, map[string]string{"ip4": "IPv4", "ip6": "IPv6", "ip": "IP"}[network])
+	}
+	return addresses, err
+}
+
+// LookupAddr returns the names of an IP address from its PTR records
+func (r *Resolver) LookupAddr(address string) ([]string, error) {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil, fmt.Errorf("%s is no IP address", address)
+	}
+	return r.cached("ptr/"+ip.String(), func() ([]string, time.Duration, error) {
+		if len(r.nameservers) == 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+			defer cancel()
+			names, err := net.DefaultResolver.LookupAddr(ctx, ip.String())
+			for i := range names {
+				names[i] = strings.TrimSuffix(names[i], ".")
+			}
+			return names, r.systemCacheTime(err), err
+		}
+		return r.lookupType(reverseName(ip), dnsmessage.TypePTR)
+	})
+}
+
+// reverseName returns the name PTR records of ip are stored under
+func reverseName(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa", ip4[3], ip4[2], ip4[1], ip4[0])
+	}
+	nibbles := make([]string, 0, 32)
+	for i := len(ip) - 1; i >= 0; i-- {
+		nibbles = append(nibbles, fmt.Sprintf("%x", ip[i]&0x0f), fmt.Sprintf("%x", ip[i]>>4))
+	}
+	return strings.Join(nibbles, ".") + ".ip6.arpa"
+}
+
+// cached returns the cached answer for key or looks it up. Answers are cached for
+// the returned TTL, bounded by maxCacheTime. Concurrent misses for the same key
+// share a single lookup. Only lookups are rate limited
+func (r *Resolver) cached(key string, lookup func() ([]string, time.Duration, error)) ([]string, error) {
+	r.cacheLock.Lock()
+	entry, cached := r.cache[key]
+	if cached && time.Now().Before(entry.expires) {
+		r.cacheLock.Unlock()
+		return entry.addresses, entry.err
+	}
+	if call, running := r.inflight[key]; running {
+		r.cacheLock.Unlock()
+		<-call.done
+		return call.addresses, call.err
+	}
+	call := &resolverCall{done: make(chan struct{})}
+	r.inflight[key] = call
+	r.cacheLock.Unlock()
+
+	if r.limiter != nil {
+		r.limiter.Wait(context.Background())
+	}
+	addresses, ttl, err := lookup()
+	if ttl > r.maxCacheTime {
+		ttl = r.maxCacheTime
+	}
+	call.addresses, call.err = addresses, err
+	r.cacheLock.Lock()
+	r.cache[key] = resolverCacheEntry{addresses: addresses, err: err, expires: time.Now().Add(ttl)}
+	delete(r.inflight, key)
+	r.cacheLock.Unlock()
+	close(call.done)
+	return addresses, err
+}
+
+func (r *Resolver) lookupSystem(name string, network string) ([]string, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	ips, err := net.DefaultResolver.LookupIP(ctx, network, name)
+	if err != nil {
+		return nil, r.systemCacheTime(err), err
+	}
+	addresses := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			addresses = append(addresses, ip.String())
+		}
+	}
+	for _, ip := range ips {
+		if ip.To4() == nil {
+			addresses = append(addresses, ip.String())
+		}
+	}
+	return addresses, r.maxCacheTime, nil
+}
+
+// systemCacheTime returns how long an answer of the system resolver is cached.
+// Names that do not exist are cached like answers, other errors only briefly
+func (r *Resolver) systemCacheTime(err error) time.Duration {
+	var dnsErr *net.DNSError
+	if err == nil || (errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+		return r.maxCacheTime
+	}
+	return transientErrorCacheTime
+}
+
+// lookupNameservers asks the configured nameservers one after another until
+// one of them returns an answer. The lowest TTL of all records is returned.
+// A name that does not exist is an answer as well and is cached
+func (r *Resolver) lookupNameservers(name string, network string) ([]string, time.Duration, error) {
+	types := map[string][]dnsmessage.Type{
+		"ip4": {dnsmessage.TypeA},
+		"ip6": {dnsmessage.TypeAAAA},
+		"ip":  {dnsmessage.TypeA, dnsmessage.TypeAAAA},
+	}[network]
+	addresses := make([]string, 0)
+	ttl := r.maxCacheTime
+	var firstErr error
+	var errTTL time.Duration
+	for _, queryType := range types {
+		typeAddresses, typeTTL, err := r.lookupType(name, queryType)
+		if err != nil {
+			if firstErr == nil {
+				firstErr, errTTL = err, typeTTL
+			}
+			continue
+		}
+		addresses = append(addresses, typeAddresses...)
+		ttl = min(ttl, typeTTL)
+	}
+	if firstErr != nil && len(addresses) == 0 {
+		return nil, errTTL, firstErr
+	}
+	// Addresses of one type are kept if the query for the other failed,
+	// but the failed query is repeated soon
+	if firstErr != nil {
+		ttl = min(ttl, transientErrorCacheTime)
+	}
+	return addresses, ttl, nil
+}
+
+// lookupType asks the nameservers for records of a single type
+func (r *Resolver) lookupType(name string, queryType dnsmessage.Type) ([]string, time.Duration, error) {
+	var lastErr error
+	for _, nameserver := range r.nameservers {
+		addresses, ttl, err := r.query(nameserver, name, queryType)
+		if err == nil || ttl > 0 {
+			return addresses, ttl, err
+		}
+		lastErr = err
+	}
+	return nil, 0, lastErr
+}
+
+func (r *Resolver) query(nameserver string, name string, queryType dnsmessage.Type) ([]string, time.Duration, error) {
+	dnsName, err := dnsmessage.NewName(name + ".")
+	if err != nil {
+		return nil, 0, err
+	}
+	id := uint16(rand.Uint32())
+	query := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  dnsName,
+			Type:  queryType,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, 0, err
+	}
+	var response *dnsmessage.Message
+	if !r.useTCP {
+		response, err = r.exchangeUDP(nameserver, packed, id)
+	}
+	if r.useTCP || (err == nil && response.Header.Truncated) {
+		response, err = r.exchangeTCP(nameserver, packed, id)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if response.Header.RCode == dnsmessage.RCodeNameError {
+		return nil, r.maxCacheTime, fmt.Errorf("%s does not exist", name)
+	}
+	if response.Header.RCode != dnsmessage.RCodeSuccess {
+		return nil, 0, fmt.Errorf("Nameserver %s answered with %s", nameserver, response.Header.RCode)
+	}
+	addresses := make([]string, 0)
+	ttl := r.maxCacheTime
+	for _, answer := range response.Answers {
+		if time.Duration(answer.Header.TTL)*time.Second < ttl {
+			ttl = time.Duration(answer.Header.TTL) * time.Second
+		}
+		// CNAMEs are resolved by the recursive resolver, the A and AAAA records are part of the answer
+		switch record := answer.Body.(type) {
+		case *dnsmessage.AResource:
+			if queryType == dnsmessage.TypeA {
+				addresses = append(addresses, net.IP(record.A[:]).String())
+			}
+		case *dnsmessage.AAAAResource:
+			if queryType == dnsmessage.TypeAAAA {
+				addresses = append(addresses, net.IP(record.AAAA[:]).String())
+			}
+		case *dnsmessage.PTRResource:
+			if queryType == dnsmessage.TypePTR {
+				addresses = append(addresses, strings.TrimSuffix(record.PTR.String(), "."))
+			}
+		}
+	}
+	return addresses, ttl, nil
+}
+
+// exchangeUDP sends a query in a single datagram and waits for the matching response
+func (r *Resolver) exchangeUDP(nameserver string, packed []byte, id uint16) (*dnsmessage.Message, error) {
+	conn, err := net.DialTimeout("udp", nameserver, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(r.timeout))
+	if _, err = conn.Write(packed); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 1500)
+	response := &dnsmessage.Message{}
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		if err = response.Unpack(buf[:n]); err != nil {
+			return nil, err
+		}
+		// Ignore answers that do not belong to our query
+		if response.Header.ID == id && response.Header.Response {
+			return response, nil
+		}
+	}
+}
+
+// exchangeTCP sends a query over TCP. Messages are prefixed with their length
+func (r *Resolver) exchangeTCP(nameserver string, packed []byte, id uint16) (*dnsmessage.Message, error) {
+	conn, err := net.DialTimeout("tcp", nameserver, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(r.timeout))
+	if _, err = conn.Write(binary.BigEndian.AppendUint16(nil, uint16(len(packed)))); err != nil {
+		return nil, err
+	}
+	if _, err = conn.Write(packed); err != nil {
+		return nil, err
+	}
+	length := make([]byte, 2)
+	if _, err = io.ReadFull(conn, length); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, binary.BigEndian.Uint16(length))
+	if _, err = io.ReadFull(conn, buf); err != nil {
+		return nil, err
+	}
+	response := &dnsmessage.Message{}
+	if err = response.Unpack(buf); err != nil {
+		return nil, err
+	}
+	if response.Header.ID != id || !response.Header.Response {
+		return nil, fmt.Errorf("Nameserver %s answered to another query", nameserver)
+	}
+	return response, nil
+}